@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -133,18 +134,19 @@ func (c *asyncClient) Keys(arg0 string) (result FutureKeys, err Error) {
 	return result, err
 }
 
-/***
 // Redis SORT command.
-func (c *asyncClient) Sort (arg0 string) (result redis.Sort, err Error){
-	arg0bytes := []byte (arg0);
+func (c *asyncClient) Sort(arg0 string, spec *SortSpec) (result FutureSortResult, err Error) {
+	cmd := spec.command()
 
-	var resp *PendingResponse;
-	resp, err = c.conn.QueueRequest(&SORT, [][]byte{arg0bytes});
-	if err == nil {result = resp.GetMultiBulkData();}
-	return result, err;
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(cmd, spec.args(arg0))
+	if err == nil {
+		result = newFutureSortResult(cmd, resp.future)
+	}
+	return result, err
 
 }
-***/
+
 // Redis EXISTS command.
 func (c *asyncClient) Exists(arg0 string) (result FutureBool, err Error) {
 	arg0bytes := []byte(arg0)
@@ -795,7 +797,7 @@ func (c *asyncClient) Zscore(arg0 string, arg1 []byte) (result FutureFloat64, er
 	var resp *PendingResponse
 	resp, err = c.conn.QueueRequest(&ZSCORE, [][]byte{arg0bytes, arg1bytes})
 	if err == nil {
-		result = newFutureFloat64(resp.future.(FutureBytes))
+		result = _futurezscore{resp.future.(FutureFloat64)}
 	}
 	return result, err
 
@@ -846,6 +848,183 @@ func (c *asyncClient) Zrangebyscore(arg0 string, arg1 float64, arg2 float64) (re
 
 }
 
+// Redis ZADD command, of multiple members.
+func (c *asyncClient) ZaddMembers(arg0 string, arg1 []ZMember, flags ...ZAddFlag) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZADD_MEMBERS, zaddArgs(arg0, flags, false, arg1...))
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis ZADD INCR command.
+func (c *asyncClient) ZaddIncr(arg0 string, arg1 []byte, arg2 float64, flags ...ZAddFlag) (result FutureFloat64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZADD_INCR, zaddArgs(arg0, flags, true, ZMember{arg1, arg2}))
+	if err == nil {
+		result = resp.future.(FutureFloat64)
+	}
+	return result, err
+
+}
+
+// Redis ZINCRBY command.
+func (c *asyncClient) Zincrby(arg0 string, arg1 float64, arg2 []byte) (result FutureFloat64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZINCRBY, [][]byte{[]byte(arg0), []byte(formatScore(arg1)), arg2})
+	if err == nil {
+		result = resp.future.(FutureFloat64)
+	}
+	return result, err
+
+}
+
+// Redis ZRANK command.
+func (c *asyncClient) Zrank(arg0 string, arg1 []byte) (result FutureRank, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZRANK, [][]byte{[]byte(arg0), arg1})
+	if err == nil {
+		result = newFutureRank(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANK command.
+func (c *asyncClient) Zrevrank(arg0 string, arg1 []byte) (result FutureRank, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZREVRANK, [][]byte{[]byte(arg0), arg1})
+	if err == nil {
+		result = newFutureRank(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis ZCOUNT command.
+func (c *asyncClient) Zcount(arg0 string, min ZBound, max ZBound) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZCOUNT, [][]byte{[]byte(arg0), []byte(min), []byte(max)})
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis ZREMRANGEBYSCORE command.
+func (c *asyncClient) Zremrangebyscore(arg0 string, min ZBound, max ZBound) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZREMRANGEBYSCORE, [][]byte{[]byte(arg0), []byte(min), []byte(max)})
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis ZREMRANGEBYRANK command.
+func (c *asyncClient) Zremrangebyrank(arg0 string, arg1 int64, arg2 int64) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZREMRANGEBYRANK, [][]byte{[]byte(arg0), []byte(fmt.Sprintf("%d", arg1)), []byte(fmt.Sprintf("%d", arg2))})
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis ZRANGE WITHSCORES command.
+func (c *asyncClient) ZrangeWithScores(arg0 string, arg1 int64, arg2 int64) (result FutureZMembers, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZRANGE_WITHSCORES, [][]byte{[]byte(arg0), []byte(fmt.Sprintf("%d", arg1)), []byte(fmt.Sprintf("%d", arg2)), []byte("WITHSCORES")})
+	if err == nil {
+		result = newFutureZMembers(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANGE WITHSCORES command.
+func (c *asyncClient) ZrevrangeWithScores(arg0 string, arg1 int64, arg2 int64) (result FutureZMembers, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZREVRANGE_WITHSCORES, [][]byte{[]byte(arg0), []byte(fmt.Sprintf("%d", arg1)), []byte(fmt.Sprintf("%d", arg2)), []byte("WITHSCORES")})
+	if err == nil {
+		result = newFutureZMembers(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis ZRANGEBYSCORE command, of the (exclusive or infinite) bounds and
+// LIMIT of the spec.
+func (c *asyncClient) ZrangebyscoreWithSpec(arg0 string, spec *ZRangeSpec) (result FutureBytesArray, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZRANGEBYSCORE, spec.args(arg0, false, false))
+	if err == nil {
+		result = resp.future.(FutureBytesArray)
+	}
+	return result, err
+
+}
+
+// Redis ZRANGEBYSCORE WITHSCORES command.  See ZrangebyscoreWithSpec.
+func (c *asyncClient) ZrangebyscoreWithScores(arg0 string, spec *ZRangeSpec) (result FutureZMembers, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZRANGEBYSCORE_WITHSCORES, spec.args(arg0, false, true))
+	if err == nil {
+		result = newFutureZMembers(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANGEBYSCORE command.
+func (c *asyncClient) Zrevrangebyscore(arg0 string, spec *ZRangeSpec) (result FutureBytesArray, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZREVRANGEBYSCORE, spec.args(arg0, true, false))
+	if err == nil {
+		result = resp.future.(FutureBytesArray)
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANGEBYSCORE WITHSCORES command.  See Zrevrangebyscore.
+func (c *asyncClient) ZrevrangebyscoreWithScores(arg0 string, spec *ZRangeSpec) (result FutureZMembers, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZREVRANGEBYSCORE_WITHSCORES, spec.args(arg0, true, true))
+	if err == nil {
+		result = newFutureZMembers(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis ZUNIONSTORE command.
+func (c *asyncClient) Zunionstore(arg0 string, arg1 []string, weights []float64, aggregate ZAggregate) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZUNIONSTORE, zstoreArgs(arg0, arg1, weights, aggregate))
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis ZINTERSTORE command.  See Zunionstore.
+func (c *asyncClient) Zinterstore(arg0 string, arg1 []string, weights []float64, aggregate ZAggregate) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&ZINTERSTORE, zstoreArgs(arg0, arg1, weights, aggregate))
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
 // Redis HGET command.
 func (c *asyncClient) Hget(arg0 string, arg1 string) (result FutureBytes, err Error) {
 	arg0bytes := []byte(arg0)
@@ -875,11 +1054,195 @@ func (c *asyncClient) Hset(arg0 string, arg1 string, arg2 []byte) (stat FutureBo
 }
 
 // Redis HGETALL command.
-func (c *asyncClient) Hgetall(arg0 string) (result FutureBytes, err Error) {
+func (c *asyncClient) Hgetall(arg0 string) (result FutureBytesMap, err Error) {
 	arg0bytes := []byte(arg0)
 
 	var resp *PendingResponse
 	resp, err = c.conn.QueueRequest(&HGETALL, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.future.(FutureBytesMap)
+	}
+	return result, err
+
+}
+
+// Redis HMSET command.
+func (c *asyncClient) Hmset(arg0 string, arg1 map[string][]byte) (stat FutureBool, err Error) {
+	resp, err := c.conn.QueueRequest(&HMSET, hashArgs(arg0, arg1))
+	if err == nil {
+		stat = resp.future.(FutureBool)
+	}
+	return
+}
+
+// Redis HMGET command.
+func (c *asyncClient) Hmget(arg0 string, arg1 string, arg2 ...string) (result FutureBytesArray, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HMGET, appendAndConvert(arg0, append([]string{arg1}, arg2...)...))
+	if err == nil {
+		result = resp.future.(FutureBytesArray)
+	}
+	return result, err
+
+}
+
+// Redis HDEL command.
+func (c *asyncClient) Hdel(arg0 string, arg1 string, arg2 ...string) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HDEL, appendAndConvert(arg0, append([]string{arg1}, arg2...)...))
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis HEXISTS command.
+func (c *asyncClient) Hexists(arg0 string, arg1 string) (result FutureBool, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HEXISTS, [][]byte{arg0bytes, arg1bytes})
+	if err == nil {
+		result = resp.future.(FutureBool)
+	}
+	return result, err
+
+}
+
+// Redis HLEN command.
+func (c *asyncClient) Hlen(arg0 string) (result FutureInt64, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HLEN, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis HKEYS command.
+func (c *asyncClient) Hkeys(arg0 string) (result FutureBytesArray, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HKEYS, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.future.(FutureBytesArray)
+	}
+	return result, err
+
+}
+
+// Redis HVALS command.
+func (c *asyncClient) Hvals(arg0 string) (result FutureBytesArray, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HVALS, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.future.(FutureBytesArray)
+	}
+	return result, err
+
+}
+
+// Redis HINCRBY command.
+func (c *asyncClient) Hincrby(arg0 string, arg1 string, arg2 int64) (result FutureInt64, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+	arg2bytes := []byte(fmt.Sprintf("%d", arg2))
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HINCRBY, [][]byte{arg0bytes, arg1bytes, arg2bytes})
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis HINCRBYFLOAT command.
+func (c *asyncClient) Hincrbyfloat(arg0 string, arg1 string, arg2 float64) (result FutureFloat64, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+	arg2bytes := []byte(fmt.Sprintf("%g", arg2))
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HINCRBYFLOAT, [][]byte{arg0bytes, arg1bytes, arg2bytes})
+	if err == nil {
+		result = resp.future.(FutureFloat64)
+	}
+	return result, err
+
+}
+
+// Redis HSETNX command.
+func (c *asyncClient) Hsetnx(arg0 string, arg1 string, arg2 []byte) (result FutureBool, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+	arg2bytes := arg2
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HSETNX, [][]byte{arg0bytes, arg1bytes, arg2bytes})
+	if err == nil {
+		result = resp.future.(FutureBool)
+	}
+	return result, err
+
+}
+
+// Redis HSTRLEN command.
+func (c *asyncClient) Hstrlen(arg0 string, arg1 string) (result FutureInt64, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&HSTRLEN, [][]byte{arg0bytes, arg1bytes})
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis SCAN command.
+func (c *asyncClient) Scan(cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error) {
+	return c.scan(&SCAN, nil, cursor, spec)
+}
+
+// Redis SSCAN command.
+func (c *asyncClient) Sscan(arg0 string, cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error) {
+	return c.scan(&SSCAN, []byte(arg0), cursor, spec)
+}
+
+// Redis HSCAN command.
+func (c *asyncClient) Hscan(arg0 string, cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error) {
+	return c.scan(&HSCAN, []byte(arg0), cursor, spec)
+}
+
+// Redis ZSCAN command.
+func (c *asyncClient) Zscan(arg0 string, cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error) {
+	return c.scan(&ZSCAN, []byte(arg0), cursor, spec)
+}
+
+// queues the SCAN family request for the page at the cursor.
+func (c *asyncClient) scan(cmd *Command, key []byte, cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(cmd, spec.args(key, cursor))
+	if err == nil {
+		result = newFutureScanPage(resp.future.(FutureReply))
+	}
+	return result, err
+}
+
+// Redis XADD command.
+func (c *asyncClient) Xadd(arg0 string, arg1 string, arg2 map[string][]byte, trim *StreamTrimSpec) (result FutureBytes, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&XADD, xaddArgs(arg0, arg1, arg2, trim))
 	if err == nil {
 		result = resp.future.(FutureBytes)
 	}
@@ -887,6 +1250,93 @@ func (c *asyncClient) Hgetall(arg0 string) (result FutureBytes, err Error) {
 
 }
 
+// Redis XRANGE command.
+func (c *asyncClient) Xrange(arg0 string, start, end string, count int64) (result FutureStreamEntries, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&XRANGE, xrangeArgs(arg0, start, end, count))
+	if err == nil {
+		result = newFutureStreamEntries(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis XREVRANGE command.
+func (c *asyncClient) Xrevrange(arg0 string, end, start string, count int64) (result FutureStreamEntries, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&XREVRANGE, xrangeArgs(arg0, end, start, count))
+	if err == nil {
+		result = newFutureStreamEntries(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis XLEN command.
+func (c *asyncClient) Xlen(arg0 string) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&XLEN, [][]byte{[]byte(arg0)})
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis XDEL command.
+func (c *asyncClient) Xdel(arg0 string, arg1 string, arg2 ...string) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&XDEL, appendAndConvert(arg0, append([]string{arg1}, arg2...)...))
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis XTRIM command.
+func (c *asyncClient) Xtrim(arg0 string, trim *StreamTrimSpec) (result FutureInt64, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&XTRIM, append([][]byte{[]byte(arg0)}, trim.args()...))
+	if err == nil {
+		result = resp.future.(FutureInt64)
+	}
+	return result, err
+
+}
+
+// Redis XREAD command.
+func (c *asyncClient) Xread(arg0 map[string]string, count int64) (result FutureStreams, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&XREAD, xreadArgs(arg0, count, false, 0))
+	if err == nil {
+		result = newFutureStreams(resp.future.(FutureReply))
+	}
+	return result, err
+
+}
+
+// Redis XREAD BLOCK command.
+func (c *asyncClient) XreadBlock(arg0 map[string]string, count int64, timeout time.Duration) (result FutureStreams, err Error) {
+	args := xreadArgs(arg0, count, true, timeout)
+	source, err := blockingSourceOf(c.conn, &XREAD, args)
+	if err != nil {
+		return nil, err
+	}
+
+	future := newFutureReply()
+	go func() {
+		resp, e := serviceBlocking(source, &XREAD, args, timeout)
+		if e != nil {
+			future.(FutureResult).onError(e)
+			return
+		}
+		future.set(resp.GetReply())
+	}()
+	return newFutureStreams(future), nil
+
+}
+
 // Redis FLUSHDB command.
 func (c *asyncClient) Flushdb() (stat FutureBool, err Error) {
 	resp, err := c.conn.QueueRequest(&FLUSHDB, [][]byte{})
@@ -953,3 +1403,68 @@ func (c *asyncClient) Publish(arg0 string, arg1 []byte) (result FutureInt64, err
 	}
 	return result, err
 }
+
+// Redis MULTI command.
+func (c *asyncClient) Multi() (tx AsyncTransaction, err Error) {
+	pipeline, ok := c.conn.(txPipeline)
+	if !ok {
+		return nil, newSystemError("connection does not support transactions")
+	}
+	return newAsyncTransaction(pipeline, pipeline), nil
+}
+
+// Redis EVAL command.
+func (c *asyncClient) Eval(arg0 string, arg1 []string, arg2 [][]byte) (result FutureReply, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&EVAL, scriptArgs(arg0, arg1, arg2))
+	if err == nil {
+		result = resp.future.(FutureReply)
+	}
+	return result, err
+}
+
+// Redis EVALSHA command.
+func (c *asyncClient) Evalsha(arg0 string, arg1 []string, arg2 [][]byte) (result FutureReply, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&EVALSHA, scriptArgs(arg0, arg1, arg2))
+	if err == nil {
+		result = resp.future.(FutureReply)
+	}
+	return result, err
+}
+
+// Redis SCRIPT LOAD command.
+func (c *asyncClient) ScriptLoad(arg0 string) (result FutureBytes, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&SCRIPT_LOAD, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.future.(FutureBytes)
+	}
+	return result, err
+}
+
+// Redis SCRIPT EXISTS command.
+func (c *asyncClient) ScriptExists(arg0 string, arg1 ...string) (result FutureBools, err Error) {
+	var resp *PendingResponse
+	resp, err = c.conn.QueueRequest(&SCRIPT_EXISTS, appendAndConvert(arg0, arg1...))
+	if err == nil {
+		result = newFutureBools(resp.future.(FutureReply))
+	}
+	return result, err
+}
+
+// Redis SCRIPT FLUSH command.
+func (c *asyncClient) ScriptFlush() (stat FutureBool, err Error) {
+	resp, err := c.conn.QueueRequest(&SCRIPT_FLUSH, [][]byte{})
+	if err == nil {
+		stat = resp.future.(FutureBool)
+	}
+	return
+}
+
+// Queues an arbitrary Redis command.
+func (c *asyncClient) Do(name string, args ...interface{}) (result FutureReply, err Error) {
+	return c.conn.Do(name, args...)
+}
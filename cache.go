@@ -0,0 +1,344 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// client side caching - see NewCachingClientWithSpec
+// -----------------------------------------------------------------------------
+
+// the pubsub channel of CLIENT TRACKING invalidation messages (RESP2)
+const trackingChannel = "__redis__:invalidate"
+
+// A Client with a local cache of GET and HGET results.  See
+// NewCachingClientWithSpec.
+type CachingClient interface {
+	Client
+
+	// Returns the cache statistics.
+	CacheStats() CacheStats
+}
+
+// Cache statistics of a CachingClient.
+type CacheStats struct {
+	Hits    uint64 // requests served from the cache
+	Misses  uint64 // requests served by the server
+	Entries int    // cached results
+}
+
+// Create a new CachingClient per the specified ConnectionSpec, caching up to
+// maxEntries GET and HGET results, least recently used first out.  All other
+// requests are serviced as is.  The client can be shared by multiple
+// goroutines.
+//
+// The cache is kept consistent per CLIENT TRACKING (Redis 6 and later).
+// The client's connection is tracked, and invalidations are redirected to a
+// dedicated PubSub connection, where they are received as messages (RESP2)
+// or push frames (RESP3 - see ConnectionSpec.RESP3).  Entries are also
+// evicted on requests of the client that (may) write their keys.  If the
+// invalidation connection is lost, the cache is flushed and the client
+// continues without caching.
+//
+// Quit() closes both connections.
+func NewCachingClientWithSpec(spec *ConnectionSpec, maxEntries int) (c CachingClient, err Error) {
+	if maxEntries <= 0 {
+		return nil, newSystemErrorf("NewCachingClientWithSpec - maxEntries must be positive - got %d", maxEntries)
+	}
+
+	// the tracking redirect is tied to the connection, so it is not
+	// reconnected
+	ispec := *spec
+	ispec.Reconnect(0)
+	var id int64
+	inval, err := newPubSubConnection(&ispec, func(hdl *connHdl) {
+		resp, e := hdl.ServiceRequest(&CLIENT_ID, [][]byte{})
+		if e != nil {
+			panic(e)
+		}
+		id = resp.GetNumberValue()
+	})
+	if err != nil {
+		return nil, withError(err)
+	}
+	pending, err := inval.ServiceSubscription(&SUBSCRIBE, ENVELOPE, [][]byte{[]byte(trackingChannel)})
+	if err == nil {
+		_, err = pending[trackingChannel].Get()
+	}
+	s := inval.Subscriptions()[SubscriptionKey{trackingChannel, false}]
+	if err == nil && s == nil {
+		err = newSystemError("NewCachingClientWithSpec - subscription to " + trackingChannel + " failed")
+	}
+	if err != nil {
+		inval.ServiceRequest(&QUIT, [][]byte{})
+		return nil, withError(err)
+	}
+
+	conn, err := NewSyncConnection(spec)
+	if err != nil {
+		inval.ServiceRequest(&QUIT, [][]byte{})
+		return nil, withError(err)
+	}
+	args := [][]byte{[]byte("ON"), []byte("REDIRECT"), []byte(strconv.FormatInt(id, 10))}
+	if _, err = conn.ServiceRequest(&CLIENT_TRACKING, args); err != nil {
+		conn.ServiceRequest(&QUIT, [][]byte{})
+		inval.ServiceRequest(&QUIT, [][]byte{})
+		return nil, withError(err)
+	}
+
+	cconn := &cachingConn{
+		conn:  conn,
+		inval: inval,
+		cache: newClientCache(maxEntries),
+	}
+	go cconn.invalidate(s.Envelopes)
+
+	return &cachingClient{syncClient{cconn}}, nil
+}
+
+type cachingClient struct {
+	syncClient
+}
+
+func (c *cachingClient) CacheStats() CacheStats {
+	return c.conn.(*cachingConn).cache.stats()
+}
+
+// -----------------------------------------------------------------------------
+// cachingConn - supports SyncConnection interface
+// -----------------------------------------------------------------------------
+
+// cachingConn services GET and HGET requests from its cache, or the
+// (tracked) delegate connection on misses.
+type cachingConn struct {
+	conn  SyncConnection
+	inval *asyncConnHdl // pubsub connection receiving invalidations
+	cache *clientCache
+}
+
+// Implementation of SyncConnection.ServiceRequest
+func (c *cachingConn) ServiceRequest(cmd *Command, args [][]byte) (Response, Error) {
+	switch cmd {
+	case &GET, &HGET:
+		return c.serviceCached(cmd, args)
+	case &QUIT:
+		resp, err := c.conn.ServiceRequest(cmd, args)
+		c.inval.ServiceRequest(&QUIT, [][]byte{})
+		c.cache.flush()
+		return resp, err
+	}
+	c.cache.evict(requestKeys(cmd, args))
+	return c.conn.ServiceRequest(cmd, args)
+}
+
+// Implementation of SyncConnection.Do
+func (c *cachingConn) Do(name string, args ...interface{}) (*Reply, Error) {
+	return serviceDo(c, name, args)
+}
+
+func (c *cachingConn) serviceCached(cmd *Command, args [][]byte) (Response, Error) {
+	key, field := string(args[0]), cmd.Code
+	if cmd == &HGET {
+		field += " " + string(args[1])
+	}
+
+	value, ok, epoch := c.cache.get(key, field)
+	if ok {
+		return &_response{bulkdata: value}, nil
+	}
+	resp, err := c.conn.ServiceRequest(cmd, args)
+	if err == nil {
+		c.cache.put(key, field, resp.GetBulkData(), epoch)
+	}
+	return resp, err
+}
+
+// applies the invalidation messages until the channel is closed (i.e. the
+// connection is lost), and then disables the cache.
+func (c *cachingConn) invalidate(messages <-chan *Message) {
+	for msg := range messages {
+		if msg.Keys == nil {
+			c.cache.flush()
+			continue
+		}
+		c.cache.evict(msg.Keys)
+	}
+	c.cache.disable()
+}
+
+// txConnection support - transactions are serviced on the delegate
+// connection, and as the keys they write are not known, flush the cache.
+func (c *cachingConn) checkout() (*connHdl, Error) {
+	source, ok := c.conn.(txConnection)
+	if !ok {
+		return nil, newSystemError("connection does not support transactions")
+	}
+	return source.checkout()
+}
+
+func (c *cachingConn) release(hdl *connHdl, broken bool) {
+	c.cache.flush()
+	c.conn.(txConnection).release(hdl, broken)
+}
+
+// Returns the keys that the request may write.  (All args of MULTI_KEY
+// requests, e.g. per Do, are assumed to be keys.)
+func requestKeys(cmd *Command, args [][]byte) [][]byte {
+	switch {
+	case len(args) == 0:
+		return nil
+	case cmd.ReqType == MULTI_KEY:
+		return args
+	case cmd.ReqType == KEY_KEY, cmd.ReqType == KEY_KEY_VALUE:
+		return args[:2]
+	case cmd.ReqType == SCRIPT_SPEC:
+		numkeys, e := strconv.Atoi(string(args[1]))
+		if e != nil || numkeys < 0 || 2+numkeys > len(args) {
+			return nil
+		}
+		return args[2 : 2+numkeys]
+	}
+	return args[:1]
+}
+
+// -----------------------------------------------------------------------------
+// clientCache
+// -----------------------------------------------------------------------------
+
+// clientCache is a bounded LRU cache of request results, by key and field
+// (the command, and its hash field, if any).
+//
+// Results of requests that were in flight when an invalidation was
+// received are not cached (see epoch), as they may predate it.
+type clientCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	lru        *list.List                          // of *cacheEntry - most recently used first
+	keys       map[string]map[string]*list.Element // key -> field -> entry
+	epoch      uint64                              // invalidation count
+	disabled   bool
+	hits       uint64
+	misses     uint64
+}
+
+type cacheEntry struct {
+	key   string
+	field string
+	value []byte
+}
+
+func newClientCache(maxEntries int) *clientCache {
+	return &clientCache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		keys:       make(map[string]map[string]*list.Element),
+	}
+}
+
+// Returns a copy of the cached value, if any, and the current epoch.
+func (c *clientCache) get(key, field string) (value []byte, ok bool, epoch uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, found := c.keys[key][field]; found {
+		c.hits++
+		c.lru.MoveToFront(elem)
+		if v := elem.Value.(*cacheEntry).value; v != nil {
+			value = append(make([]byte, 0, len(v)), v...)
+		}
+		return value, true, c.epoch
+	}
+	c.misses++
+	return nil, false, c.epoch
+}
+
+// Caches the value, unless invalidations were received since epoch.
+func (c *clientCache) put(key, field string, value []byte, epoch uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.disabled || epoch != c.epoch {
+		return
+	}
+	fields := c.keys[key]
+	if fields == nil {
+		fields = make(map[string]*list.Element)
+		c.keys[key] = fields
+	}
+	if elem, found := fields[field]; found {
+		elem.Value.(*cacheEntry).value = value
+		c.lru.MoveToFront(elem)
+		return
+	}
+	fields[field] = c.lru.PushFront(&cacheEntry{key, field, value})
+	if c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *clientCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	fields := c.keys[entry.key]
+	delete(fields, entry.field)
+	if len(fields) == 0 {
+		delete(c.keys, entry.key)
+	}
+}
+
+// Evicts all entries of the keys.
+func (c *clientCache) evict(keys [][]byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.epoch++
+	for _, key := range keys {
+		for _, elem := range c.keys[string(key)] {
+			c.remove(elem)
+		}
+	}
+}
+
+// Evicts all entries.
+func (c *clientCache) flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.epoch++
+	c.lru.Init()
+	c.keys = make(map[string]map[string]*list.Element)
+}
+
+// Flushes the cache, and stops caching.
+func (c *clientCache) disable() {
+	c.flush()
+	c.mutex.Lock()
+	c.disabled = true
+	c.mutex.Unlock()
+}
+
+func (c *clientCache) stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return CacheStats{c.hits, c.misses, c.lru.Len()}
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("CacheStats [hits:%d misses:%d entries:%d]", s.Hits, s.Misses, s.Entries)
+}
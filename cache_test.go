@@ -0,0 +1,255 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// trackingRedis is a fake redis supporting GET, SET, HGET, HSET, and the
+// CLIENT ID and CLIENT TRACKING handshake of caching clients.
+// Invalidations are pushed by the tests - see invalidation.
+type trackingRedis struct {
+	mutex sync.Mutex
+	data  map[string]string
+	reads int // GET and HGET count
+	ids   int
+}
+
+func newTrackingRedis() *trackingRedis {
+	return &trackingRedis{data: make(map[string]string)}
+}
+
+func (r *trackingRedis) set(key, value string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.data[key] = value
+}
+
+func (r *trackingRedis) readCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.reads
+}
+
+func (r *trackingRedis) session() func(args []string) string {
+	resp3 := false
+	return func(args []string) string {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		switch args[0] {
+		case "HELLO":
+			resp3 = args[1] == "3"
+			return "%1\r\n$5\r\nproto\r\n:3\r\n"
+		case "CLIENT":
+			if args[1] == "ID" {
+				r.ids++
+				return ":" + strconv.Itoa(r.ids) + "\r\n"
+			}
+			if args[1] == "TRACKING" && args[2] == "ON" && args[3] == "REDIRECT" {
+				return "+OK\r\n"
+			}
+		case "SUBSCRIBE":
+			if resp3 {
+				return ">3\r\n" + bulkReply("subscribe") + bulkReply(args[1]) + ":1\r\n"
+			}
+			return "*3\r\n" + bulkReply("subscribe") + bulkReply(args[1]) + ":1\r\n"
+		case "GET", "HGET":
+			r.reads++
+			key := args[1]
+			if args[0] == "HGET" {
+				key += "." + args[2]
+			}
+			if v, ok := r.data[key]; ok {
+				return bulkReply(v)
+			}
+			return "$-1\r\n"
+		case "SET":
+			r.data[args[1]] = args[2]
+			return "+OK\r\n"
+		case "HSET":
+			r.data[args[1]+"."+args[2]] = args[3]
+			return ":1\r\n"
+		case "QUIT":
+			return "+OK\r\n"
+		}
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+// returns the raw invalidation of the keys (all keys if none) sent to
+// the redirect connection.
+func invalidation(resp3 bool, keys ...string) string {
+	raw := "*" + strconv.Itoa(len(keys)) + "\r\n"
+	if len(keys) == 0 {
+		raw = "*-1\r\n"
+	}
+	for _, key := range keys {
+		raw += bulkReply(key)
+	}
+	if resp3 {
+		return ">2\r\n" + bulkReply("invalidate") + raw
+	}
+	return "*3\r\n" + bulkReply("message") + bulkReply(trackingChannel) + raw
+}
+
+// waits until Get of the key returns the value.
+func awaitValue(t *testing.T, client Client, key, value string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if v, e := client.Get(key); e == nil && string(v) == value {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %s of %s", value, key)
+}
+
+func TestCachingClient(t *testing.T) {
+	for _, resp3 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("resp3=%t", resp3), func(t *testing.T) {
+			redis := newTrackingRedis()
+			server := newFakeSessionServer(t, redis.session)
+			defer server.close()
+
+			client, e := NewCachingClientWithSpec(server.spec().RESP3(resp3), 100)
+			if e != nil {
+				t.Fatalf("NewCachingClientWithSpec - %s", e)
+			}
+			defer client.Quit()
+
+			if e := client.Set("foo", []byte("bar")); e != nil {
+				t.Fatalf("Set - %s", e)
+			}
+			for i := 0; i < 3; i++ {
+				if v, e := client.Get("foo"); e != nil || string(v) != "bar" {
+					t.Fatalf("expected GET bar - got %s %s", v, e)
+				}
+				if v, e := client.Hget("hash", "f"); e != nil || v != nil {
+					t.Fatalf("expected HGET nil - got %s %s", v, e)
+				}
+			}
+			if n := redis.readCount(); n != 2 {
+				t.Errorf("expected 2 reads at server - got %d", n)
+			}
+			if stats := client.CacheStats(); stats.Hits != 4 || stats.Misses != 2 || stats.Entries != 2 {
+				t.Errorf("unexpected %s", stats)
+			}
+
+			// invalidation by another client
+			redis.set("foo", "baz")
+			server.pushTo(0, invalidation(resp3, "foo"))
+			awaitValue(t, client, "foo", "baz")
+
+			// writes of the client evict its entries
+			if e := client.Hset("hash", "f", []byte("v")); e != nil {
+				t.Fatalf("Hset - %s", e)
+			}
+			if v, e := client.Hget("hash", "f"); e != nil || string(v) != "v" {
+				t.Errorf("expected HGET v - got %s %s", v, e)
+			}
+
+			server.pushTo(0, invalidation(resp3))
+			deadline := time.Now().Add(5 * time.Second)
+			for client.CacheStats().Entries != 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if stats := client.CacheStats(); stats.Entries != 0 {
+				t.Errorf("expected flushed cache - got %s", stats)
+			}
+		})
+	}
+}
+
+func TestCachingClientLostInvalidations(t *testing.T) {
+	redis := newTrackingRedis()
+	server := newFakeSessionServer(t, redis.session)
+	defer server.close()
+
+	client, e := NewCachingClientWithSpec(server.spec(), 100)
+	if e != nil {
+		t.Fatalf("NewCachingClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	redis.set("foo", "bar")
+	awaitValue(t, client, "foo", "bar")
+
+	// no invalidations - no caching
+	server.connAt(0).Close()
+	redis.set("foo", "baz")
+	awaitValue(t, client, "foo", "baz")
+	reads := redis.readCount()
+	client.Get("foo")
+	client.Get("foo")
+	if n := redis.readCount() - reads; n != 2 {
+		t.Errorf("expected reads at server - got %d", n)
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	cache := newClientCache(2)
+
+	_, _, epoch := cache.get("a", "GET")
+	cache.put("a", "GET", []byte("1"), epoch)
+	cache.put("b", "GET", []byte("2"), epoch)
+	cache.get("a", "GET")
+	cache.put("c", "GET", []byte("3"), epoch)
+	if _, ok, _ := cache.get("b", "GET"); ok {
+		t.Error("expected least recently used b to be evicted")
+	}
+	if v, ok, _ := cache.get("a", "GET"); !ok || string(v) != "1" {
+		t.Errorf("expected a - got %s %t", v, ok)
+	}
+
+	// results that predate invalidations are not cached
+	_, _, epoch = cache.get("d", "GET")
+	cache.evict([][]byte{[]byte("d")})
+	cache.put("d", "GET", []byte("4"), epoch)
+	if _, ok, _ := cache.get("d", "GET"); ok {
+		t.Error("expected stale d not to be cached")
+	}
+
+	// all fields of the key are evicted
+	cache = newClientCache(10)
+	_, _, epoch = cache.get("h", "HGET f1")
+	cache.put("h", "HGET f1", []byte("1"), epoch)
+	cache.put("h", "HGET f2", []byte("2"), epoch)
+	cache.evict([][]byte{[]byte("h")})
+	if stats := cache.stats(); stats.Entries != 0 {
+		t.Errorf("expected no entries - got %s", stats)
+	}
+
+	// concurrent use
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := strconv.Itoa(j % 5)
+				if _, ok, epoch := cache.get(key, "GET"); !ok {
+					cache.put(key, "GET", []byte(key), epoch)
+				}
+				if j%100 == i {
+					cache.evict([][]byte{[]byte(key)})
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if stats := cache.stats(); stats.Entries > 5 || stats.Hits == 0 || stats.Hits+stats.Misses != 8*1000+1 {
+		t.Errorf("unexpected %s", stats)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_cache(t *testing.T) {
+	log.Println("-- cache test completed")
+}
@@ -0,0 +1,437 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// -----------------------------------------------------------------------------
+// redis cluster - see NewClusterSynchClientWithSpec
+// -----------------------------------------------------------------------------
+
+const (
+	clusterSlots        = 16384 // hash slots of a redis cluster
+	clusterMaxRedirects = 5     // MOVED and ASK redirects followed per request
+)
+
+// Create a new Client for the Redis cluster of the (seed) node per the
+// specified ConnectionSpec.  The client can be shared by multiple
+// goroutines.
+//
+// The slot map of the cluster is fetched per CLUSTER SLOTS, and requests are
+// routed to the master serving the hash slot of their key(s), on one async
+// connection per node.  Node connections are per the spec, with the host and
+// port of the node (unix sockets are not supported).
+//
+// MOVED and ASK redirects are followed (up to 5 per request), and MOVED
+// redirects and lost node connections trigger a refresh of the slot map.
+// Requests with keys in distinct slots fail with a CROSSSLOT RedisError (see
+// {hashtags} of hashTag).  Keyless requests (e.g. DBSIZE or KEYS) are
+// serviced by a single node.  Transactions and blocking requests (i.e.
+// XreadBlock) are not supported.
+//
+// Quit() closes all connections.
+func NewClusterSynchClientWithSpec(spec *ConnectionSpec) (c Client, err Error) {
+	conn, err := newClusterConn(spec)
+	if err != nil {
+		return nil, withError(err)
+	}
+	return &syncClient{&clusterSyncConn{conn}}, nil
+}
+
+// Create a new AsyncClient for the Redis cluster of the (seed) node per the
+// specified ConnectionSpec.  See NewClusterSynchClientWithSpec.
+func NewClusterAsynchClientWithSpec(spec *ConnectionSpec) (c AsyncClient, err Error) {
+	conn, err := newClusterConn(spec)
+	if err != nil {
+		return nil, withError(err)
+	}
+	return &asyncClient{conn}, nil
+}
+
+// Returns the hash slot of the key - CRC16 (XMODEM) of its hashTag mod
+// 16384.
+func keySlot(key []byte) int {
+	return int(crc16(hashTag(key))) % clusterSlots
+}
+
+// Returns the part of the key that is hashed: if the key has a non empty
+// {hashtag}, the hashtag, and otherwise the key, so that e.g.
+// {user1000}.following and {user1000}.followers hash alike.
+func hashTag(key []byte) []byte {
+	for i, b := range key {
+		if b != '{' {
+			continue
+		}
+		for j := i + 1; j < len(key); j++ {
+			if key[j] == '}' {
+				if j > i+1 {
+					return key[i+1 : j]
+				}
+				break
+			}
+		}
+		break
+	}
+	return key
+}
+
+// CRC16 per the XMODEM polynomial (0x1021), as used by redis cluster.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// Returns the keys of the request, per which it is routed.  Ad-hoc (Do)
+// requests are routed per their first arg.
+func commandKeys(cmd *Command, args [][]byte) [][]byte {
+	switch cmd {
+	case &MGET, &SINTER, &SINTERSTORE, &SUNION, &SUNIONSTORE, &SDIFF, &SDIFFSTORE, &WATCH:
+		return args
+	case &RENAME, &RENAMENX, &RPOPLPUSH, &BRPOPLPUSH, &SMOVE:
+		if len(args) > 2 {
+			return args[:2]
+		}
+		return args
+	case &EVAL, &EVALSHA:
+		return requestKeys(cmd, args)
+	case &SORT_STORE:
+		// key ... STORE dst
+		if len(args) > 2 {
+			return [][]byte{args[0], args[len(args)-1]}
+		}
+		return args
+	case &ZUNIONSTORE, &ZINTERSTORE:
+		// dst numkeys key [key ...] - the keys follow numkeys as with EVAL
+		return append([][]byte{args[0]}, requestKeys(&EVAL, args)...)
+	case &XREAD:
+		return xreadKeys(args)
+	case &AUTH, &AUTH_USER, &HELLO, &SELECT, &KEYS, &SCAN, &PUBLISH,
+		&SCRIPT_LOAD, &SCRIPT_EXISTS, &CLIENT_TRACKING,
+		&SUBSCRIBE, &UNSUBSCRIBE, &PSUBSCRIBE, &PUNSUBSCRIBE:
+		return nil
+	}
+	if cmd.ReqType == NO_ARG || len(args) == 0 {
+		return nil
+	}
+	return args[:1]
+}
+
+// Returns the host and port of the host:port address.
+func splitAddr(addr string) (host string, port int, err Error) {
+	host, portstr, e := net.SplitHostPort(addr)
+	if e == nil {
+		port, e = strconv.Atoi(portstr)
+	}
+	if e != nil {
+		return "", 0, newSystemErrorWithCause("invalid address "+addr, e)
+	}
+	return host, port, nil
+}
+
+// -----------------------------------------------------------------------------
+// clusterConn - supports AsyncConnection interface
+// -----------------------------------------------------------------------------
+
+// clusterConn routes requests to the async connections of the cluster
+// nodes, per its slot map.
+type clusterConn struct {
+	spec       *ConnectionSpec // of the nodes, sans host and port
+	seed       string          // host:port of the seed node
+	lock       sync.RWMutex
+	slots      []string                // slot -> host:port of its master
+	nodes      map[string]*clusterNode // by host:port
+	closed     bool
+	refreshing int32 // 1 while refreshing - see triggerRefresh
+}
+
+type clusterNode struct {
+	addr  string
+	conn  *asyncConnHdl
+	qlock sync.Mutex // held while queuing - see queueOn
+}
+
+func newClusterConn(spec *ConnectionSpec) (*clusterConn, Error) {
+	nspec := *spec
+	nspec.Socket("")
+	c := &clusterConn{
+		spec:  &nspec,
+		seed:  net.JoinHostPort(spec.host, strconv.Itoa(spec.port)),
+		nodes: make(map[string]*clusterNode),
+	}
+	if err := c.refresh(); err != nil {
+		c.quit()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Implementation of AsyncConnection.QueueRequest
+func (c *clusterConn) QueueRequest(cmd *Command, args [][]byte) (*PendingResponse, Error) {
+	if cmd == &QUIT {
+		return c.quit(), nil
+	}
+	addr, err := c.route(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := c.queueOn(addr, false, cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	future := CreateFuture(cmd)
+	go c.redirect(cmd, args, pending.future, future)
+	return &PendingResponse{future}, nil
+}
+
+// Implementation of AsyncConnection.Do
+func (c *clusterConn) Do(name string, args ...interface{}) (FutureReply, Error) {
+	return queueDo(c, name, args)
+}
+
+// Returns the address of the node serving the keys of the request.
+func (c *clusterConn) route(cmd *Command, args [][]byte) (string, Error) {
+	keys := commandKeys(cmd, args)
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	slot := 0
+	if len(keys) > 0 {
+		slot = keySlot(keys[0])
+		for _, key := range keys[1:] {
+			if keySlot(key) != slot {
+				return "", newRedisError(fmt.Sprintf("[%s]: CROSSSLOT Keys in request don't hash to the same slot", cmd.Code))
+			}
+		}
+	}
+	if addr := c.slots[slot]; addr != "" {
+		return addr, nil
+	}
+	return c.seed, nil
+}
+
+// Returns the node of the address, (re)connecting to it as needed.
+func (c *clusterConn) node(addr string) (*clusterNode, Error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil, newConnectionClosedError(nil)
+	}
+	if n := c.nodes[addr]; n != nil {
+		select {
+		case <-n.conn.shutdown:
+		default:
+			return n, nil
+		}
+	}
+
+	host, port, err := splitAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	spec := *c.spec
+	spec.Host(host).Port(port)
+	conn, err := NewAsynchConnection(&spec)
+	if err != nil {
+		return nil, err
+	}
+	n := &clusterNode{addr: addr, conn: conn.(*asyncConnHdl)}
+	c.nodes[addr] = n
+	return n, nil
+}
+
+// Queues the request on the node of the address, immediately preceded by
+// ASKING if asking.
+func (c *clusterConn) queueOn(addr string, asking bool, cmd *Command, args [][]byte) (*PendingResponse, Error) {
+	n, err := c.node(addr)
+	if err != nil {
+		return nil, err
+	}
+	n.qlock.Lock()
+	defer n.qlock.Unlock()
+
+	if asking {
+		if _, err = n.conn.QueueRequest(&ASKING, [][]byte{}); err != nil {
+			return nil, err
+		}
+	}
+	return n.conn.QueueRequest(cmd, args)
+}
+
+// Sets the result of the pending request on the future, following its
+// MOVED and ASK redirects.
+func (c *clusterConn) redirect(cmd *Command, args [][]byte, pending interface{}, future interface{}) {
+	for redirects := 0; ; redirects++ {
+		r := <-resultChan(pending)
+		if _, ok := r.e.(ConnectionClosedError); ok {
+			c.triggerRefresh()
+		}
+		redirect, ok := r.e.(RedirectError)
+		if !ok || redirects == clusterMaxRedirects {
+			resultChan(future) <- r
+			return
+		}
+		if !redirect.Ask() {
+			c.lock.Lock()
+			c.slots[redirect.Slot()] = redirect.Addr()
+			c.lock.Unlock()
+			c.triggerRefresh()
+		}
+		next, err := c.queueOn(redirect.Addr(), redirect.Ask(), cmd, args)
+		if err != nil {
+			resultChan(future) <- result{nil, err}
+			return
+		}
+		pending = next.future
+	}
+}
+
+// Refreshes the slot map in the background, unless already refreshing.
+func (c *clusterConn) triggerRefresh() {
+	if atomic.CompareAndSwapInt32(&c.refreshing, 0, 1) {
+		go func() {
+			defer atomic.StoreInt32(&c.refreshing, 0)
+			c.refresh()
+		}()
+	}
+}
+
+// Fetches the slot map from the first responsive node, seed first.
+func (c *clusterConn) refresh() (err Error) {
+	c.lock.RLock()
+	addrs := []string{c.seed}
+	for addr := range c.nodes {
+		if addr != c.seed {
+			addrs = append(addrs, addr)
+		}
+	}
+	c.lock.RUnlock()
+
+	for _, addr := range addrs {
+		var slots []string
+		if slots, err = c.fetchSlots(addr); err == nil {
+			c.lock.Lock()
+			c.slots = slots
+			c.lock.Unlock()
+			return nil
+		}
+	}
+	return err
+}
+
+func (c *clusterConn) fetchSlots(addr string) ([]string, Error) {
+	pending, err := c.queueOn(addr, false, &CLUSTER_SLOTS, [][]byte{})
+	if err != nil {
+		return nil, err
+	}
+	reply, err := pending.future.(FutureReply).Get()
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	return parseClusterSlots(reply, host)
+}
+
+// Returns the slot map (slot -> host:port of its master) per the CLUSTER
+// SLOTS reply of a node of the host.  Masters with an empty or unknown ("?")
+// host are on the host of the node.
+func parseClusterSlots(reply *Reply, host string) ([]string, Error) {
+	if reply.Type != REPLY_ARRAY {
+		return nil, newSystemErrorf("CLUSTER SLOTS - unexpected reply %s", reply)
+	}
+	slots := make([]string, clusterSlots)
+	for _, r := range reply.Array {
+		if len(r.Array) < 3 || len(r.Array[2].Array) < 2 {
+			return nil, newSystemErrorf("CLUSTER SLOTS - unexpected slot range %s", r)
+		}
+		start, end := r.Array[0].Integer, r.Array[1].Integer
+		if start < 0 || end < start || end >= clusterSlots {
+			return nil, newSystemErrorf("CLUSTER SLOTS - invalid slot range %d-%d", start, end)
+		}
+		master := r.Array[2].Array
+		mhost := string(master[0].Bulk)
+		if mhost == "" || mhost == "?" {
+			mhost = host
+		}
+		addr := net.JoinHostPort(mhost, strconv.FormatInt(master[1].Integer, 10))
+		for slot := start; slot <= end; slot++ {
+			slots[slot] = addr
+		}
+	}
+	return slots, nil
+}
+
+// Quits all node connections, and returns the (set) QUIT response.
+func (c *clusterConn) quit() *PendingResponse {
+	c.lock.Lock()
+	c.closed = true
+	nodes := c.nodes
+	c.nodes = make(map[string]*clusterNode)
+	c.lock.Unlock()
+
+	for _, n := range nodes {
+		if pending, err := n.conn.QueueRequest(&QUIT, [][]byte{}); err == nil {
+			pending.future.(FutureBool).Get()
+		}
+	}
+	future := newFutureBool()
+	future.set(true)
+	return &PendingResponse{future}
+}
+
+// -----------------------------------------------------------------------------
+// clusterSyncConn - supports SyncConnection interface
+// -----------------------------------------------------------------------------
+
+// clusterSyncConn services requests on its clusterConn, blocking on their
+// results.
+type clusterSyncConn struct {
+	*clusterConn
+}
+
+// Implementation of SyncConnection.ServiceRequest
+func (c *clusterSyncConn) ServiceRequest(cmd *Command, args [][]byte) (Response, Error) {
+	pending, err := c.QueueRequest(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	r := <-resultChan(pending.future)
+	if r.e != nil {
+		return nil, r.e
+	}
+	return futureResponse(cmd, r.v), nil
+}
+
+// Implementation of SyncConnection.Do
+func (c *clusterSyncConn) Do(name string, args ...interface{}) (*Reply, Error) {
+	return serviceDo(c, name, args)
+}
@@ -0,0 +1,384 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// in-process fake cluster
+// ----------------------------------------------------------------------------
+
+// fakeCluster is a redis cluster of fake nodes serving GET, SET, MGET, and
+// CLUSTER SLOTS per a shared slot map.  Requests for slots of other nodes
+// are redirected per MOVED, and requests for keys no longer on a node of a
+// migrating slot per ASK.
+type fakeCluster struct {
+	mutex     sync.Mutex
+	nodes     []*fakeServer
+	data      []map[string]string // per node
+	owners    [clusterSlots]int   // slot -> node
+	migrating map[int]int         // slot -> target node
+	slotsReqs int                 // CLUSTER SLOTS requests
+	moved     int                 // MOVED redirects
+}
+
+func newFakeCluster(t *testing.T, n int) *fakeCluster {
+	c := &fakeCluster{migrating: make(map[int]int)}
+	for slot := range c.owners {
+		c.owners[slot] = slot * n / clusterSlots
+	}
+	for i := 0; i < n; i++ {
+		node := i
+		c.data = append(c.data, make(map[string]string))
+		c.nodes = append(c.nodes, newFakeSessionServer(t, func() func(args []string) string {
+			return c.session(node)
+		}))
+	}
+	return c
+}
+
+func (c *fakeCluster) close() {
+	for _, node := range c.nodes {
+		node.close()
+	}
+}
+
+func (c *fakeCluster) addr(node int) string {
+	return c.nodes[node].listener.Addr().String()
+}
+
+// returns the node holding the key, and its value there.
+func (c *fakeCluster) lookup(key string) (node int, value string, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for node, data := range c.data {
+		if value, ok = data[key]; ok {
+			return node, value, ok
+		}
+	}
+	return -1, "", false
+}
+
+func (c *fakeCluster) counts() (slotsReqs, moved int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.slotsReqs, c.moved
+}
+
+// moves the slot (and its keys) to the node.
+func (c *fakeCluster) move(slot, node int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	owner := c.owners[slot]
+	for key, value := range c.data[owner] {
+		if keySlot([]byte(key)) == slot {
+			c.data[node][key] = value
+			delete(c.data[owner], key)
+		}
+	}
+	c.owners[slot] = node
+}
+
+// starts migrating the slot to the node.
+func (c *fakeCluster) migrate(slot, node int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.migrating[slot] = node
+}
+
+// the CLUSTER SLOTS reply - masters of node 0 have no host.
+func (c *fakeCluster) slotsReply() string {
+	var ranges []string
+	for start := 0; start < clusterSlots; {
+		end := start
+		for end+1 < clusterSlots && c.owners[end+1] == c.owners[start] {
+			end++
+		}
+		host, port, _ := net.SplitHostPort(c.addr(c.owners[start]))
+		if c.owners[start] == 0 {
+			host = ""
+		}
+		ranges = append(ranges, fmt.Sprintf("*3\r\n:%d\r\n:%d\r\n*3\r\n%s:%s\r\n%s",
+			start, end, bulkReply(host), port, bulkReply("id"+strconv.Itoa(c.owners[start]))))
+		start = end + 1
+	}
+	return "*" + strconv.Itoa(len(ranges)) + "\r\n" + strings.Join(ranges, "")
+}
+
+func (c *fakeCluster) session(node int) func(args []string) string {
+	asking := false
+	return func(args []string) string {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		wasAsking := asking
+		asking = false
+		switch args[0] {
+		case "CLUSTER":
+			c.slotsReqs++
+			return c.slotsReply()
+		case "ASKING":
+			asking = true
+			return "+OK\r\n"
+		case "PING":
+			return "+PONG\r\n"
+		case "QUIT":
+			return "+OK\r\n"
+		case "GET", "SET", "MGET":
+		default:
+			return "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+
+		keys := args[1:2]
+		if args[0] == "MGET" {
+			keys = args[1:]
+		}
+		slot := keySlot([]byte(keys[0]))
+		for _, key := range keys[1:] {
+			if keySlot([]byte(key)) != slot {
+				return "-CROSSSLOT Keys in request don't hash to the same slot\r\n"
+			}
+		}
+		data := c.data[node]
+		target, migrating := c.migrating[slot]
+		switch {
+		case c.owners[slot] != node && !(migrating && target == node && wasAsking):
+			c.moved++
+			return fmt.Sprintf("-MOVED %d %s\r\n", slot, c.addr(c.owners[slot]))
+		case c.owners[slot] == node && migrating:
+			if _, ok := data[keys[0]]; !ok {
+				return fmt.Sprintf("-ASK %d %s\r\n", slot, c.addr(target))
+			}
+		}
+
+		switch args[0] {
+		case "GET":
+			if v, ok := data[args[1]]; ok {
+				return bulkReply(v)
+			}
+			return "$-1\r\n"
+		case "SET":
+			data[args[1]] = args[2]
+			return "+OK\r\n"
+		}
+		reply := "*" + strconv.Itoa(len(keys)) + "\r\n"
+		for _, key := range keys {
+			if v, ok := data[key]; ok {
+				reply += bulkReply(v)
+			} else {
+				reply += "$-1\r\n"
+			}
+		}
+		return reply
+	}
+}
+
+// ----------------------------------------------------------------------------
+// tests
+// ----------------------------------------------------------------------------
+
+func TestKeySlot(t *testing.T) {
+	if crc := crc16([]byte("123456789")); crc != 0x31c3 {
+		t.Errorf("expected CRC16 0x31c3 - got %#x", crc)
+	}
+	slots := map[string]int{
+		"":          0,
+		"123456789": 0x31c3,
+		"foo":       12182,
+		"bar":       5061,
+	}
+	for key, expected := range slots {
+		if slot := keySlot([]byte(key)); slot != expected {
+			t.Errorf("%q - expected slot %d - got %d", key, expected, slot)
+		}
+	}
+
+	// hashtags
+	hashtags := map[string]string{
+		"{user1000}.following": "user1000",
+		"{user1000}.followers": "user1000",
+		"foo{}{bar}":           "foo{}{bar}",
+		"foo{{bar}}zap":        "{bar",
+		"foo{bar}{zap}":        "bar",
+		"{bar":                 "{bar",
+	}
+	for key, hashed := range hashtags {
+		if slot, expected := keySlot([]byte(key)), keySlot([]byte(hashed)); slot != expected {
+			t.Errorf("%q - expected slot %d of %q - got %d", key, expected, hashed, slot)
+		}
+	}
+}
+
+func TestCommandKeys(t *testing.T) {
+	args := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	cases := []struct {
+		cmd      *Command
+		args     [][]byte
+		expected int
+	}{
+		{&GET, args[:1], 1},
+		{&HSET, args, 1},
+		{&MGET, args, 3},
+		{&RENAME, args[:2], 2},
+		{&BRPOPLPUSH, args, 2},
+		{&EVAL, [][]byte{[]byte("script"), []byte("2"), []byte("a"), []byte("b"), []byte("arg")}, 2},
+		{&KEYS, args[:1], 0},
+		{&DBSIZE, nil, 0},
+		{&Command{"OBJECT ENCODING", MULTI_KEY, REPLY, false}, args[:1], 1},
+	}
+	for _, c := range cases {
+		if keys := commandKeys(c.cmd, c.args); len(keys) != c.expected {
+			t.Errorf("%s - expected %d keys - got %q", c.cmd.Code, c.expected, keys)
+		}
+	}
+}
+
+func TestClusterSyncClient(t *testing.T) {
+	cluster := newFakeCluster(t, 3)
+	defer cluster.close()
+
+	client, e := NewClusterSynchClientWithSpec(cluster.nodes[1].spec())
+	if e != nil {
+		t.Fatalf("NewClusterSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	nodes := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		key := "key" + strconv.Itoa(i)
+		if e := client.Set(key, []byte(strconv.Itoa(i))); e != nil {
+			t.Fatalf("Set %s - %s", key, e)
+		}
+		node, _, ok := cluster.lookup(key)
+		if !ok || node != keySlot([]byte(key))*3/clusterSlots {
+			t.Errorf("%s - expected on the node of its slot - got %d", key, node)
+		}
+		nodes[node] = true
+		if v, e := client.Get(key); e != nil || string(v) != strconv.Itoa(i) {
+			t.Errorf("expected GET %d - got %s %s", i, v, e)
+		}
+	}
+	if len(nodes) != 3 {
+		t.Errorf("expected keys on all nodes - got %v", nodes)
+	}
+	if _, moved := cluster.counts(); moved != 0 {
+		t.Errorf("expected no redirects - got %d", moved)
+	}
+
+	client.Set("{user1000}.following", []byte("a"))
+	client.Set("{user1000}.followers", []byte("b"))
+	values, e := client.Mget("{user1000}.following", []string{"{user1000}.followers"})
+	if e != nil || len(values) != 2 || string(values[0]) != "a" || string(values[1]) != "b" {
+		t.Errorf("expected MGET [a b] - got %q %s", values, e)
+	}
+
+	_, e = client.Mget("foo", []string{"bar"})
+	if e == nil || !e.IsRedisError() || !strings.Contains(e.Error(), "CROSSSLOT") {
+		t.Errorf("expected CROSSSLOT error - got %v", e)
+	}
+}
+
+func TestClusterMoved(t *testing.T) {
+	cluster := newFakeCluster(t, 3)
+	defer cluster.close()
+
+	client, e := NewClusterSynchClientWithSpec(cluster.nodes[0].spec())
+	if e != nil {
+		t.Fatalf("NewClusterSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	slot := keySlot([]byte("foo")) // of node 2
+	if e := client.Set("foo", []byte("bar")); e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	cluster.move(slot, 0)
+
+	if v, e := client.Get("foo"); e != nil || string(v) != "bar" {
+		t.Fatalf("expected GET bar after MOVED - got %s %s", v, e)
+	}
+	if _, moved := cluster.counts(); moved != 1 {
+		t.Errorf("expected 1 MOVED redirect - got %d", moved)
+	}
+
+	// the slot map is refreshed
+	deadline := time.Now().Add(5 * time.Second)
+	for slotsReqs, _ := cluster.counts(); slotsReqs < 2 && time.Now().Before(deadline); slotsReqs, _ = cluster.counts() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	client.Get("foo")
+	if _, moved := cluster.counts(); moved != 1 {
+		t.Errorf("expected no further redirects - got %d", moved)
+	}
+
+	// plain clients get the redirect
+	plain, e := NewSynchClientWithSpec(cluster.nodes[1].spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer plain.Quit()
+	_, e = plain.Get("foo")
+	redirect, ok := e.(RedirectError)
+	if !ok || redirect.Ask() || redirect.Slot() != slot || redirect.Addr() != cluster.addr(0) {
+		t.Errorf("expected MOVED %d %s - got %v", slot, cluster.addr(0), e)
+	}
+}
+
+func TestClusterAsk(t *testing.T) {
+	cluster := newFakeCluster(t, 2)
+	defer cluster.close()
+
+	client, e := NewClusterAsynchClientWithSpec(cluster.nodes[0].spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewClusterAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	slot := keySlot([]byte("bar")) // of node 0
+	cluster.migrate(slot, 1)
+
+	fset, e := client.Set("bar", []byte("baz"))
+	if e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	if _, e, timedout := fset.TryGet(5 * time.Second); timedout || e != nil {
+		t.Fatalf("Set - error:%s timedout:%t", e, timedout)
+	}
+	if node, _, _ := cluster.lookup("bar"); node != 1 {
+		t.Errorf("expected bar on the migration target - got %d", node)
+	}
+
+	fget, e := client.Get("bar")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	if v, e, timedout := fget.TryGet(5 * time.Second); timedout || e != nil || string(v) != "baz" {
+		t.Errorf("expected GET baz - got %s error:%s timedout:%t", v, e, timedout)
+	}
+
+	// ASK redirects do not update the slot map
+	conn := client.(*asyncClient).conn.(*clusterConn)
+	conn.lock.RLock()
+	addr := conn.slots[slot]
+	conn.lock.RUnlock()
+	if addr != cluster.addr(0) {
+		t.Errorf("expected slot %d of %s - got %s", slot, cluster.addr(0), addr)
+	}
+	if _, moved := cluster.counts(); moved != 0 {
+		t.Errorf("expected no MOVED redirects - got %d", moved)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_cluster(t *testing.T) {
+	log.Println("-- cluster test completed")
+}
@@ -16,9 +16,15 @@ package redis
 
 import (
 	"bufio"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -35,21 +41,32 @@ const (
 	DefaultRespChanSize         = 1000000
 	DefaultTCPReadBuffSize      = 1024 * 256
 	DefaultTCPWriteBuffSize     = 1024 * 256
-	DefaultTCPReadTimeoutNSecs  = 1000 * time.Nanosecond
-	DefaultTCPWriteTimeoutNSecs = 1000 * time.Nanosecond
+	DefaultTCPReadTimeoutNSecs  = 0 // 0: no timeout
+	DefaultTCPWriteTimeoutNSecs = 0 // 0: no timeout
 	DefaultTCPLinger            = 0 // -n: finish io; 0: discard, +n: wait for n secs to finish
 	DefaultTCPKeepalive         = true
 	DefaultHeartbeatSecs        = 1 * time.Second
 	DefaultProtocol             = REDIS_DB
+	DefaultPoolMaxIdle          = 4
+	DefaultPoolMaxActive        = 0 // 0: no limit
+	DefaultPoolIdleTimeout      = 5 * time.Minute
+	DefaultPoolWait             = false
+	DefaultReconnectAttempts    = 0 // 0: no reconnect
+	DefaultReconnectBackoff     = 100 * time.Millisecond
+	DefaultReconnectMaxBackoff  = 5 * time.Second
+	DefaultReplayPolicy         = FAIL_PENDING
+	DefaultRESP3                = false // false: RESP2
 )
 
 // Redis specific default settings
 // exported for user convenience.
 const (
 	DefaultRedisPassword = ""
+	DefaultRedisUsername = "" // "": AUTH with password only (default user)
 	DefaultRedisDB       = 0
 	DefaultRedisPort     = 6379
 	DefaultRedisHost     = "127.0.0.1"
+	DefaultRedisSocket   = "" // "": tcp/ip connection to host:port
 )
 
 // ----------------------------------------------------------------------------
@@ -73,6 +90,28 @@ func (p Protocol) String() string {
 	return "BUG - unknown protocol value"
 }
 
+// ReplayPolicy determines the fate of pending requests of an asynchronous
+// connection that is reconnected after a fault.  See ConnectionSpec.Replay.
+type ReplayPolicy int
+
+const (
+	FAIL_PENDING  ReplayPolicy = iota // fail all pending requests
+	REPLAY_UNSENT                     // replay requests not yet sent; fail requests awaiting response
+	REPLAY_ALL                        // replay all pending requests - non-idempotent commands may be applied twice
+)
+
+func (p ReplayPolicy) String() string {
+	switch p {
+	case FAIL_PENDING:
+		return "ReplayPolicy:FAIL_PENDING"
+	case REPLAY_UNSENT:
+		return "ReplayPolicy:REPLAY_UNSENT"
+	case REPLAY_ALL:
+		return "ReplayPolicy:REPLAY_ALL"
+	}
+	return "BUG - unknown replay policy value"
+}
+
 // Defines the set of parameters that are used by the client connections
 //
 type ConnectionSpec struct {
@@ -82,14 +121,28 @@ type ConnectionSpec struct {
 	db         int           // Redis connection db #
 	rBufSize   int           // tcp read buffer size
 	wBufSize   int           // tcp write buffer size
-	rTimeout   time.Duration // tcp read timeout
-	wTimeout   time.Duration // tcp write timeout
+	rTimeout   time.Duration // tcp read timeout - 0 means no timeout
+	wTimeout   time.Duration // tcp write timeout - 0 means no timeout
 	keepalive  bool          // keepalive flag
 	lingerspec int           // -n: finish io; 0: discard, +n: wait for n secs to finish
 	reqChanCap int           // async request channel capacity - see DefaultReqChanSize
 	rspChanCap int           // async response channel capacity - see DefaultRespChanSize
 	heartbeat  time.Duration // 0 means no heartbeat
 	protocol   Protocol
+	maxIdle    int           // pooled clients: max idle connections retained
+	maxActive  int           // pooled clients: max open connections - 0 means no limit
+	idleTTL    time.Duration // pooled clients: idle connections are closed after this period - 0 means never
+	poolWait   bool          // pooled clients: wait for a connection if pool is exhausted
+	reconnect  int           // async clients: reconnect attempts on connection faults - 0 means no reconnect
+	backoff    time.Duration // async clients: initial delay between reconnect attempts
+	backoffMax time.Duration // async clients: max delay between reconnect attempts
+	replay     ReplayPolicy  // async clients: pending requests on reconnect
+	socket     string        // unix domain socket path - overrides host and port
+	tlsConfig  *tls.Config   // nil means no TLS
+	username   string        // redis (ACL) user - requires password
+	resp3      bool          // negotiate RESP3 with HELLO 3
+
+	sentinel *sentinelResolver // resolves the master - see SentinelSpec.MasterSpec
 }
 
 // Creates a ConnectionSpec using default settings.
@@ -110,6 +163,19 @@ func DefaultSpec() *ConnectionSpec {
 		DefaultRespChanSize,
 		DefaultHeartbeatSecs,
 		DefaultProtocol,
+		DefaultPoolMaxIdle,
+		DefaultPoolMaxActive,
+		DefaultPoolIdleTimeout,
+		DefaultPoolWait,
+		DefaultReconnectAttempts,
+		DefaultReconnectBackoff,
+		DefaultReconnectMaxBackoff,
+		DefaultReplayPolicy,
+		DefaultRedisSocket,
+		nil, // no TLS
+		DefaultRedisUsername,
+		DefaultRESP3,
+		nil, // no sentinel
 	}
 }
 
@@ -134,6 +200,84 @@ func (spec *ConnectionSpec) Port(port int) *ConnectionSpec {
 	return spec
 }
 
+// Sets the unix domain socket path for connection spec and returns the
+// reference.  If set, host and port are not used.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) Socket(path string) *ConnectionSpec {
+	spec.socket = path
+	return spec
+}
+
+// Enables (or disables) TLS for connection spec and returns the reference.
+// Per defaults, the server certificate is verified using the host's root
+// CAs, and the server name is the spec's host.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) TLS(enabled bool) *ConnectionSpec {
+	switch {
+	case !enabled:
+		spec.tlsConfig = nil
+	case spec.tlsConfig == nil:
+		spec.tlsConfig = &tls.Config{}
+	}
+	return spec
+}
+
+// Enables TLS and sets the CAs used to verify the server certificate for
+// connection spec and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) TLSRootCAs(pool *x509.CertPool) *ConnectionSpec {
+	spec.TLS(true).tlsConfig.RootCAs = pool
+	return spec
+}
+
+// Enables TLS and sets the client certificate presented to the server for
+// connection spec and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) TLSClientCert(cert tls.Certificate) *ConnectionSpec {
+	spec.TLS(true).tlsConfig.Certificates = []tls.Certificate{cert}
+	return spec
+}
+
+// Enables TLS and sets the name used to verify the server certificate (and
+// sent per SNI) for connection spec and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) TLSServerName(name string) *ConnectionSpec {
+	spec.TLS(true).tlsConfig.ServerName = name
+	return spec
+}
+
+// Enables TLS and sets whether the server certificate is verified for
+// connection spec and returns the reference.  For tests only - skipping
+// verification allows man-in-the-middle attacks.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) TLSInsecureSkipVerify(skip bool) *ConnectionSpec {
+	spec.TLS(true).tlsConfig.InsecureSkipVerify = skip
+	return spec
+}
+
+// Sets the read timeout for connection spec and returns the reference.
+// A read (of a response) that does not complete in time fails with a
+// SystemError whose Cause() is a timeout net.Error.  0 means no timeout.
+// Not applicable to PubSub message reads.
+// A timeout closes the connection, as the late reply would otherwise be read
+// as the reply of the next request.  Synchronous clients redial on their next
+// request, and asynchronous connections reconnect per the spec's Reconnect.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) ReadTimeout(timeout time.Duration) *ConnectionSpec {
+	spec.rTimeout = timeout
+	return spec
+}
+
+// Sets the write timeout for connection spec and returns the reference.
+// A write (of a request) that does not complete in time fails with a
+// SystemError whose Cause() is a timeout net.Error.  0 means no timeout.
+// As with ReadTimeout, a timeout closes the connection.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) WriteTimeout(timeout time.Duration) *ConnectionSpec {
+	spec.wTimeout = timeout
+	return spec
+}
+
 // Sets the password for connection spec and returns the reference
 // Note that you should not this after you have already connected.
 func (spec *ConnectionSpec) Password(password string) *ConnectionSpec {
@@ -141,6 +285,26 @@ func (spec *ConnectionSpec) Password(password string) *ConnectionSpec {
 	return spec
 }
 
+// Sets the (Redis 6 ACL) username for connection spec and returns the
+// reference.  Connections then authenticate with AUTH username password.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) Username(username string) *ConnectionSpec {
+	spec.username = username
+	return spec
+}
+
+// Sets the RESP3 flag for connection spec and returns the reference.  If
+// set, connections negotiate RESP3 with HELLO 3 (authenticating with HELLO
+// AUTH, as the default user if no username is set), which requires Redis 6
+// or later.  RESP3 replies (maps, sets, doubles, etc.) are decoded into the
+// native values of the typed client methods, and are available as is per
+// Reply for Do and scripts.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) RESP3(enabled bool) *ConnectionSpec {
+	spec.resp3 = enabled
+	return spec
+}
+
 // return the address as string.
 func (spec *ConnectionSpec) Heartbeat(period time.Duration) *ConnectionSpec {
 	spec.heartbeat = period
@@ -153,6 +317,76 @@ func (spec *ConnectionSpec) Protocol(protocol Protocol) *ConnectionSpec {
 	return spec
 }
 
+// Sets the max number of idle connections retained by pooled clients
+// and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) MaxIdle(n int) *ConnectionSpec {
+	spec.maxIdle = n
+	return spec
+}
+
+// Sets the max number of open connections of pooled clients (0 for no limit)
+// and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) MaxActive(n int) *ConnectionSpec {
+	spec.maxActive = n
+	return spec
+}
+
+// Sets the period after which idle connections of pooled clients are closed
+// (0 for never) and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) IdleTimeout(period time.Duration) *ConnectionSpec {
+	spec.idleTTL = period
+	return spec
+}
+
+// Sets whether pooled clients block (true) or fail (false) when MaxActive
+// connections are all in use, and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) WaitOnExhaustion(wait bool) *ConnectionSpec {
+	spec.poolWait = wait
+	return spec
+}
+
+// Sets the number of attempts made by asynchronous (and pubsub) connections
+// to reconnect on connection faults (0 for no reconnect) and returns the
+// reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) Reconnect(attempts int) *ConnectionSpec {
+	spec.reconnect = attempts
+	return spec
+}
+
+// Sets the delay before the first reconnect attempt, doubled on each
+// subsequent attempt up to max, and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) Backoff(initial, max time.Duration) *ConnectionSpec {
+	spec.backoff = initial
+	spec.backoffMax = max
+	return spec
+}
+
+// Sets the policy for pending requests of reconnected asynchronous connections
+// and returns the reference.
+// Note that you should not set this after you have already connected.
+func (spec *ConnectionSpec) Replay(policy ReplayPolicy) *ConnectionSpec {
+	spec.replay = policy
+	return spec
+}
+
+// returns the delay before the n-th (0 based) reconnect attempt.
+func (spec *ConnectionSpec) backoffPeriod(attempt int) time.Duration {
+	period := spec.backoff
+	for i := 0; i < attempt && period < spec.backoffMax; i++ {
+		period *= 2
+	}
+	if period > spec.backoffMax {
+		period = spec.backoffMax
+	}
+	return period
+}
+
 // ----------------------------------------------------------------------------
 // SyncConnection API
 // ----------------------------------------------------------------------------
@@ -162,6 +396,8 @@ func (spec *ConnectionSpec) Protocol(protocol Protocol) *ConnectionSpec {
 
 type SyncConnection interface {
 	ServiceRequest(cmd *Command, args [][]byte) (Response, Error)
+	// Services an arbitrary command - see Client.Do
+	Do(name string, args ...interface{}) (*Reply, Error)
 }
 
 // ----------------------------------------------------------------------------
@@ -173,6 +409,8 @@ type SyncConnection interface {
 
 type AsyncConnection interface {
 	QueueRequest(cmd *Command, args [][]byte) (*PendingResponse, Error)
+	// Queues an arbitrary command - see AsyncClient.Do
+	Do(name string, args ...interface{}) (FutureReply, Error)
 }
 
 // Handle to a future response
@@ -188,8 +426,27 @@ type PendingResponse struct {
 // connections.
 
 type PubSubConnection interface {
-	Subscriptions() map[string]*Subscription
+	Subscriptions() map[SubscriptionKey]*Subscription
 	ServiceRequest(cmd *Command, args [][]byte) (pending map[string]FutureBool, err Error)
+	// As ServiceRequest, with the specified DeliveryMode for (P)SUBSCRIBE.
+	ServiceSubscription(cmd *Command, mode DeliveryMode, args [][]byte) (pending map[string]FutureBool, err Error)
+	// Gap notifications - see Gap.  Closed when the connection is closed.
+	Gaps() <-chan *Gap
+}
+
+// Delivery mode of a subscription's messages.
+type DeliveryMode int
+
+const (
+	PAYLOAD  DeliveryMode = iota // message body on Subscription.Channel
+	ENVELOPE                     // *Message on Subscription.Envelopes
+)
+
+// Identifies a subscription.  Pattern (PSUBSCRIBE) and literal (SUBSCRIBE)
+// subscriptions are distinct, even if Topic is the same.
+type SubscriptionKey struct {
+	Topic   string
+	Pattern bool
 }
 
 // REVU - why is this exported?
@@ -197,10 +454,25 @@ type Subscription struct {
 	activated FutureBool
 	//	closed FutureBool // REVU - for unsubscribe - necessary?
 	//	activated chan bool
-	Channel  chan []byte
-	IsActive bool // REVU - not necessary
+	Channel   chan []byte   // PAYLOAD mode only
+	Envelopes chan *Message // ENVELOPE mode only
+	IsActive  bool          // REVU - not necessary
 }
 
+// Gap notifies PubSub consumers that the connection was re-established (and
+// all subscriptions renewed) after a fault.  Messages published between Start
+// and End may have been missed.
+type Gap struct {
+	Start         time.Time         // time of the fault
+	End           time.Time         // time of resubscribe
+	Cause         error             // the fault
+	Subscriptions []SubscriptionKey // renewed subscriptions
+}
+
+// capacity of the gap notification channel.  Notifications are dropped if
+// the channel is full.
+const gapChanCap = 16
+
 // ----------------------------------------------------------------------------
 // Generic Conn handle and methods - supports SyncConnection interface
 // ----------------------------------------------------------------------------
@@ -211,11 +483,20 @@ type connHdl struct {
 	spec      *ConnectionSpec
 	conn      net.Conn // may want to change this to TCPConn - TODO REVU
 	reader    *bufio.Reader
-	connected bool // TODO
+	connected bool       // TODO
+	faulted   bool       // closed on an io fault - redialed on next request
+	mutex     sync.Mutex // serializes requests - see contextSyncConn
+	stale     int32      // 1 once its master was switched - see sentinelResolver
 }
 
 // Returns minimal info string for logging, etc
 func (c *connHdl) String() string {
+	if c.spec.sentinel != nil && c.conn != nil {
+		return fmt.Sprintf("conn<redis-server@%s [db %d]>", c.conn.RemoteAddr(), c.spec.db)
+	}
+	if c.spec.socket != "" {
+		return fmt.Sprintf("conn<redis-server@%s [db %d]>", c.spec.socket, c.spec.db)
+	}
 	return fmt.Sprintf("conn<redis-server@%s:%d [db %d]>", c.spec.host, c.spec.port, c.spec.db)
 }
 
@@ -234,10 +515,18 @@ func newConnHdl(spec *ConnectionSpec) (hdl *connHdl) {
 	}
 
 	var mode, addr string
-	if spec.port == 0 { // REVU - no special values (it was a contrib) TODO add flag to connspec.
+	switch {
+	case spec.sentinel != nil:
+		host, port := spec.sentinel.resolve() // panics
+		mode = TCP
+		addr = net.JoinHostPort(host, strconv.Itoa(port))
+	case spec.socket != "":
+		mode = UNIX
+		addr = spec.socket
+	case spec.port == 0: // REVU - legacy - use Socket
 		mode = UNIX
 		addr = spec.host
-	} else {
+	default:
 		mode = TCP
 		addr = fmt.Sprintf("%s:%d", spec.host, spec.port)
 		_, e := net.ResolveTCPAddr(TCP, addr)
@@ -254,21 +543,23 @@ func newConnHdl(spec *ConnectionSpec) (hdl *connHdl) {
 		panic(fmt.Errorf("%s(): net.Dial returned nil, nil (?)", loginfo))
 	default:
 		configureConn(conn, spec)
+		if spec.tlsConfig != nil {
+			conn = newTLSConn(conn, spec) // panics
+		}
 		hdl.spec = spec
-		hdl.conn = conn
+		hdl.conn = newTimeoutConn(conn, spec)
 		hdl.connected = true
 		bufsize := 4096
-		hdl.reader = bufio.NewReaderSize(conn, bufsize)
+		hdl.reader = bufio.NewReaderSize(hdl.conn, bufsize)
+		if spec.sentinel != nil {
+			spec.sentinel.register(hdl)
+		}
 	}
 	return
 }
 
+// Read and write timeouts are applied by timeoutConn.
 func configureConn(conn net.Conn, spec *ConnectionSpec) {
-	// REVU - this requires a refact of protocol.go's error propagation
-	// starting from read or write op -- 09-22-2012
-	// TODO 09-23-2012
-	// Deadline can be set in a handful of callsites in connection.go
-	// but need a clean way to test for net.Error (as cause) and timeout
 	if tcp, ok := conn.(*net.TCPConn); ok {
 		tcp.SetLinger(spec.lingerspec)
 		tcp.SetKeepAlive(spec.keepalive)
@@ -277,13 +568,71 @@ func configureConn(conn net.Conn, spec *ConnectionSpec) {
 	}
 }
 
+// Wraps the connection in a TLS client connection per spec and performs the
+// handshake.  The server name is the spec's host, unless set.
+// panics on error (with error)
+func newTLSConn(conn net.Conn, spec *ConnectionSpec) net.Conn {
+	config := spec.tlsConfig.Clone()
+	if config.ServerName == "" && spec.socket == "" {
+		config.ServerName = spec.host
+	}
+	tlsConn := tls.Client(conn, config)
+	if e := tlsConn.Handshake(); e != nil {
+		conn.Close()
+		panic(newSystemErrorWithCause("newConnHdl(): TLS handshake failed", e))
+	}
+	return tlsConn
+}
+
+// timeoutConn sets the read (write) deadline of the net.Conn before each
+// read (write) per the spec's rTimeout (wTimeout).  Timeouts surface as
+// net.Error causes of the SystemErrors raised by protocol.go.
+//
+// PubSub connections idle until messages are published, and manage their
+// read deadline directly - see msgProcessingTask.
+type timeoutConn struct {
+	net.Conn
+	rTimeout time.Duration
+	wTimeout time.Duration
+}
+
+func newTimeoutConn(conn net.Conn, spec *ConnectionSpec) net.Conn {
+	rTimeout := spec.rTimeout
+	if spec.protocol == REDIS_PUBSUB {
+		rTimeout = 0
+	}
+	if rTimeout <= 0 && spec.wTimeout <= 0 {
+		return conn
+	}
+	return &timeoutConn{conn, rTimeout, spec.wTimeout}
+}
+
+func (c *timeoutConn) Read(b []byte) (int, error) {
+	if c.rTimeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.rTimeout))
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *timeoutConn) Write(b []byte) (int, error) {
+	if c.wTimeout > 0 {
+		c.Conn.SetWriteDeadline(time.Now().Add(c.wTimeout))
+	}
+	return c.Conn.Write(b)
+}
+
 // connect event handler will issue AUTH/SELECT on new connection
 // if required.
 // panics on error (with error)
 func (c *connHdl) connect() {
-	if c.spec.password != DefaultRedisPassword {
-		args := [][]byte{[]byte(c.spec.password)}
-		if _, e := c.ServiceRequest(&AUTH, args); e != nil {
+	if c.spec.resp3 {
+		c.hello()
+	} else if c.spec.password != DefaultRedisPassword {
+		cmd, args := &AUTH, [][]byte{[]byte(c.spec.password)}
+		if c.spec.username != DefaultRedisUsername {
+			cmd, args = &AUTH_USER, [][]byte{[]byte(c.spec.username), []byte(c.spec.password)}
+		}
+		if _, e := c.ServiceRequest(cmd, args); e != nil {
 			panic(e)
 			//			panic(fmt.Errorf("<ERROR> Authentication failed - %s", e.Message()))
 		}
@@ -295,16 +644,60 @@ func (c *connHdl) connect() {
 			//			panic(fmt.Errorf("<ERROR> REDIS_DB Select failed - %s", e.Message()))
 		}
 	}
+	if c.spec.sentinel != nil {
+		c.verifyMaster()
+	}
 	// REVU - pretty please TODO do the customized log
 	//	log.Printf("<INFO> %s - CONNECTED", c)
 	return
 }
 
+// negotiates RESP3, and authenticates per spec, with HELLO 3 [AUTH ..]
+// panics on error (with error)
+func (c *connHdl) hello() {
+	args := [][]byte{[]byte("3")}
+	if c.spec.password != DefaultRedisPassword {
+		username := c.spec.username
+		if username == DefaultRedisUsername {
+			username = "default"
+		}
+		args = append(args, []byte("AUTH"), []byte(username), []byte(c.spec.password))
+	}
+	if _, e := c.ServiceRequest(&HELLO, args); e != nil {
+		panic(e)
+	}
+}
+
+// verifies that the (sentinel resolved) server is a master, per ROLE.
+// panics on error (with error)
+func (c *connHdl) verifyMaster() {
+	resp, e := c.ServiceRequest(&ROLE, [][]byte{})
+	if e != nil {
+		panic(e)
+	}
+	reply := resp.GetReply()
+	if reply.Type != REPLY_ARRAY || len(reply.Array) == 0 || string(reply.Array[0].Bulk) != "master" {
+		panic(newSystemErrorf("%s is not a master - ROLE %s", c, reply))
+	}
+}
+
+// Returns the error for the recovered panic of a failed connect.  Redis
+// errors, e.g. a WrongPassError on AUTH, are returned as is.
+func connectError(e interface{}, info string) Error {
+	if re, ok := e.(Error); ok && re.IsRedisError() {
+		return re
+	}
+	return newSystemErrorWithCause(info, e.(error))
+}
+
 // disconnects from net connections and sets connected state to false
 // panics on net error (with error)
 func (hdl *connHdl) disconnect() {
 	// silently ignore repeated calls to closed connections
 	if hdl.connected {
+		if hdl.spec.sentinel != nil {
+			hdl.spec.sentinel.unregister(hdl)
+		}
 		if e := hdl.conn.Close(); e != nil {
 			panic(fmt.Errorf("on connHdl.Close()", e))
 			//			return newSystemErrorWithCause( "on connHdl.Close()", e)
@@ -318,14 +711,17 @@ func (hdl *connHdl) disconnect() {
 // Creates a new SyncConnection using the provided ConnectionSpec.
 // Note that this function will also connect to the specified redis server.
 func NewSyncConnection(spec *ConnectionSpec) (c SyncConnection, err Error) {
+	var connHdl *connHdl
 	defer func() {
 		if e := recover(); e != nil {
-			connerr := e.(error)
-			err = newSystemErrorWithCause("NewSyncConnection", connerr)
+			if connHdl != nil {
+				closeConnHdl(connHdl)
+			}
+			err = connectError(e, "NewSyncConnection")
 		}
 	}()
 
-	connHdl := newConnHdl(spec)
+	connHdl = newConnHdl(spec)
 	connHdl.connect()
 	c = connHdl
 	return
@@ -333,15 +729,71 @@ func NewSyncConnection(spec *ConnectionSpec) (c SyncConnection, err Error) {
 
 // Implementation of SyncConnection.ServiceRequest.
 func (c *connHdl) ServiceRequest(cmd *Command, args [][]byte) (resp Response, err Error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.serviceRequest(cmd, args)
+}
+
+// Services the request of a blocking command (e.g. XREAD BLOCK) that may
+// wait up to block for its reply, by extending the read timeout (if any)
+// of the connection by block, or lifting it if block is 0 (i.e. blocks
+// indefinitely) for the request.
+func (c *connHdl) serviceBlockingRequest(cmd *Command, args [][]byte, block time.Duration) (resp Response, err Error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	defer func() {
+		if re := recover(); re != nil {
+			err = newSystemErrorWithCause("serviceBlockingRequest", rootCause(re.(error)))
+			c.fault()
+		}
+	}()
+
+	// redial first (see serviceRequest), so that the timeout of the new
+	// connection is the one adjusted.
+	if c.connected && c.isStale() {
+		c.fault() // the master was switched - see sentinelResolver
+	}
+	if c.faulted {
+		c.redial() // panics
+	}
+
+	if tc, ok := c.conn.(*timeoutConn); ok && tc.rTimeout > 0 {
+		defer func(rTimeout time.Duration) { tc.rTimeout = rTimeout }(tc.rTimeout)
+		if block == 0 {
+			tc.rTimeout = 0
+			tc.Conn.SetReadDeadline(time.Time{})
+		} else {
+			tc.rTimeout += block
+		}
+	}
+	return c.serviceRequest(cmd, args)
+}
+
+// services the request - the caller holds the mutex.
+func (c *connHdl) serviceRequest(cmd *Command, args [][]byte) (resp Response, err Error) {
 	loginfo := "connHdl.ServiceRequest"
 
 	defer func() {
 		if re := recover(); re != nil {
 			// REVU - needs to be logged - TODO
-			err = newSystemErrorWithCause("ServiceRequest", re.(error))
+			err = newSystemErrorWithCause("ServiceRequest", rootCause(re.(error)))
+			c.fault()
 		}
 	}()
 
+	if c.connected && c.isStale() {
+		c.fault() // the master was switched - see sentinelResolver
+	}
+	if c.faulted {
+		if cmd == &QUIT {
+			c.faulted = false
+			return
+		}
+		c.redial() // panics
+	}
+
 	if !c.connected {
 		panic(fmt.Errorf("Connection %s is alredy closed", c.String()))
 	}
@@ -358,18 +810,71 @@ func (c *connHdl) ServiceRequest(cmd *Command, args [][]byte) (resp Response, er
 	// TODO - look into this
 	resp, e := GetResponse(c.reader, cmd)
 	if e != nil {
-		panic(fmt.Errorf("%s(%s) - failed to get response", loginfo, cmd.Code))
+		panic(newSystemErrorWithCause(fmt.Sprintf("%s(%s) - failed to get response", loginfo, cmd.Code), e))
 	}
 
 	// handle Redis server ERR - don't panic
 	if resp.IsError() {
 		redismsg := fmt.Sprintf(" [%s]: %s", cmd.Code, resp.GetMessage())
-		err = newRedisError(redismsg)
+		err = newRedisReplyError(resp.GetMessage(), redismsg)
 	}
 
 	return
 }
 
+// Disconnects the connection after an io fault (e.g. a timeout), as the
+// reply of the faulted request may yet arrive and would be read as the
+// reply of the next request.  The next request redials, and pools evict
+// the connection (per its SystemError).
+func (c *connHdl) fault() {
+	closeConnHdl(c)
+	c.faulted = true
+}
+
+// Replaces the net connection of the faulted connHdl with a new one.
+// panics on error (with error)
+func (c *connHdl) redial() {
+	hdl, e := openConnHdl(c.spec)
+	if e != nil {
+		panic(e)
+	}
+	c.conn, c.reader, c.connected = hdl.conn, hdl.reader, true
+	c.faulted = false
+	if c.spec.sentinel != nil {
+		// c (and not hdl) is the registered connection to the new master
+		atomic.StoreInt32(&c.stale, 0)
+		c.spec.sentinel.register(c)
+		c.spec.sentinel.unregister(hdl)
+	}
+}
+
+// Sends the MULTI ... EXEC sequence for the requests and returns the per
+// request responses.  See getTransactionResponse.
+func (c *connHdl) serviceTransaction(reqs []asyncReqPtr) (resps []Response, err Error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	defer func() {
+		if re := recover(); re != nil {
+			err = newSystemErrorWithCause("serviceTransaction", rootCause(re.(error)))
+			c.fault()
+		}
+	}()
+
+	if c.connected && c.isStale() {
+		c.fault() // the master was switched - see sentinelResolver
+	}
+	if c.faulted {
+		c.redial() // panics
+	}
+	if !c.connected {
+		panic(fmt.Errorf("Connection %s is alredy closed", c.String()))
+	}
+
+	sendRequest(c.conn, createTransactionBytes(reqs)) // panics
+	return getTransactionResponse(c.reader, transactionCommands(reqs))
+}
+
 // ----------------------------------------------------------------------------
 // Asynchronous connection handle and friends
 // ----------------------------------------------------------------------------
@@ -434,6 +939,7 @@ type asyncRequestInfo struct {
 	outbuff *[]byte
 	future  interface{}
 	error   Error
+	txreqs  []asyncReqPtr // queued requests of a MULTI ... EXEC request
 }
 type asyncReqPtr *asyncRequestInfo
 
@@ -448,6 +954,7 @@ const (
 type asyncConnHdl struct {
 	super  *connHdl
 	writer *bufio.Writer
+	wire   *countingWriter // under writer
 
 	nextid int64
 
@@ -455,7 +962,12 @@ type asyncConnHdl struct {
 	pendingResps chan asyncReqPtr
 	faults       chan asyncReqPtr
 
-	subscriptions map[string]*Subscription // REDIS_PUBSUB only
+	subscriptions map[SubscriptionKey]*Subscription // REDIS_PUBSUB only
+	subsLock      sync.Mutex
+	drained       chan bool // closed once all subscriptions are removed
+	gaps          chan *Gap // REDIS_PUBSUB only
+
+	dedicated *connPool // connections of transactions and blocking requests
 
 	managerCtl   workerCtl
 	reqProcCtl   workerCtl
@@ -463,9 +975,11 @@ type asyncConnHdl struct {
 	heartbeatCtl workerCtl // REDIS_DB only
 
 	feedback chan workerStatus
+	quit     chan bool // requests shutdown - see disconnect
 
-	shutdown   chan bool
-	isShutdown bool
+	shutdown     chan bool    // closed on shutdown
+	shutdownLock sync.RWMutex // held (read) while queuing requests
+	closed       chan bool    // closed once shutdown is complete
 }
 
 func (c *asyncConnHdl) String() string {
@@ -487,14 +1001,17 @@ func newAsyncConnHdl(spec *ConnectionSpec) *asyncConnHdl {
 	// connection base
 	connHdl := newConnHdl(spec) // panics
 	c.super = connHdl
+	c.dedicated = newConnPool(spec)
 
 	// conn management
 	c.managerCtl = make(workerCtl)
 	c.feedback = make(chan workerStatus)
-	c.shutdown = make(chan bool, 1)
+	c.quit = make(chan bool)
+	c.shutdown = make(chan bool)
+	c.closed = make(chan bool)
 
 	// request processing
-	c.writer = bufio.NewWriterSize(connHdl.conn, spec.wBufSize)
+	c.setWriter(connHdl.conn)
 	c.reqProcCtl = make(workerCtl)
 	c.pendingReqs = make(chan asyncReqPtr, spec.reqChanCap) // REVU for PubSub
 
@@ -509,12 +1026,10 @@ func newAsyncConnHdl(spec *ConnectionSpec) *asyncConnHdl {
 		c.heartbeatCtl = make(workerCtl)
 		c.pendingResps = make(chan asyncReqPtr, spec.rspChanCap)
 	case REDIS_PUBSUB:
-		c.subscriptions = make(map[string]*Subscription)
+		c.subscriptions = make(map[SubscriptionKey]*Subscription)
+		c.gaps = make(chan *Gap, gapChanCap)
 	}
 
-	// REVU - this is state - TODO move to startup
-	c.isShutdown = false
-
 	return c
 }
 
@@ -522,15 +1037,17 @@ func newAsyncConnHdl(spec *ConnectionSpec) *asyncConnHdl {
 // request and response processing
 // interaction with redis (AUTH &| SELECT)
 func NewAsynchConnection(spec *ConnectionSpec) (conn AsyncConnection, err Error) {
+	var async *asyncConnHdl
 	defer func() {
 		if e := recover(); e != nil {
-			connerr := e.(error)
-			err = newSystemErrorWithCause("NewSyncConnection", connerr)
+			if async != nil {
+				closeConnHdl(async.super)
+			}
+			err = connectError(e, "NewAsynchConnection")
 		}
 	}()
 
-	//	var async *asyncConnHdl
-	async := newAsyncConnHdl(spec)
+	async = newAsyncConnHdl(spec)
 	async.connect()
 	async.startup()
 
@@ -539,17 +1056,29 @@ func NewAsynchConnection(spec *ConnectionSpec) (conn AsyncConnection, err Error)
 }
 
 func NewPubSubConnection(spec *ConnectionSpec) (conn PubSubConnection, err Error) {
+	return newPubSubConnection(spec, nil)
+}
+
+// As NewPubSubConnection.  init, if not nil, is called with the connection
+// once connected, and before it is in pubsub mode, e.g. to get its CLIENT
+// ID.
+func newPubSubConnection(spec *ConnectionSpec, init func(hdl *connHdl)) (conn *asyncConnHdl, err Error) {
+	var async *asyncConnHdl
 	defer func() {
 		if e := recover(); e != nil {
-			connerr := e.(error)
-			err = newSystemErrorWithCause("NewSyncConnection", connerr)
+			if async != nil {
+				closeConnHdl(async.super)
+			}
+			conn, err = nil, connectError(e, "NewPubSubConnection")
 		}
 	}()
 
-	//	var async *asyncConnHdl
 	spec.Protocol(REDIS_PUBSUB) // must be so set it regardless
-	async := newAsyncConnHdl(spec)
+	async = newAsyncConnHdl(spec)
 	async.connect()
+	if init != nil {
+		init(async.super)
+	}
 	async.startup()
 
 	conn = async
@@ -563,100 +1092,229 @@ func NewPubSubConnection(spec *ConnectionSpec) (conn PubSubConnection, err Error
 func (c *asyncConnHdl) QueueRequest(cmd *Command, args [][]byte) (pending *PendingResponse, err Error) {
 
 	defer func() {
-		if re := recover(); re != nil {
-			// REVU - needs to be logged - TODO
-			err = newSystemErrorWithCause("QueueRequest", re.(error))
-		}
+		// REVU - needs to be logged - TODO
+		err = onRecover(recover(), "QueueRequest")
 	}()
 
-	if c.isShutdown {
-		panic(fmt.Errorf("Connection %s is alredy shutdown", c.String()))
-	}
-	select {
-	case <-c.shutdown:
-		c.isShutdown = true
-		c.shutdown <- true // put it back REVU likely to be a bug under heavy load ..
-		panic(fmt.Errorf("Connection %s is alredy shutdown", c.String()))
-	default:
-	}
-
 	buff := CreateRequestBytes(cmd, args) // panics
 	future := CreateFuture(cmd)
-	request := &asyncRequestInfo{0, 0, cmd, &buff, future, nil}
+	request := &asyncRequestInfo{0, 0, cmd, &buff, future, nil, nil}
 
-	c.pendingReqs <- request
+	c.queue(request) // panics
 
 	pending = &PendingResponse{future}
 
 	return
 }
 
+// Queues the MULTI ... EXEC sequence for the (transaction's) requests as a
+// single request, so that the sequence is sent as a contiguous block on the
+// pipeline.  The futures of the requests are set on processing the response
+// to EXEC.  The returned future is set on successful EXEC.
+func (c *asyncConnHdl) queueTransaction(reqs []asyncReqPtr) (status FutureBool, err Error) {
+
+	defer func() {
+		err = onRecover(recover(), "queueTransaction")
+	}()
+
+	buff := createTransactionBytes(reqs)
+	status = newFutureBool()
+	request := &asyncRequestInfo{0, 0, &EXEC, &buff, status, nil, reqs}
+
+	c.queue(request) // panics
+
+	return
+}
+
+// Queues the request for the request processor.
+// panics if connection is shutdown (with ConnectionClosedError)
+func (c *asyncConnHdl) queue(req asyncReqPtr) {
+	c.shutdownLock.RLock()
+	defer c.shutdownLock.RUnlock()
+
+	c.assertNotShutdown() // panics
+	select {
+	case c.pendingReqs <- req:
+	case <-c.shutdown:
+		panic(newConnectionClosedError(nil))
+	}
+}
+
+// panics if connection is shutdown (with ConnectionClosedError)
+func (c *asyncConnHdl) assertNotShutdown() {
+	select {
+	case <-c.shutdown:
+		panic(newConnectionClosedError(nil))
+	default:
+	}
+}
+
 // ----------------------------------------------------------------------------
 // asyncConnHdl support for PubSubConnection interface
 // ----------------------------------------------------------------------------
 
-// TODO - HERE
 // PubSubConnection support (only)
-// Accepts Redis commands (P)SUBSCRIBE and (P)UNSUBSCRIBE.
+// Accepts Redis commands (P)SUBSCRIBE and (P)UNSUBSCRIBE, and QUIT.
 // Request is processed asynchronously but call semantics are sync/blocking.
+//
+// For (P)SUBSCRIBE the returned pending map has the activation future of
+// each topic.  Subscriptions are removed (and their channel closed) on
+// receipt of the (P)UNSUBSCRIBE ack of Redis.
+//
+// QUIT unsubscribes from all channels and patterns and closes the
+// connection.  (QUIT itself is not sent.)
 func (c *asyncConnHdl) ServiceRequest(cmd *Command, args [][]byte) (pending map[string]FutureBool, err Error) {
-	//func (c *asyncConnHdl) ServiceRequest(cmd *Command, args [][]byte) (ok bool, err Error) {
+	return c.ServiceSubscription(cmd, PAYLOAD, args)
+}
+
+// PubSubConnection support (only)
+// See ServiceRequest.  mode determines the message delivery of new
+// subscriptions.
+func (c *asyncConnHdl) ServiceSubscription(cmd *Command, mode DeliveryMode, args [][]byte) (pending map[string]FutureBool, err Error) {
 
 	defer func() {
-		if re := recover(); re != nil {
-			// REVU - needs to be logged - TODO
-			err = newSystemErrorWithCause("QueueRequest", re.(error))
-		}
+		// REVU - needs to be logged - TODO
+		err = onRecover(recover(), "ServiceRequest")
 	}()
+	var subscribe, pattern bool
 	switch *cmd {
-	case SUBSCRIBE, UNSUBSCRIBE: /* nop - ok */
+	case SUBSCRIBE:
+		subscribe = true
+	case PSUBSCRIBE:
+		subscribe, pattern = true, true
+	case UNSUBSCRIBE: /* nop - ok */
+	case PUNSUBSCRIBE:
+		pattern = true
+	case QUIT:
+		c.unsubscribeAll()
+		c.disconnect()
+		return
 	default:
 		panic(fmt.Errorf("BUG - command %s is not applicable to PubSub", cmd))
 	}
 
-	if c.isShutdown {
-		panic(fmt.Errorf("Connection %s is alredy shutdown", c.String()))
-	}
-	select {
-	case <-c.shutdown:
-		c.isShutdown = true
-		c.shutdown <- true // put it back REVU likely to be a bug under heavy load ..
-		panic(fmt.Errorf("Connection %s is alredy shutdown", c.String()))
-	default:
-	}
+	c.assertNotShutdown() // panics
 
 	// REVU - issue with this pattern is that request side errors
 	// REVU   are not captured.
 	pending = make(map[string]FutureBool)
 
 	buff := CreateRequestBytes(cmd, args) // panics
-	for _, arg := range args {
-		topic := string(arg)
-		if s := c.subscriptions[topic]; s != nil {
-			panic(fmt.Errorf("already subscribed to topic %s", topic))
+	if subscribe {
+		keys := c.addSubscriptions(pending, args, pattern, mode) // panics
+		defer func() {
+			if re := recover(); re != nil {
+				c.removeSubscriptions(keys)
+				panic(re)
+			}
+		}()
+	}
+
+	// REVU - errors on request side are conveyed via the future in request
+	// REVU - issue is how t
+	//	future := CreateFuture(cmd)
+	request := &asyncRequestInfo{0, 0, cmd, &buff, nil, nil, nil}
+	c.queue(request) // panics
+
+	return
+}
+
+// PubSubConnection support (only)
+func (c *asyncConnHdl) Gaps() <-chan *Gap {
+	return c.gaps
+}
+
+// Adds (inactive) subscriptions for the topics and their activation futures
+// to pending.
+// panics if already subscribed to any of the topics
+func (c *asyncConnHdl) addSubscriptions(pending map[string]FutureBool, args [][]byte, pattern bool, mode DeliveryMode) []SubscriptionKey {
+	c.subsLock.Lock()
+	defer c.subsLock.Unlock()
+
+	keys := make([]SubscriptionKey, len(args))
+	for i, arg := range args {
+		keys[i] = SubscriptionKey{string(arg), pattern}
+		if s := c.subscriptions[keys[i]]; s != nil {
+			panic(fmt.Errorf("already subscribed to topic %s", keys[i].Topic))
 		}
+	}
+	for _, key := range keys {
 		pendingActivation := newFutureBool()
-		pending[topic] = pendingActivation
+		pending[key.Topic] = pendingActivation
 		subscription := &Subscription{
 			IsActive:  false,
 			activated: pendingActivation,
-			Channel:   make(chan []byte, 100), // TODO - from spec
 		}
-		c.subscriptions[topic] = subscription
+		switch mode {
+		case ENVELOPE:
+			subscription.Envelopes = make(chan *Message, 100) // TODO - from spec
+		default:
+			subscription.Channel = make(chan []byte, 100) // TODO - from spec
+		}
+		c.subscriptions[key] = subscription
 	}
+	return keys
+}
 
-	// REVU - errors on request side are conveyed via the future in request
-	// REVU - issue is how t
-	//	future := CreateFuture(cmd)
-	//	request := &asyncRequestInfo{0, 0, cmd, &buff, future, nil}
-	request := &asyncRequestInfo{0, 0, cmd, &buff, nil, nil}
-	c.pendingReqs <- request
+// Removes subscriptions for which the SUBSCRIBE request could not be queued.
+func (c *asyncConnHdl) removeSubscriptions(keys []SubscriptionKey) {
+	c.subsLock.Lock()
+	defer c.subsLock.Unlock()
 
-	return
+	for _, key := range keys {
+		delete(c.subscriptions, key)
+	}
 }
 
-func (c *asyncConnHdl) Subscriptions() map[string]*Subscription {
-	return c.subscriptions
+// bound on the wait for the unsubscribe acks on QUIT
+const unsubscribeAckTimeout = 1 * time.Second
+
+// Unsubscribes from all channels and patterns, and waits (a bit) for the
+// acks of Redis, which close the subscription channels.
+func (c *asyncConnHdl) unsubscribeAll() {
+	c.subsLock.Lock()
+	var literal, pattern bool
+	for key := range c.subscriptions {
+		literal = literal || !key.Pattern
+		pattern = pattern || key.Pattern
+	}
+	if !literal && !pattern {
+		c.subsLock.Unlock()
+		return
+	}
+	drained := make(chan bool)
+	c.drained = drained
+	c.subsLock.Unlock()
+
+	defer func() {
+		if re := recover(); re != nil && debug() {
+			log.Println("unsubscribeAll - ignoring error: ", re)
+		}
+	}()
+	for _, cmd := range []*Command{&UNSUBSCRIBE, &PUNSUBSCRIBE} {
+		if (cmd == &UNSUBSCRIBE && literal) || (cmd == &PUNSUBSCRIBE && pattern) {
+			buff := CreateRequestBytes(cmd, [][]byte{})
+			c.queue(&asyncRequestInfo{0, 0, cmd, &buff, nil, nil, nil}) // panics
+		}
+	}
+	select {
+	case <-drained:
+	case <-c.closed:
+	case <-time.After(unsubscribeAckTimeout):
+	}
+}
+
+// Returns a snapshot of the subscriptions.
+func (c *asyncConnHdl) Subscriptions() map[SubscriptionKey]*Subscription {
+	c.subsLock.Lock()
+	defer c.subsLock.Unlock()
+
+	subscriptions := make(map[SubscriptionKey]*Subscription, len(c.subscriptions))
+	for key, s := range c.subscriptions {
+		snapshot := *s
+		subscriptions[key] = &snapshot
+	}
+	return subscriptions
 }
 
 // ----------------------------------------------------------------------------
@@ -669,12 +1327,14 @@ func (c *asyncConnHdl) connect() {
 	c.super.connect()
 }
 
-// REVU - TODO opt 2 for Quit here
-// panics
+// Requests the manager to shutdown the connection, and blocks until the
+// connection is closed.  See asyncConnHdl#close
 func (c *asyncConnHdl) disconnect() {
-
-	panic("asyncConnHdl.disconnect NOT IMLEMENTED!")
-	//	return
+	select {
+	case c.quit <- true:
+	case <-c.closed:
+	}
+	<-c.closed
 }
 
 // responsible for managing the various moving parts of the asyncConnHdl
@@ -701,7 +1361,6 @@ func (c *asyncConnHdl) startup() {
 	case REDIS_PUBSUB:
 		rspProcTask = msgProcessingTask
 		//		cmd := SUBSCRIBE
-		//		c.pendingResps <- &asyncRequestInfo{0, 0, &cmd, nil, nil, nil}
 	}
 	go c.worker(responsehandler, "response-processor", rspProcTask, c.rspProcCtl, c.feedback)
 	c.rspProcCtl <- start
@@ -776,38 +1435,32 @@ before_stop:
 // ----------------------------------------------------------------------------
 
 func managementTask(c *asyncConnHdl, ctl workerCtl) (sig *interrupt_code, te *taskStatus) {
-	/* TODO:
-	connected:
-		clearChannels();
-		startWokers();
-	disconnected:
-		?
-	on_fault:
-		disconnect();
-		goto disconnected;
-	on_exit:
-		?
-	*/
 	//	log.Println("MGR: do task ...")
 	select {
 	case stat := <-c.feedback:
-		// do the shutdown for now -- TODO: try reconnect
-		if stat.event == faulted || stat.event == quit_processed {
-			if stat.event == faulted {
-				// REVU - pretty please TODO do the customized log
-				log.Printf("<INFO> - %s (manager task) FAULT EVENT ", c)
+		switch stat.event {
+		case faulted:
+			// REVU - pretty please TODO do the customized log
+			log.Printf("<INFO> - %s (manager task) FAULT EVENT ", c)
+			var cause error
+			if stat.taskinfo != nil {
+				cause = rootCause(stat.taskinfo.error)
 			}
+			if closed := c.onFault(cause); closed {
+				stopsig := stop
+				return &stopsig, &ok_status
+			}
+		case quit_processed:
 			// REVU - pretty please TODO do the customized log
 			//			log.Printf("<INFO> %s - (manager task) SHUTTING DOWN ...", c)
-			c.shutdown <- true
-
-			// REVU - pretty please TODO do the customized log
-			//			log.Printf("<INFO> %s - (manager task) RAISING SIGNAL STOP ...", c)
-			go func() { c.reqProcCtl <- stop }()
-			go func() { c.rspProcCtl <- stop }()
-			go func() { c.heartbeatCtl <- stop }()
-			go func() { c.managerCtl <- stop }()
+			c.close(nil)
+			stopsig := stop
+			return &stopsig, &ok_status
 		}
+	case <-c.quit:
+		c.close(nil)
+		stopsig := stop
+		return &stopsig, &ok_status
 	case s := <-ctl:
 		return &s, &ok_status
 	}
@@ -875,8 +1528,14 @@ func dbRspProcessingTask(c *asyncConnHdl, ctl workerCtl) (sig *interrupt_code, t
 	reader := c.super.reader
 	cmd := req.cmd
 
+	if req.txreqs != nil {
+		return c.processTransactionResponse(req)
+	}
+
 	resp, e3 := GetResponse(reader, cmd) // REVU - protocol modified to handle VIRTUALS
-	if e3 != nil {
+	if e3 == nil {
+		req.outbuff = nil // retained until now for replay on reconnect
+	} else {
 		// system error
 		log.Println("<TEMP DEBUG> Request sent to faults chan on error in GetResponse: ", e3)
 		req.stat = rcverr
@@ -888,10 +1547,9 @@ func dbRspProcessingTask(c *asyncConnHdl, ctl workerCtl) (sig *interrupt_code, t
 	// if responsed processed was for cmd QUIT then signal the rest of the crew
 	// REVU - ok, a bit hacky but it works.
 	if cmd == &QUIT {
+		SetFutureResult(req.future, cmd, resp)
 		c.feedback <- workerStatus{0, quit_processed, nil, nil}
 		fakesig := pause
-		c.isShutdown = true
-		SetFutureResult(req.future, cmd, resp)
 		return &fakesig, &ok_status
 	}
 
@@ -899,6 +1557,21 @@ func dbRspProcessingTask(c *asyncConnHdl, ctl workerCtl) (sig *interrupt_code, t
 	return nil, &ok_status
 }
 
+// processes the response to a MULTI ... EXEC request.
+// See dbRspProcessingTask
+func (c *asyncConnHdl) processTransactionResponse(req asyncReqPtr) (sig *interrupt_code, te *taskStatus) {
+	resps, e := getTransactionResponse(c.super.reader, transactionCommands(req.txreqs))
+	if e != nil && !e.IsRedisError() {
+		req.stat = rcverr
+		req.error = e
+		c.faults <- req
+		return nil, &taskStatus{rcverr, e}
+	}
+	req.outbuff = nil
+	setTransactionResult(req.future.(FutureBool), req.txreqs, resps, e)
+	return nil, &ok_status
+}
+
 // Task:
 // process one incoming Redis PubSub message at a time
 // - can be interrupted while waiting on the net read
@@ -935,20 +1608,72 @@ func msgProcessingTask(c *asyncConnHdl, ctl workerCtl) (sig *interrupt_code, te
 	if message == nil {
 		panic(newSystemError("BUG - msgProcessingTask - message is nil on nil error"))
 	}
-	s := c.subscriptions[message.Topic]
+
+	// pattern messages and acks are keyed by pattern, and not the channel
+	key := SubscriptionKey{message.Topic, false}
+	switch message.Type {
+	case PSUBSCRIBE_ACK, PUNSUBSCRIBE_ACK:
+		key.Pattern = true
+	case PMESSAGE:
+		key = SubscriptionKey{message.Pattern, true}
+	}
+
+	c.subsLock.Lock()
+	s := c.subscriptions[key]
+	activated := false
 	switch message.Type {
-	case SUBSCRIBE_ACK:
-		s.activated.set(true)
-		s.IsActive = true
-	case UNSUBSCRIBE_ACK:
-		s.IsActive = false
-		close(s.Channel)
-	case MESSAGE:
-		s.Channel <- message.Body
+	case SUBSCRIBE_ACK, PSUBSCRIBE_ACK:
+		// renewed subscriptions (on reconnect) are already active
+		if s != nil && !s.IsActive {
+			s.IsActive = true
+			activated = true
+		}
+	case UNSUBSCRIBE_ACK, PUNSUBSCRIBE_ACK:
+		delete(c.subscriptions, key)
+		if len(c.subscriptions) == 0 && c.drained != nil {
+			close(c.drained)
+			c.drained = nil
+		}
+	case MESSAGE, PMESSAGE:
 	default:
+		c.subsLock.Unlock()
 		e := newSystemErrorf("BUG - TODO - unhandled message type - %s", message.Type)
 		return nil, &taskStatus{rcverr, e}
 	}
+	c.subsLock.Unlock()
+
+	// ack of unknown subscriptions (e.g. unsubscribe of non-subscribed
+	// topics) and messages received after unsubscribe are ignored.
+	if s == nil {
+		return nil, &ok_status
+	}
+	switch message.Type {
+	case SUBSCRIBE_ACK, PSUBSCRIBE_ACK:
+		if activated {
+			s.activated.set(true)
+		}
+	case UNSUBSCRIBE_ACK, PUNSUBSCRIBE_ACK:
+		if s.Envelopes != nil {
+			close(s.Envelopes)
+		} else {
+			close(s.Channel)
+		}
+	case MESSAGE, PMESSAGE:
+		// delivery blocks on slow consumers but can be interrupted
+		if s.Envelopes != nil {
+			select {
+			case s.Envelopes <- message:
+			case sig := <-ctl:
+				return &sig, &ok_status
+			}
+		} else {
+			select {
+			case s.Channel <- message.Body:
+			case sig := <-ctl:
+				return &sig, &ok_status
+			}
+		}
+	}
 	return nil, &ok_status
 }
 
@@ -967,7 +1692,7 @@ func reqProcessingTask(c *asyncConnHdl, ctl workerCtl) (ic *interrupt_code, ts *
 
 	select {
 	case req := <-c.pendingReqs:
-		blen, err := c.processAsyncRequest(req)
+		blen, err = c.processAsyncRequest(req)
 		if err != nil {
 			errmsg = fmt.Sprintf("processAsyncRequest error in initial phase")
 			goto proc_error
@@ -1001,7 +1726,10 @@ func reqProcessingTask(c *asyncConnHdl, ctl workerCtl) (ic *interrupt_code, ts *
 	}
 
 done:
-	c.writer.Flush()
+	if err = c.writer.Flush(); err != nil {
+		errmsg = fmt.Sprintf("flush error")
+		goto proc_error
+	}
 	return ic, &ok_status
 
 proc_error:
@@ -1019,21 +1747,35 @@ func (c *asyncConnHdl) processAsyncRequest(req asyncReqPtr) (blen int, e error)
 	req.id = c.nextId()
 	blen = len(*req.outbuff)
 
+	// the request's bytes start at offset on the wire
+	offset := c.wire.n + int64(c.writer.Buffered())
+
 	defer func() {
 		if re := recover(); re != nil {
 			e = re.(error)
-			log.Println("<BUG> lazy programmer >> ERROR in processRequest goroutine -req requeued for now")
-			// TODO: set stat on future & inform conn control and put it in faulted list
-			req.future.(FutureResult).onError(newSystemErrorWithCause("recovered panic in processAsyncRequest", e))
+			// future is resolved by the manager per the connection's replay policy.
+			// if none of its bytes were written, the request is (still) unsent.
+			if c.wire.n > offset {
+				req.stat = snderr
+			}
+			req.error = newSystemErrorWithCause("recovered panic in processAsyncRequest", e)
 			c.faults <- req
-			//			c.pendingReqs <- req
 		}
 	}()
 
 	// REVU - where is error check on this?
 	sendRequest(c.writer, *req.outbuff)
 
-	req.outbuff = nil
+	// pubsub (un)subscribe requests are acked via the message processor.
+	if c.pendingResps == nil {
+		if e := c.writer.Flush(); e != nil {
+			panic(e)
+		}
+		req.outbuff = nil
+		return 0, nil
+	}
+
+	// outbuff is retained for replay until the response is processed
 	select {
 	case c.pendingResps <- req:
 	default:
@@ -1045,6 +1787,26 @@ func (c *asyncConnHdl) processAsyncRequest(req asyncReqPtr) (blen int, e error)
 	return
 }
 
+// Buffers the writes to the (new) connection.
+func (c *asyncConnHdl) setWriter(conn io.Writer) {
+	c.wire = &countingWriter{Writer: conn}
+	c.writer = bufio.NewWriterSize(c.wire, c.spec().wBufSize)
+}
+
+// countingWriter counts the bytes written to the connection, so that a
+// request that faulted on send can be told apart from one that was never
+// (even partially) sent.
+type countingWriter struct {
+	io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (n int, e error) {
+	n, e = w.Writer.Write(p)
+	w.n += int64(n)
+	return
+}
+
 // request id needs to be unique in context of associated connection
 // only one goroutine calls this so no need to provide concurrency guards
 func (c *asyncConnHdl) nextId() (id int64) {
@@ -1052,3 +1814,290 @@ func (c *asyncConnHdl) nextId() (id int64) {
 	c.nextid++
 	return
 }
+
+// ----------------------------------------------------------------------------
+// asyncConnHdl fault handling
+// ----------------------------------------------------------------------------
+
+// Handles a fault raised by one of the workers.
+//
+// Workers are paused and the requests pending on the faulted connection are
+// collected.  Per spec, the connection will then attempt to reconnect (with
+// backoff) and pending requests are either replayed or failed per spec replay
+// policy, and the workers are resumed.  A REDIS_PUBSUB connection renews all
+// subscriptions, replays all pending requests (as (P)SUBSCRIBE and
+// (P)UNSUBSCRIBE are idempotent), and sends a Gap notification.  If reconnect
+// is not specified
+// or all attempts fail, the connection is closed.  See asyncConnHdl#close
+//
+// Returns true if the connection was closed.
+func (c *asyncConnHdl) onFault(cause error) (closed bool) {
+	spec := c.spec()
+
+	closeConnHdl(c.super) // unblocks workers waiting on net io
+	c.signalWorkers(pause)
+
+	policy := spec.replay
+	if spec.protocol == REDIS_PUBSUB {
+		policy = REPLAY_ALL
+	}
+
+	faulted := time.Now()
+	var unsent []asyncReqPtr
+	for attempt := 0; attempt < spec.reconnect; attempt++ {
+		inflight, queued := c.drainPending()
+		unsent = append(unsent, queued...)
+		switch policy {
+		case REPLAY_ALL:
+			unsent = append(inflight, unsent...)
+		case REPLAY_UNSENT:
+			failRequests(inflight, newSystemErrorWithCause("connection fault", cause))
+		default:
+			failRequests(inflight, newSystemErrorWithCause("connection fault", cause))
+			failRequests(unsent, newSystemErrorWithCause("connection fault", cause))
+			unsent = nil
+		}
+
+		time.Sleep(spec.backoffPeriod(attempt))
+		hdl, e := openConnHdl(spec)
+		if e != nil {
+			log.Printf("<INFO> - %s reconnect attempt %d failed - %s", c, attempt+1, e)
+			cause = e
+			continue
+		}
+		c.super = hdl
+		c.setWriter(hdl.conn)
+
+		var renewed []SubscriptionKey
+		var re error
+		if spec.protocol == REDIS_PUBSUB {
+			renewed, re = c.resubscribe()
+		}
+		if re == nil {
+			unsent, re = c.replay(unsent)
+		}
+		if re != nil {
+			log.Printf("<INFO> - %s replay on reconnect attempt %d failed - %s", c, attempt+1, re)
+			closeConnHdl(c.super)
+			cause = re
+			continue
+		}
+
+		// REVU - pretty please TODO do the customized log
+		log.Printf("<INFO> - %s RECONNECTED", c)
+		if spec.protocol == REDIS_PUBSUB {
+			c.notifyGap(&Gap{faulted, time.Now(), cause, renewed})
+		}
+		c.signalWorkers(start)
+		return false
+	}
+
+	err := newConnectionClosedError(cause)
+	failRequests(unsent, err)
+	c.close(err)
+	return true
+}
+
+// Sends the interrupt signal to the request, response, and heartbeat workers.
+// Unless starting the workers, feedback sent by the workers meanwhile (e.g.
+// faults raised on the closed connection) is discarded.
+func (c *asyncConnHdl) signalWorkers(sig interrupt_code) {
+	for _, ctl := range []workerCtl{c.reqProcCtl, c.rspProcCtl, c.heartbeatCtl} {
+		c.signalWorker(ctl, sig)
+	}
+}
+
+// See signalWorkers
+func (c *asyncConnHdl) signalWorker(ctl workerCtl, sig interrupt_code) {
+	if ctl == nil {
+		return
+	}
+	if sig == start {
+		ctl <- sig
+		return
+	}
+	for {
+		select {
+		case ctl <- sig:
+			return
+		case <-c.feedback:
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// asyncConnHdl shutdown
+// ----------------------------------------------------------------------------
+
+// Closes the connection.  Called by the manager only, either on QUIT or on
+// unrecoverable faults.
+//
+// New requests are rejected, the workers are stopped, the socket (and the
+// dedicated connections) are closed,
+// pending requests are failed with a ConnectionClosedError, and all
+// subscription channels are closed.  disconnect() returns once done.
+func (c *asyncConnHdl) close(cause error) {
+	// reject new requests - and wait for requests being queued
+	close(c.shutdown)
+	c.shutdownLock.Lock()
+	c.shutdownLock.Unlock()
+
+	closeConnHdl(c.super) // unblocks workers waiting on net io
+	c.dedicated.close()
+	c.signalWorker(c.reqProcCtl, stop)
+	c.signalWorker(c.rspProcCtl, stop)
+
+	var err Error
+	if cce, ok := cause.(ConnectionClosedError); ok {
+		err = cce
+	} else {
+		err = newConnectionClosedError(cause)
+	}
+	inflight, queued := c.drainPending()
+	failRequests(inflight, err)
+	failRequests(queued, err)
+
+	// heartbeat may be waiting on a (now failed) PING
+	c.signalWorker(c.heartbeatCtl, stop)
+
+	c.closeSubscriptions(err)
+	if c.gaps != nil {
+		close(c.gaps)
+	}
+	close(c.closed)
+
+	// REVU - pretty please TODO do the customized log
+	log.Printf("<INFO> - %s CLOSED", c)
+}
+
+// Closes the channels of all subscriptions and removes them.
+// Pending subscriptions are failed.
+func (c *asyncConnHdl) closeSubscriptions(err Error) {
+	c.subsLock.Lock()
+	defer c.subsLock.Unlock()
+
+	for key, s := range c.subscriptions {
+		if !s.IsActive {
+			s.activated.(FutureResult).onError(err)
+		}
+		if s.Envelopes != nil {
+			close(s.Envelopes)
+		} else {
+			close(s.Channel)
+		}
+		delete(c.subscriptions, key)
+	}
+	if c.drained != nil {
+		close(c.drained)
+		c.drained = nil
+	}
+}
+
+// Collects the requests pending on the connection, in request order.
+// inflight requests have been (or may have been) sent to the server;
+// unsent requests have not.
+// Workers must be paused.
+func (c *asyncConnHdl) drainPending() (inflight, unsent []asyncReqPtr) {
+	var sndfaults []asyncReqPtr
+	for {
+		select {
+		case req := <-c.faults:
+			switch req.stat {
+			case rcverr:
+				inflight = append(inflight, req)
+			case snderr:
+				sndfaults = append(sndfaults, req)
+			default: // faulted before any of its bytes were written
+				unsent = append(unsent, req)
+			}
+			continue
+		default:
+		}
+		break
+	}
+	for {
+		select {
+		case req := <-c.pendingResps:
+			inflight = append(inflight, req)
+			continue
+		default:
+		}
+		break
+	}
+	inflight = append(inflight, sndfaults...)
+	for {
+		select {
+		case req := <-c.pendingReqs:
+			unsent = append(unsent, req)
+			continue
+		default:
+		}
+		break
+	}
+	return
+}
+
+// Re-issues (P)SUBSCRIBE for all subscriptions on the (reconnected)
+// connection, and returns the renewed subscriptions.
+// Workers must be paused.
+func (c *asyncConnHdl) resubscribe() (renewed []SubscriptionKey, e error) {
+	var topics, patterns [][]byte
+	c.subsLock.Lock()
+	for key := range c.subscriptions {
+		renewed = append(renewed, key)
+		if key.Pattern {
+			patterns = append(patterns, []byte(key.Topic))
+		} else {
+			topics = append(topics, []byte(key.Topic))
+		}
+	}
+	c.subsLock.Unlock()
+
+	for _, cmd := range []*Command{&SUBSCRIBE, &PSUBSCRIBE} {
+		args := topics
+		if cmd == &PSUBSCRIBE {
+			args = patterns
+		}
+		if len(args) == 0 {
+			continue
+		}
+		buff := CreateRequestBytes(cmd, args)
+		if _, e = c.processAsyncRequest(&asyncRequestInfo{0, 0, cmd, &buff, nil, nil, nil}); e != nil {
+			return
+		}
+	}
+	return
+}
+
+// Sends the Gap notification, unless the gap channel is full.
+func (c *asyncConnHdl) notifyGap(gap *Gap) {
+	select {
+	case c.gaps <- gap:
+	default:
+		log.Printf("<INFO> - %s gap notification dropped - channel is full", c)
+	}
+}
+
+// Sends the requests on the (reconnected) connection.
+// On error, the requests that were not sent are returned.
+// Workers must be paused.
+func (c *asyncConnHdl) replay(reqs []asyncReqPtr) (rest []asyncReqPtr, e error) {
+	for i, req := range reqs {
+		req.stat = ok
+		req.error = nil
+		if _, e = c.processAsyncRequest(req); e != nil {
+			return reqs[i+1:], e
+		}
+	}
+	return nil, c.writer.Flush()
+}
+
+// Fails the futures of the requests.
+func failRequests(reqs []asyncReqPtr, err Error) {
+	for _, req := range reqs {
+		if req.future != nil {
+			req.future.(FutureResult).onError(err)
+		}
+		failRequests(req.txreqs, err)
+	}
+}
@@ -3,14 +3,826 @@
 package redis
 
 import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestStub(t *testing.T) {
 	/* feed the compiler */
 }
 
+// ----------------------------------------------------------------------------
+// in-process fake redis server
+// ----------------------------------------------------------------------------
+
+// fakeServer accepts connections and replies to each request with the raw
+// protocol bytes returned by its handler.  A handler return of "" closes
+// the connection, e.g. to simulate a network fault.
+type fakeServer struct {
+	listener net.Listener
+	session  func() func(args []string) string // per connection handler
+	mutex    sync.Mutex
+	wmutex   sync.Mutex // serializes replies and pushes
+	conns    []net.Conn
+	accepted int
+}
+
+func newFakeServer(t *testing.T, handler func(args []string) string) *fakeServer {
+	return newFakeSessionServer(t, func() func(args []string) string { return handler })
+}
+
+// session is called on each accepted connection to obtain its handler.
+func newFakeSessionServer(t *testing.T, session func() func(args []string) string) *fakeServer {
+	listener, e := net.Listen("tcp", "127.0.0.1:0")
+	if e != nil {
+		t.Fatalf("newFakeServer - %s", e)
+	}
+	s := &fakeServer{listener: listener, session: session}
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) spec() *ConnectionSpec {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return DefaultSpec().Host(addr.IP.String()).Port(addr.Port)
+}
+
+func (s *fakeServer) connectionCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.accepted
+}
+
+func (s *fakeServer) close() {
+	s.listener.Close()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// closes all connections, e.g. to simulate a network fault.  The server
+// continues to accept new connections.
+func (s *fakeServer) drop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// writes the raw protocol bytes to all connections, e.g. to push pubsub
+// messages.
+func (s *fakeServer) push(raw string) {
+	s.mutex.Lock()
+	conns := append([]net.Conn{}, s.conns...)
+	s.mutex.Unlock()
+
+	s.wmutex.Lock()
+	defer s.wmutex.Unlock()
+	for _, conn := range conns {
+		conn.Write([]byte(raw))
+	}
+}
+
+// writes the raw protocol bytes to the i-th accepted connection, e.g. to
+// push messages to a reconnected connection.
+func (s *fakeServer) pushTo(i int, raw string) {
+	conn := s.connAt(i)
+	s.wmutex.Lock()
+	defer s.wmutex.Unlock()
+	conn.Write([]byte(raw))
+}
+
+// returns the i-th accepted connection.
+func (s *fakeServer) connAt(i int) net.Conn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.conns[i]
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, e := s.listener.Accept()
+		if e != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.conns = append(s.conns, conn)
+		s.accepted++
+		s.mutex.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+func (s *fakeServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+	defer func() { recover() }() // io errors on close
+
+	handler := s.session()
+	reader := bufio.NewReader(conn)
+	for {
+		buf := readToCRLF(reader)
+		cnt, _ := strconv.Atoi(string(buf[1:]))
+		data := readMultiBulkData(reader, cnt)
+		args := make([]string, len(data))
+		for i, arg := range data {
+			args[i] = string(arg)
+		}
+		reply := handler(args)
+		if reply == "" {
+			return
+		}
+		s.wmutex.Lock()
+		_, e := conn.Write([]byte(reply))
+		s.wmutex.Unlock()
+		if e != nil {
+			return
+		}
+	}
+}
+
+// recordingServer is a fakeServer that records the requests it receives
+// (as their space separated args), and replies per its handler.
+type recordingServer struct {
+	*fakeServer
+	mutex    sync.Mutex
+	requests []string
+}
+
+func newRecordingServer(t *testing.T, handler func(args []string) string) *recordingServer {
+	s := &recordingServer{}
+	s.fakeServer = newFakeServer(t, func(args []string) string {
+		s.mutex.Lock()
+		s.requests = append(s.requests, strings.Join(args, " "))
+		s.mutex.Unlock()
+		return handler(args)
+	})
+	return s
+}
+
+// returns the requests received, and clears them.
+func (s *recordingServer) received() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+// fakeRedis is a minimal in-memory redis supporting a handful of string
+// commands, MULTI/EXEC/WATCH, scripting, and AUTH.
+//
+// If users (username -> password) are set, connections must AUTH, with
+// the password of the default user, or as an ACL user.
+//
+// Scripts are not run - EVAL and EVALSHA reply with [numkeys, script,
+// [+OK, nil]].
+type fakeRedis struct {
+	mutex    sync.Mutex
+	data     map[string]string
+	versions map[string]int
+	scripts  map[string]string // sha1 -> src
+	evals    int               // EVAL count
+	users    map[string]string // ACL users - none means no AUTH required
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		data:     make(map[string]string),
+		versions: make(map[string]int),
+		scripts:  make(map[string]string),
+	}
+}
+
+func bulkReply(v string) string {
+	return "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+}
+
+// returns the raw bytes of a pubsub message.
+func pubsubMessage(kind string, elems ...string) string {
+	raw := "*" + strconv.Itoa(len(elems)+1) + "\r\n" + bulkReply(kind)
+	for _, elem := range elems {
+		raw += bulkReply(elem)
+	}
+	return raw
+}
+
+// returns the session handler for a new connection.
+func (r *fakeRedis) session() func(args []string) string {
+	var queued [][]string
+	var watched map[string]int
+	inMulti := false
+	subscriptions := 0
+	authenticated := false
+
+	return func(args []string) string {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		switch args[0] {
+		case "AUTH":
+			user, password := "default", args[1]
+			if len(args) == 3 {
+				user, password = args[1], args[2]
+			}
+			if pw, ok := r.users[user]; !ok || pw != password {
+				return "-WRONGPASS invalid username-password pair or user is disabled.\r\n"
+			}
+			authenticated = true
+			return "+OK\r\n"
+		case "QUIT":
+		default:
+			if len(r.users) > 0 && !authenticated {
+				return "-NOAUTH Authentication required.\r\n"
+			}
+		}
+
+		switch args[0] {
+		case "SUBSCRIBE", "PSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE":
+			acks := ""
+			for _, topic := range args[1:] {
+				if strings.Contains(args[0], "UNSUBSCRIBE") {
+					subscriptions--
+				} else {
+					subscriptions++
+				}
+				acks += "*3\r\n" + bulkReply(strings.ToLower(args[0])) + bulkReply(topic) +
+					":" + strconv.Itoa(subscriptions) + "\r\n"
+			}
+			return acks
+		case "MULTI":
+			inMulti = true
+			return "+OK\r\n"
+		case "EXEC":
+			inMulti = false
+			cmds := queued
+			queued = nil
+			for key, version := range watched {
+				if r.versions[key] != version {
+					watched = nil
+					return "*-1\r\n"
+				}
+			}
+			watched = nil
+			reply := "*" + strconv.Itoa(len(cmds)) + "\r\n"
+			for _, cmd := range cmds {
+				reply += r.exec(cmd)
+			}
+			return reply
+		case "WATCH":
+			if watched == nil {
+				watched = make(map[string]int)
+			}
+			for _, key := range args[1:] {
+				watched[key] = r.versions[key]
+			}
+			return "+OK\r\n"
+		case "UNWATCH":
+			watched = nil
+			return "+OK\r\n"
+		}
+		if inMulti {
+			if args[0] == "BADCMD" {
+				return "-ERR unknown command 'BADCMD'\r\n"
+			}
+			queued = append(queued, args)
+			return "+QUEUED\r\n"
+		}
+		return r.exec(args)
+	}
+}
+
+func (r *fakeRedis) exec(args []string) string {
+	switch args[0] {
+	case "GET":
+		if v, ok := r.data[args[1]]; ok {
+			return bulkReply(v)
+		}
+		return "$-1\r\n"
+	case "MGET":
+		reply := "*" + strconv.Itoa(len(args)-1) + "\r\n"
+		for _, key := range args[1:] {
+			if v, ok := r.data[key]; ok {
+				reply += bulkReply(v)
+			} else {
+				reply += "$-1\r\n"
+			}
+		}
+		return reply
+	case "SET":
+		r.data[args[1]] = args[2]
+		r.versions[args[1]]++
+		return "+OK\r\n"
+	case "FLUSHDB":
+		r.data = make(map[string]string)
+		return "+OK\r\n"
+	case "INCR":
+		n, e := strconv.ParseInt(r.data[args[1]], 10, 64)
+		if e != nil && r.data[args[1]] != "" {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		n++
+		r.data[args[1]] = strconv.FormatInt(n, 10)
+		r.versions[args[1]]++
+		return ":" + r.data[args[1]] + "\r\n"
+	case "LRANGE":
+		return "*2\r\n$1\r\na\r\n$1\r\nb\r\n"
+	case "EVAL", "EVALSHA":
+		src := args[1]
+		if args[0] == "EVALSHA" {
+			var ok bool
+			if src, ok = r.scripts[args[1]]; !ok {
+				return "-NOSCRIPT No matching script. Please use EVAL.\r\n"
+			}
+		} else {
+			r.evals++
+			r.loadScript(src)
+		}
+		return "*3\r\n:" + args[2] + "\r\n" + bulkReply(src) + "*2\r\n+OK\r\n$-1\r\n"
+	case "SCRIPT":
+		switch args[1] {
+		case "LOAD":
+			return bulkReply(r.loadScript(args[2]))
+		case "EXISTS":
+			reply := "*" + strconv.Itoa(len(args)-2) + "\r\n"
+			for _, sha := range args[2:] {
+				if _, ok := r.scripts[sha]; ok {
+					reply += ":1\r\n"
+				} else {
+					reply += ":0\r\n"
+				}
+			}
+			return reply
+		case "FLUSH":
+			r.scripts = make(map[string]string)
+			return "+OK\r\n"
+		}
+	case "QUIT":
+		return "+OK\r\n"
+	case "PING":
+		return "+PONG\r\n"
+	case "XREAD":
+		return "*-1\r\n" // no new entries
+	}
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+func (r *fakeRedis) loadScript(src string) string {
+	hash := sha1.Sum([]byte(src))
+	sha := hex.EncodeToString(hash[:])
+	r.scripts[sha] = src
+	return sha
+}
+
+// ----------------------------------------------------------------------------
+// asyncConnHdl reconnect
+// ----------------------------------------------------------------------------
+
+// returns a handler that drops the connection on the first GET.
+func dropFirstGetHandler() func(args []string) string {
+	var mutex sync.Mutex
+	dropped := false
+	return func(args []string) string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch args[0] {
+		case "GET":
+			if !dropped {
+				dropped = true
+				return ""
+			}
+			return "$3\r\nbar\r\n"
+		case "QUIT":
+			return "+OK\r\n"
+		}
+		return "+PONG\r\n"
+	}
+}
+
+func reconnectTestSpec(server *fakeServer) *ConnectionSpec {
+	return server.spec().Heartbeat(time.Hour).Reconnect(3).Backoff(10*time.Millisecond, 50*time.Millisecond)
+}
+
+func TestAsyncReconnectReplayAll(t *testing.T) {
+	server := newFakeServer(t, dropFirstGetHandler())
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(reconnectTestSpec(server).Replay(REPLAY_ALL))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	future, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	v, e, timedout := future.TryGet(5 * time.Second)
+	if timedout || e != nil || string(v) != "bar" {
+		t.Fatalf("expected replayed GET result - value:%s error:%s timedout:%t", v, e, timedout)
+	}
+	if n := server.connectionCount(); n != 2 {
+		t.Errorf("expected 2 connections - got %d", n)
+	}
+}
+
+func TestAsyncReconnectFailPending(t *testing.T) {
+	server := newFakeServer(t, dropFirstGetHandler())
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(reconnectTestSpec(server).Replay(FAIL_PENDING))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	future, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	if _, e, timedout := future.TryGet(5 * time.Second); timedout || e == nil || e.IsRedisError() {
+		t.Fatalf("expected SystemError on failed GET - error:%s timedout:%t", e, timedout)
+	}
+
+	// reconnected connection services new requests
+	future, e = client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	v, e, timedout := future.TryGet(5 * time.Second)
+	if timedout || e != nil || string(v) != "bar" {
+		t.Fatalf("expected GET result after reconnect - value:%s error:%s timedout:%t", v, e, timedout)
+	}
+}
+
+func TestAsyncFaultWithoutReconnect(t *testing.T) {
+	server := newFakeServer(t, dropFirstGetHandler())
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+
+	future, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	if _, e, timedout := future.TryGet(5 * time.Second); timedout || e == nil {
+		t.Fatalf("expected error on faulted GET - error:%s timedout:%t", e, timedout)
+	}
+	time.Sleep(50 * time.Millisecond)
+	_, e = client.Get("foo")
+	if cce, ok := e.(ConnectionClosedError); !ok || !cce.ConnectionClosed() {
+		t.Errorf("expected ConnectionClosedError on request to shutdown connection - got %v", e)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// asyncConnHdl shutdown
+// ----------------------------------------------------------------------------
+
+// returns a handler that delays its replies until release is closed.
+func delayedHandler(release chan bool) func(args []string) string {
+	return func(args []string) string {
+		<-release
+		switch args[0] {
+		case "GET":
+			return "$3\r\nbar\r\n"
+		}
+		return "+OK\r\n"
+	}
+}
+
+func TestAsyncQuit(t *testing.T) {
+	release := make(chan bool)
+	server := newFakeServer(t, delayedHandler(release))
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	before, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	stat, e := client.Quit()
+	if e != nil {
+		t.Fatalf("Quit - %s", e)
+	}
+	after, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	close(release)
+
+	if v, e, timedout := before.TryGet(5 * time.Second); timedout || e != nil || string(v) != "bar" {
+		t.Errorf("expected GET result before QUIT - value:%s error:%s timedout:%t", v, e, timedout)
+	}
+	if ok, e, timedout := stat.TryGet(5 * time.Second); timedout || e != nil || !ok {
+		t.Errorf("expected QUIT ok - error:%s timedout:%t", e, timedout)
+	}
+	// GET after QUIT is pending when the connection is closed
+	_, e, timedout := after.TryGet(5 * time.Second)
+	if _, ok := e.(ConnectionClosedError); timedout || !ok {
+		t.Errorf("expected ConnectionClosedError on GET after QUIT - error:%v timedout:%t", e, timedout)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if _, e := client.Get("foo"); e == nil {
+		t.Error("expected error on request to closed connection")
+	}
+}
+
+// a connection that accepts limit bytes, then fails.
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (n int, e error) {
+	if n = len(p); n > w.limit {
+		n = w.limit
+		e = errors.New("write failed")
+	}
+	w.limit -= n
+	return
+}
+
+func TestAsyncDrainPendingSendFaults(t *testing.T) {
+	for _, tc := range []struct {
+		limit        int
+		inflight     int
+		unsentFaults int
+	}{
+		{0, 1, 1},  // nothing written - the faulted request is unsent
+		{12, 2, 0}, // faulted request partially written
+	} {
+		c := new(asyncConnHdl)
+		c.pendingReqs = make(chan asyncReqPtr, 4)
+		c.pendingResps = make(chan asyncReqPtr, 4)
+		c.faults = make(chan asyncReqPtr, 4)
+		c.wire = &countingWriter{Writer: &failingWriter{tc.limit}}
+		c.writer = bufio.NewWriterSize(c.wire, 16)
+
+		buffered := make([]byte, 8) // fits in the write buffer
+		faulted := make([]byte, 20) // flushes the buffer
+		if _, e := c.processAsyncRequest(&asyncRequestInfo{outbuff: &buffered}); e != nil {
+			t.Fatalf("processAsyncRequest - %s", e)
+		}
+		if _, e := c.processAsyncRequest(&asyncRequestInfo{outbuff: &faulted}); e == nil {
+			t.Fatal("expected processAsyncRequest error")
+		}
+		c.pendingReqs <- &asyncRequestInfo{}
+
+		inflight, unsent := c.drainPending()
+		if len(inflight) != tc.inflight || len(unsent) != tc.unsentFaults+1 {
+			t.Errorf("limit %d - expected %d inflight and %d unsent - got %d and %d",
+				tc.limit, tc.inflight, tc.unsentFaults+1, len(inflight), len(unsent))
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// read/write timeouts
+// ----------------------------------------------------------------------------
+
+// returns a handler that never replies, and stops reading after the first
+// request, until hang is closed.
+func hungHandler(hang chan bool) func(args []string) string {
+	return func(args []string) string {
+		<-hang
+		return ""
+	}
+}
+
+// returns a handler that replies to GET with the key, and delays the reply
+// to GET slow until release is closed.
+func slowGetHandler(release chan bool) func(args []string) string {
+	return func(args []string) string {
+		switch args[0] {
+		case "GET":
+			if args[1] == "slow" {
+				<-release
+			}
+			return bulkReply(args[1])
+		}
+		return "+OK\r\n"
+	}
+}
+
+func assertTimeoutError(t *testing.T, info string, e Error) {
+	if e == nil {
+		t.Fatalf("%s - expected timeout error", info)
+	}
+	syserr, ok := e.(SystemError)
+	if !ok {
+		t.Fatalf("%s - expected SystemError - got %s", info, e)
+	}
+	neterr, ok := syserr.Cause().(net.Error)
+	if !ok || !neterr.Timeout() {
+		t.Fatalf("%s - expected timeout net.Error cause - got %v", info, syserr.Cause())
+	}
+}
+
+func TestSyncTimeouts(t *testing.T) {
+	hang := make(chan bool)
+	server := newFakeServer(t, hungHandler(hang))
+	defer server.close()
+	defer close(hang)
+
+	spec := server.spec().ReadTimeout(100 * time.Millisecond).WriteTimeout(100 * time.Millisecond)
+	client, e := NewSynchClientWithSpec(spec)
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+
+	start := time.Now()
+	_, e = client.Get("foo")
+	assertTimeoutError(t, "Get", e)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected read timeout - took %s", elapsed)
+	}
+
+	// server is not reading - large requests fill the socket buffers
+	e = client.Set("foo", make([]byte, 64*1024*1024))
+	assertTimeoutError(t, "Set", e)
+}
+
+func TestSyncTimeoutDisconnects(t *testing.T) {
+	for _, pooled := range []bool{false, true} {
+		release := make(chan bool)
+		server := newFakeServer(t, slowGetHandler(release))
+		defer server.close()
+
+		spec := server.spec().ReadTimeout(100 * time.Millisecond)
+		newClient := NewSynchClientWithSpec
+		if pooled {
+			newClient = NewPooledSynchClientWithSpec
+		}
+		client, e := newClient(spec)
+		if e != nil {
+			t.Fatalf("new client (pooled:%t) - %s", pooled, e)
+		}
+
+		_, e = client.Get("slow")
+		assertTimeoutError(t, "Get", e)
+
+		// the late reply of the timed out request must not be read as the
+		// reply of the next request
+		close(release)
+		time.Sleep(100 * time.Millisecond)
+		value, e := client.Get("b")
+		if e != nil || string(value) != "b" {
+			t.Errorf("expected b on a new connection (pooled:%t) - got %q %v", pooled, value, e)
+		}
+		if server.connectionCount() != 2 {
+			t.Errorf("expected the faulted connection to be replaced (pooled:%t) - got %d connections", pooled, server.connectionCount())
+		}
+	}
+}
+
+func TestAsyncReadTimeout(t *testing.T) {
+	hang := make(chan bool)
+	server := newFakeServer(t, hungHandler(hang))
+	defer server.close()
+	defer close(hang)
+
+	spec := server.spec().Heartbeat(time.Hour).ReadTimeout(100 * time.Millisecond)
+	client, e := NewAsynchClientWithSpec(spec)
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+
+	future, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	_, e, timedout := future.TryGet(5 * time.Second)
+	if timedout {
+		t.Fatal("expected read timeout on GET")
+	}
+	assertTimeoutError(t, "Get", e)
+}
+
+func TestDefaultSpecTimeouts(t *testing.T) {
+	spec := DefaultSpec()
+	if spec.rTimeout != 0 || spec.wTimeout != 0 {
+		t.Errorf("expected no default timeouts - got %s %s", spec.rTimeout, spec.wTimeout)
+	}
+	spec.ReadTimeout(time.Second).WriteTimeout(2 * time.Second)
+	if spec.rTimeout != time.Second || spec.wTimeout != 2*time.Second {
+		t.Errorf("unexpected timeouts %s %s", spec.rTimeout, spec.wTimeout)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// AUTH
+// ----------------------------------------------------------------------------
+
+func newAuthTestServer(t *testing.T) *fakeServer {
+	redis := newFakeRedis()
+	redis.users = map[string]string{"default": "secret", "alice": "wonderland"}
+	return newFakeSessionServer(t, redis.session)
+}
+
+func TestAuthUsername(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec().Username("alice").Password("wonderland"))
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	if e := client.Set("foo", []byte("bar")); e != nil {
+		t.Errorf("Set - %s", e)
+	}
+
+	client, e = NewSynchClientWithSpec(server.spec().Password("secret"))
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec (default user) - %s", e)
+	}
+	if e := client.Set("foo", []byte("bar")); e != nil {
+		t.Errorf("Set - %s", e)
+	}
+
+	async, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour).Username("alice").Password("wonderland"))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer async.Quit()
+	future, e := async.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	if v, e, timedout := future.TryGet(5 * time.Second); timedout || e != nil || string(v) != "bar" {
+		t.Errorf("expected GET result - value:%s error:%s timedout:%t", v, e, timedout)
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.close()
+
+	specs := []*ConnectionSpec{
+		server.spec().Username("alice").Password("looking-glass"),
+		server.spec().Password("wonderland"),
+	}
+	for _, spec := range specs {
+		_, e := NewSynchClientWithSpec(spec)
+		if wperr, ok := e.(WrongPassError); !ok || !wperr.WrongPass() || !e.IsRedisError() {
+			t.Errorf("expected WrongPassError - got %v", e)
+		}
+		_, e = NewAsynchClientWithSpec(spec)
+		if _, ok := e.(WrongPassError); !ok {
+			t.Errorf("expected WrongPassError (async) - got %v", e)
+		}
+		_, e = NewPooledSynchClientWithSpec(spec)
+		if e == nil {
+			// pools connect lazily
+			client, _ := NewPooledSynchClientWithSpec(spec)
+			_, e = client.Get("foo")
+		}
+		if _, ok := e.(WrongPassError); !ok {
+			t.Errorf("expected WrongPassError (pooled) - got %v", e)
+		}
+	}
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	_, e = client.Get("foo")
+	if naerr, ok := e.(NoAuthError); !ok || !naerr.NoAuth() || !e.IsRedisError() {
+		t.Errorf("expected NoAuthError - got %v", e)
+	}
+
+	async, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer async.Quit()
+	future, e := async.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	_, e, _ = future.TryGet(5 * time.Second)
+	if _, ok := e.(NoAuthError); !ok {
+		t.Errorf("expected NoAuthError (async) - got %v", e)
+	}
+}
+
 /* --------------- KEEP THIS AS LAST FUNCTION -------------- */
 func TestEnd_ct(t *testing.T) {
 	log.Println("-- connection test completed")
@@ -0,0 +1,208 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"context"
+)
+
+// -----------------------------------------------------------------------------
+// context bound clients - see Client.WithContext and AsyncClient.WithContext
+// -----------------------------------------------------------------------------
+
+// Returns a Client that services requests on the client's connection(s),
+// bound to ctx.
+func (c *syncClient) WithContext(ctx context.Context) Client {
+	return &syncClient{conn: &contextSyncConn{ctx, c.conn}}
+}
+
+// Returns an AsyncClient that queues requests on the client's connection,
+// bound to ctx.
+func (c *asyncClient) WithContext(ctx context.Context) AsyncClient {
+	return &asyncClient{conn: &contextAsyncConn{ctx, c.conn}}
+}
+
+// Error of requests made after (or waiting when) the context is done.
+// Cause() is the context's error, i.e. context.Canceled or
+// context.DeadlineExceeded.
+func newContextError(ctx context.Context) Error {
+	return newSystemErrorWithCause("context done", ctx.Err())
+}
+
+// -----------------------------------------------------------------------------
+// contextSyncConn - supports SyncConnection interface
+// -----------------------------------------------------------------------------
+
+// contextSyncConn services requests on the delegate connection in a new
+// goroutine, and stops waiting on the response once the context is done.
+//
+// The abandoned request runs to completion (or per spec read timeout) and
+// its response is read and discarded, so the connection is not left with an
+// unread response.  Connections are not shared meanwhile: connHdl serializes
+// requests, and connPool returns the connection to the pool on completion.
+// Hence, absent a read timeout, subsequent requests on a (non-pooled)
+// connection wait until the response of the abandoned request arrives.
+type contextSyncConn struct {
+	ctx  context.Context
+	conn SyncConnection
+}
+
+type syncResult struct {
+	resp Response
+	err  Error
+}
+
+// Implementation of SyncConnection.ServiceRequest
+func (c *contextSyncConn) ServiceRequest(cmd *Command, args [][]byte) (Response, Error) {
+	if c.ctx.Err() != nil {
+		return nil, newContextError(c.ctx)
+	}
+
+	done := make(chan syncResult, 1)
+	go func() {
+		resp, err := c.conn.ServiceRequest(cmd, args)
+		done <- syncResult{resp, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-c.ctx.Done():
+		return nil, newContextError(c.ctx)
+	}
+}
+
+// Implementation of SyncConnection.Do
+func (c *contextSyncConn) Do(name string, args ...interface{}) (*Reply, Error) {
+	return serviceDo(c, name, args)
+}
+
+// txConnection support - transactions (and blocking requests) are serviced
+// on a connection of the delegate, and are not bound to the context.
+func (c *contextSyncConn) checkout() (*connHdl, Error) {
+	if c.ctx.Err() != nil {
+		return nil, newContextError(c.ctx)
+	}
+	return checkoutOf(c.conn)
+}
+func (c *contextSyncConn) release(hdl *connHdl, broken bool) {
+	c.conn.(txConnection).release(hdl, broken)
+}
+
+// checks out a connection of the delegate of a context bound connection.
+func checkoutOf(conn interface{}) (*connHdl, Error) {
+	source, ok := conn.(txConnection)
+	if !ok {
+		return nil, newSystemError("connection does not support transactions")
+	}
+	return source.checkout()
+}
+
+// -----------------------------------------------------------------------------
+// contextAsyncConn - supports AsyncConnection interface
+// -----------------------------------------------------------------------------
+
+// contextAsyncConn queues requests on the delegate connection, and returns
+// futures that are failed once the context is done.
+//
+// Abandoned requests remain in the pipeline.  Their responses are processed
+// in order (see dbRspProcessingTask) and discarded.
+type contextAsyncConn struct {
+	ctx  context.Context
+	conn AsyncConnection
+}
+
+// Implementation of AsyncConnection.QueueRequest
+func (c *contextAsyncConn) QueueRequest(cmd *Command, args [][]byte) (*PendingResponse, Error) {
+	if c.ctx.Err() != nil {
+		return nil, newContextError(c.ctx)
+	}
+
+	pending, err := c.conn.QueueRequest(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+
+	future := CreateFuture(cmd)
+	go forwardResult(c.ctx, resultChan(pending.future), resultChan(future))
+	return &PendingResponse{future}, nil
+}
+
+// Implementation of AsyncConnection.Do
+func (c *contextAsyncConn) Do(name string, args ...interface{}) (FutureReply, Error) {
+	return queueDo(c, name, args)
+}
+
+// txPipeline support - see contextSyncConn.checkout.  The status of queued
+// transactions is bound to the context, as with QueueRequest.
+func (c *contextAsyncConn) checkout() (*connHdl, Error) {
+	if c.ctx.Err() != nil {
+		return nil, newContextError(c.ctx)
+	}
+	return checkoutOf(c.conn)
+}
+func (c *contextAsyncConn) release(hdl *connHdl, broken bool) {
+	c.conn.(txConnection).release(hdl, broken)
+}
+func (c *contextAsyncConn) queueTransaction(reqs []asyncReqPtr) (FutureBool, Error) {
+	if c.ctx.Err() != nil {
+		return nil, newContextError(c.ctx)
+	}
+	pipeline, ok := c.conn.(txPipeline)
+	if !ok {
+		return nil, newSystemError("connection does not support transactions")
+	}
+	status, err := pipeline.queueTransaction(reqs)
+	if err != nil {
+		return nil, err
+	}
+	future := newFutureBool()
+	go forwardResult(c.ctx, resultChan(status), resultChan(future))
+	return future, nil
+}
+
+// forwards the result of the request's future to the caller's, or fails
+// the latter once the context is done.
+func forwardResult(ctx context.Context, from, to chan result) {
+	select {
+	case r := <-from:
+		to <- r
+	case <-ctx.Done():
+		to <- result{nil, newContextError(ctx)}
+	}
+}
+
+// returns the result channel of the future.  See CreateFuture
+func resultChan(future interface{}) chan result {
+	switch f := future.(type) {
+	case _boolfuture:
+		return f
+	case _byteslicefuture:
+		return f
+	case _bytearrayslicefuture:
+		return f
+	case _futureint64:
+		return f
+	case _futurestring:
+		return f
+	case _futurereply:
+		return f
+	case _futurefloat64:
+		return f
+	case _futurebytesmap:
+		return f
+	}
+	panic(newSystemErrorf("BUG - resultChan - unexpected future type %T", future))
+}
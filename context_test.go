@@ -0,0 +1,169 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+)
+
+func assertContextError(t *testing.T, info string, e Error, cause error) {
+	if e == nil {
+		t.Fatalf("%s - expected context error", info)
+	}
+	syserr, ok := e.(SystemError)
+	if !ok || syserr.Cause() != cause {
+		t.Fatalf("%s - expected SystemError with cause %s - got %s", info, cause, e)
+	}
+}
+
+func TestSyncWithContext(t *testing.T) {
+	release := make(chan bool)
+	server := newFakeServer(t, slowGetHandler(release))
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	bound := client.WithContext(ctx)
+
+	if v, e := bound.Get("fast"); e != nil || string(v) != "fast" {
+		t.Fatalf("expected GET result - value:%s error:%s", v, e)
+	}
+	_, e = bound.Get("slow")
+	assertContextError(t, "Get", e, context.DeadlineExceeded)
+	_, e = bound.Get("fast")
+	assertContextError(t, "Get after deadline", e, context.DeadlineExceeded)
+
+	// the abandoned response is discarded
+	close(release)
+	if v, e := client.Get("fast"); e != nil || string(v) != "fast" {
+		t.Fatalf("expected GET result after abandoned GET - value:%s error:%s", v, e)
+	}
+}
+
+func TestAsyncWithContext(t *testing.T) {
+	release := make(chan bool)
+	server := newFakeServer(t, slowGetHandler(release))
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bound := client.WithContext(ctx)
+
+	fast, e := bound.Get("fast")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	if v, e, timedout := fast.TryGet(5 * time.Second); timedout || e != nil || string(v) != "fast" {
+		t.Fatalf("expected GET result - value:%s error:%s timedout:%t", v, e, timedout)
+	}
+
+	slow, e := bound.Get("slow")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	cancel()
+	_, e, timedout := slow.TryGet(5 * time.Second)
+	if timedout {
+		t.Fatal("expected abandoned future to be set")
+	}
+	assertContextError(t, "Get", e, context.Canceled)
+
+	_, e = bound.Get("fast")
+	assertContextError(t, "Get after cancel", e, context.Canceled)
+
+	// the pipeline is in sync
+	close(release)
+	fast, e = client.Get("fast")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	if v, e, timedout := fast.TryGet(5 * time.Second); timedout || e != nil || string(v) != "fast" {
+		t.Fatalf("expected GET result after abandoned GET - value:%s error:%s timedout:%t", v, e, timedout)
+	}
+}
+
+func TestWithContextTransactions(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+	async, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer async.Quit()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tx, e := client.WithContext(ctx).Multi()
+	if e != nil {
+		t.Fatalf("Multi - %s", e)
+	}
+	if e := tx.Watch("foo"); e != nil {
+		t.Fatalf("Watch - %s", e)
+	}
+	tx.Queue(&GET, []byte("foo"))
+	if results, e := tx.Exec(); e != nil || len(results) != 1 {
+		t.Errorf("Exec - results:%d error:%s", len(results), e)
+	}
+
+	// pipelined and watched
+	for _, watch := range []bool{false, true} {
+		atx, e := async.WithContext(ctx).Multi()
+		if e != nil {
+			t.Fatalf("async Multi - %s", e)
+		}
+		fget, _ := atx.Get("foo")
+		if watch {
+			if e := atx.Watch("foo"); e != nil {
+				t.Fatalf("async Watch - %s", e)
+			}
+		}
+		status, e := atx.Exec()
+		if e != nil {
+			t.Fatalf("async Exec (watched:%t) - %s", watch, e)
+		}
+		if ok, e := status.Get(); e != nil || !ok {
+			t.Errorf("async Exec (watched:%t) - ok:%t error:%s", watch, ok, e)
+		}
+		if _, e := fget.Get(); e != nil {
+			t.Errorf("GET in async transaction (watched:%t) - %s", watch, e)
+		}
+	}
+
+	cancel()
+	tx, _ = client.WithContext(ctx).Multi()
+	_, e = tx.Exec()
+	assertContextError(t, "Exec after cancel", e, context.Canceled)
+	atx, _ := async.WithContext(ctx).Multi()
+	atx.Get("foo")
+	_, e = atx.Exec()
+	assertContextError(t, "async Exec after cancel", e, context.Canceled)
+	fstreams, e := async.WithContext(ctx).XreadBlock(map[string]string{"s": "$"}, 0, time.Second)
+	if e != nil {
+		t.Fatalf("XreadBlock - %s", e)
+	}
+	_, e = fstreams.Get()
+	assertContextError(t, "XreadBlock after cancel", e, context.Canceled)
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_cxt(t *testing.T) {
+	log.Println("-- context test completed")
+}
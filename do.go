@@ -0,0 +1,163 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Do - arbitrary commands
+// ----------------------------------------------------------------------------
+
+// Commands that are not modeled by this package are sent using an ad-hoc
+// Command with a REPLY response type.  The reply is then decoded purely per
+// the wire and returned as a (possibly nested) Reply.
+
+// Implementation of SyncConnection.Do
+func (c *connHdl) Do(name string, args ...interface{}) (*Reply, Error) {
+	return serviceDo(c, name, args)
+}
+
+// Implementation of SyncConnection.Do
+func (p *connPool) Do(name string, args ...interface{}) (*Reply, Error) {
+	return serviceDo(p, name, args)
+}
+
+// Implementation of AsyncConnection.Do
+func (c *asyncConnHdl) Do(name string, args ...interface{}) (FutureReply, Error) {
+	return queueDo(c, name, args)
+}
+
+// Implementation of AsyncConnection.Do - queues the command in the transaction.
+func (tx *asyncTransaction) Do(name string, args ...interface{}) (FutureReply, Error) {
+	return queueDo(tx, name, args)
+}
+
+// Implementation of Transaction.Do - queues the command in the transaction.
+func (tx *syncTransaction) Do(name string, args ...interface{}) Error {
+	cmd, bargs, err := newDoRequest(name, args)
+	if err != nil {
+		return err
+	}
+	return tx.queue(cmd, bargs, nil)
+}
+
+func serviceDo(c SyncConnection, name string, args []interface{}) (result *Reply, err Error) {
+	cmd, bargs, err := newDoRequest(name, args)
+	if err != nil {
+		return nil, err
+	}
+	var resp Response
+	resp, err = c.ServiceRequest(cmd, bargs)
+	if err == nil {
+		result = resp.GetReply()
+	}
+	return result, err
+}
+
+func queueDo(c AsyncConnection, name string, args []interface{}) (result FutureReply, err Error) {
+	cmd, bargs, err := newDoRequest(name, args)
+	if err != nil {
+		return nil, err
+	}
+	var resp *PendingResponse
+	resp, err = c.QueueRequest(cmd, bargs)
+	if err == nil {
+		result = resp.future.(FutureReply)
+	}
+	return result, err
+}
+
+// Creates the ad-hoc Command and request args for Do.
+func newDoRequest(name string, args []interface{}) (cmd *Command, bargs [][]byte, err Error) {
+	code := strings.TrimSpace(name)
+	if code == "" {
+		return nil, nil, newSystemError("Do - command name is empty")
+	}
+
+	bargs = make([][]byte, len(args))
+	for i, arg := range args {
+		var ok bool
+		if bargs[i], ok = argToBytes(arg); !ok {
+			return nil, nil, newSystemErrorf("Do(%s) - unsupported type %T of arg %d", code, arg, i)
+		}
+	}
+	if changesConnState(code, bargs) {
+		return nil, nil, newSystemErrorf("Do - %s is not supported", code)
+	}
+	return &Command{code, MULTI_KEY, REPLY, false}, bargs, nil
+}
+
+// Returns true if the request changes the state of the connection, e.g.
+// its db or protocol, or whether it replies at all.  Sent via Do, these
+// would silently affect subsequent requests of pooled connections, or
+// corrupt the pipeline shared by the requests of async clients.
+func changesConnState(code string, args [][]byte) bool {
+	words := strings.Fields(strings.ToUpper(code))
+	switch words[0] {
+	case "QUIT", "MONITOR", "SUBSCRIBE", "UNSUBSCRIBE", "PSUBSCRIBE", "PUNSUBSCRIBE",
+		"SELECT", "MULTI", "EXEC", "DISCARD", "WATCH", "UNWATCH", "HELLO", "AUTH", "RESET":
+		return true
+	case "CLIENT":
+		// the subcommand is either part of the name or the first arg
+		if len(words) > 1 {
+			return words[1] == "REPLY"
+		}
+		return len(args) > 0 && strings.ToUpper(string(args[0])) == "REPLY"
+	}
+	return false
+}
+
+// Converts a Do arg to its wire representation.
+func argToBytes(arg interface{}) ([]byte, bool) {
+	switch v := arg.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	case int:
+		return []byte(strconv.Itoa(v)), true
+	case int8:
+		return []byte(strconv.FormatInt(int64(v), 10)), true
+	case int16:
+		return []byte(strconv.FormatInt(int64(v), 10)), true
+	case int32:
+		return []byte(strconv.FormatInt(int64(v), 10)), true
+	case int64:
+		return []byte(strconv.FormatInt(v, 10)), true
+	case uint:
+		return []byte(strconv.FormatUint(uint64(v), 10)), true
+	case uint8:
+		return []byte(strconv.FormatUint(uint64(v), 10)), true
+	case uint16:
+		return []byte(strconv.FormatUint(uint64(v), 10)), true
+	case uint32:
+		return []byte(strconv.FormatUint(uint64(v), 10)), true
+	case uint64:
+		return []byte(strconv.FormatUint(v, 10)), true
+	case float32:
+		return []byte(strconv.FormatFloat(float64(v), 'g', -1, 32)), true
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'g', -1, 64)), true
+	case bool:
+		if v {
+			return []byte("1"), true
+		}
+		return []byte("0"), true
+	}
+	return nil, false
+}
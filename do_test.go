@@ -0,0 +1,133 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"testing"
+	"time"
+)
+
+func TestArgToBytes(t *testing.T) {
+	tests := []struct {
+		arg      interface{}
+		expected string
+	}{
+		{[]byte("foo"), "foo"},
+		{"bar", "bar"},
+		{-42, "-42"},
+		{int64(1) << 40, "1099511627776"},
+		{uint8(7), "7"},
+		{1.5, "1.5"},
+		{float32(0.25), "0.25"},
+		{true, "1"},
+		{false, "0"},
+	}
+	for _, test := range tests {
+		if b, ok := argToBytes(test.arg); !ok || string(b) != test.expected {
+			t.Errorf("argToBytes(%v) - expected %s got %s", test.arg, test.expected, b)
+		}
+	}
+	if _, ok := argToBytes(struct{}{}); ok {
+		t.Error("expected struct arg to be unsupported")
+	}
+}
+
+func TestSyncDo(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	if r, e := client.Do("SET", "foo", 41); e != nil || r.Type != REPLY_STATUS || r.Status != "OK" {
+		t.Fatalf("Do(SET) - reply:%s error:%s", r, e)
+	}
+	if r, e := client.Do("INCR", []byte("foo")); e != nil || r.Type != REPLY_INTEGER || r.Integer != 42 {
+		t.Errorf("Do(INCR) - reply:%s error:%s", r, e)
+	}
+	if r, e := client.Do("GET", "nosuchkey"); e != nil || !r.IsNil() {
+		t.Errorf("Do(GET) - reply:%s error:%s", r, e)
+	}
+	if _, e := client.Do("NOSUCHCOMMAND"); e == nil || !e.IsRedisError() {
+		t.Errorf("expected RedisError - got %s", e)
+	}
+
+	// nested replies
+	r, e := client.Do("EVAL", "return 1", 0)
+	if e != nil || r.Type != REPLY_ARRAY || len(r.Array) != 3 {
+		t.Fatalf("Do(EVAL) - reply:%s error:%s", r, e)
+	}
+	if r.Array[0].Integer != 0 || r.Array[2].Type != REPLY_ARRAY || len(r.Array[2].Array) != 2 {
+		t.Errorf("Do(EVAL) - unexpected reply %s", r)
+	}
+
+	// commands that change the state of the connection
+	for _, cmd := range []string{"QUIT", "subscribe", "", "SELECT", "multi", "EXEC", "WATCH", "HELLO", "CLIENT REPLY"} {
+		if _, e := client.Do(cmd, "foo"); e == nil || e.IsRedisError() {
+			t.Errorf("expected SystemError for Do(%q) - got %s", cmd, e)
+		}
+	}
+	if _, e := client.Do("CLIENT", "reply", "OFF"); e == nil || e.IsRedisError() {
+		t.Errorf("expected SystemError for Do(CLIENT reply OFF) - got %s", e)
+	}
+	if got := changesConnState("CLIENT", [][]byte{[]byte("SETNAME"), []byte("foo")}); got {
+		t.Error("expected CLIENT SETNAME to be supported")
+	}
+	if _, e := client.Do("SET", "foo", struct{}{}); e == nil {
+		t.Error("expected error for unsupported arg type")
+	}
+
+	// in transaction
+	tx, _ := client.Multi()
+	tx.Do("INCR", "counter")
+	if e := tx.Do("MULTI"); e == nil || e.IsRedisError() {
+		t.Errorf("expected SystemError for Do(MULTI) in transaction - got %s", e)
+	}
+	results, e := tx.Exec()
+	if e != nil || len(results) != 1 {
+		t.Fatalf("Exec - results:%d error:%s", len(results), e)
+	}
+	if r := results[0].GetReply(); r.Type != REPLY_INTEGER || r.Integer != 1 {
+		t.Errorf("Do(INCR) in transaction - reply:%s", r)
+	}
+}
+
+func TestAsyncDo(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	client.Do("SET", "foo", "bar")
+	freply, e := client.Do("GET", "foo")
+	if e != nil {
+		t.Fatalf("Do(GET) - %s", e)
+	}
+	if r, e := freply.Get(); e != nil || r.Type != REPLY_BULK || string(r.Bulk) != "bar" {
+		t.Errorf("Do(GET) - reply:%s error:%s", r, e)
+	}
+
+	// in transaction
+	tx, _ := client.Multi()
+	fincr, _ := tx.Do("INCR", "counter")
+	status, _ := tx.Exec()
+	if _, e := status.Get(); e != nil {
+		t.Fatalf("Exec - %s", e)
+	}
+	if r, e := fincr.Get(); e != nil || r.Integer != 1 {
+		t.Errorf("Do(INCR) in transaction - reply:%s error:%s", r, e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_dt(t *testing.T) {
+	log.Println("-- do test completed")
+}
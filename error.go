@@ -19,6 +19,8 @@ import (
 	"log"
 	"net"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // ----------------------------------------------------------------------------
@@ -92,12 +94,14 @@ type RedisError interface {
 }
 
 type redisError struct {
-	msg string
+	msg   string
+	reply string // the error reply of the server
 }
 
 func newRedisError(msg string) Error {
 	e := &redisError{
-		msg: msg,
+		msg:   msg,
+		reply: msg,
 	}
 	return e
 }
@@ -109,6 +113,190 @@ func (e *redisError) Error() string {
 	return fmt.Sprintf("REDIS_ERROR - %s", e.msg)
 }
 
+// See: redis.RedisError#Message()
+func (e *redisError) Message() string { return e.msg }
+
+// Returns the Redis error for the error reply of the server, per its error
+// code, with the given message.  See NoAuthError and WrongPassError.
+func newRedisReplyError(reply string, msg string) Error {
+	e := redisError{msg: msg, reply: reply}
+	switch {
+	case strings.HasPrefix(reply, "NOAUTH"):
+		return &noAuthError{e}
+	case strings.HasPrefix(reply, "WRONGPASS"), strings.HasPrefix(reply, "ERR invalid password"):
+		return &wrongPassError{e}
+	case strings.HasPrefix(reply, "MOVED "), strings.HasPrefix(reply, "ASK "):
+		if re := newRedirectError(reply, msg); re != nil {
+			return re
+		}
+	}
+	return &e
+}
+
+// ----------------------------------------------------------------------
+// Authentication Errors
+// ----------------------------------------------------------------------
+
+// Returned when the Redis server rejects a request (NOAUTH) as the
+// connection is not authenticated, e.g. when no password is specified.
+// Like Redis ERRs, these are user level errors and IsRedisError() is true.
+type NoAuthError interface {
+	Error
+	NoAuth() bool
+}
+
+type noAuthError struct {
+	redisError
+}
+
+// See: redis.NoAuthError#NoAuth()
+func (e *noAuthError) NoAuth() bool { return true }
+
+// Returned when the Redis server rejects the username and password of AUTH
+// (WRONGPASS), or the password (ERR invalid password) of pre ACL servers.
+// Like Redis ERRs, these are user level errors and IsRedisError() is true.
+type WrongPassError interface {
+	Error
+	WrongPass() bool
+}
+
+type wrongPassError struct {
+	redisError
+}
+
+// See: redis.WrongPassError#WrongPass()
+func (e *wrongPassError) WrongPass() bool { return true }
+
+// ----------------------------------------------------------------------
+// Cluster Redirection Errors
+// ----------------------------------------------------------------------
+
+// Returned when a Redis cluster node redirects a request for a key (hash
+// slot) it does not serve, either permanently (MOVED), or for the request
+// only (ASK), while the slot is migrated.  Cluster clients follow the
+// redirects - see NewClusterSynchClientWithSpec.  Like Redis ERRs, these
+// are user level errors and IsRedisError() is true.
+type RedirectError interface {
+	Error
+	// true for ASK, false for MOVED redirects
+	Ask() bool
+	// the hash slot of the key
+	Slot() int
+	// the host:port address of the node serving the slot
+	Addr() string
+}
+
+type redirectError struct {
+	redisError
+	ask  bool
+	slot int
+	addr string
+}
+
+// returns nil if the reply is not a valid MOVED or ASK redirect.
+func newRedirectError(reply string, msg string) Error {
+	fields := strings.Fields(reply)
+	if len(fields) != 3 {
+		return nil
+	}
+	slot, e := strconv.Atoi(fields[1])
+	if e != nil || slot < 0 || slot >= clusterSlots {
+		return nil
+	}
+	return &redirectError{redisError{msg: msg, reply: reply}, fields[0] == "ASK", slot, fields[2]}
+}
+
+// See: redis.RedirectError#Ask()
+func (e *redirectError) Ask() bool { return e.ask }
+
+// See: redis.RedirectError#Slot()
+func (e *redirectError) Slot() int { return e.slot }
+
+// See: redis.RedirectError#Addr()
+func (e *redirectError) Addr() string { return e.addr }
+
+// ----------------------------------------------------------------------
+// Aborted Transaction Errors
+// ----------------------------------------------------------------------
+
+// Returned by Transaction.Exec (and set on the Exec status and the futures
+// of the queued commands of an AsyncTransaction) when the Redis server
+// aborts the transaction because one or more WATCHed keys were modified.  Like Redis ERRs, these are user level
+// errors and IsRedisError() is true.
+type ExecAbortedError interface {
+	Error
+	ExecAborted() bool
+}
+
+type execAbortedError struct {
+	redisError
+}
+
+func newExecAbortedError() Error {
+	e := &execAbortedError{
+		redisError{msg: "EXEC aborted - WATCHed keys were modified"},
+	}
+	return e
+}
+
+// See: redis.ExecAbortedError#ExecAborted()
+func (e *execAbortedError) ExecAborted() bool { return true }
+
+// ----------------------------------------------------------------------
+// Connection Closed Errors
+// ----------------------------------------------------------------------
+
+// Set on the futures of requests pending on an async (or pubsub) connection
+// when the connection is closed, either on Quit, or on an unrecoverable
+// fault, in which case Cause() is the fault.  Also returned for requests
+// made on closed connections.  These are system errors.
+type ConnectionClosedError interface {
+	Error
+	ConnectionClosed() bool
+}
+
+type connectionClosedError struct {
+	systemError
+}
+
+func newConnectionClosedError(cause error) Error {
+	e := &connectionClosedError{
+		systemError{msg: "connection closed", cause: cause},
+	}
+	return e
+}
+
+// See: redis.ConnectionClosedError#ConnectionClosed()
+func (e *connectionClosedError) ConnectionClosed() bool { return true }
+
+// ----------------------------------------------------------------------
+// Spec Errors
+// ----------------------------------------------------------------------
+
+// Returned by ParseURL and SpecFromEnv for invalid settings.  Parameter()
+// names the offending URL parameter (or environment variable) and Cause() is
+// the validation error.  These are system errors.
+type SpecError interface {
+	Error
+	Parameter() string
+}
+
+type specError struct {
+	systemError
+	param string
+}
+
+func newSpecError(param string, cause error) Error {
+	e := &specError{
+		systemError{msg: "invalid " + param, cause: cause},
+		param,
+	}
+	return e
+}
+
+// See: redis.SpecError#Parameter()
+func (e *specError) Parameter() string { return e.param }
+
 // ----------------------------------------------------------------------
 // error handling helper functions
 // ----------------------------------------------------------------------
@@ -145,6 +333,19 @@ func isRedisError(e interface{}) bool {
 	}
 	return false
 }
+
+// returns the innermost cause of (nested) SystemErrors, e.g. the net.Error
+// of io faults, or e if it is not a SystemError (with a cause).
+func rootCause(e error) error {
+	for {
+		syserr, ok := e.(SystemError)
+		if !ok || syserr.Cause() == nil {
+			return e
+		}
+		e = syserr.Cause()
+	}
+}
+
 func isNetError(e interface{}) bool {
 	if e != nil && reflect.TypeOf(e).Implements(reflect.TypeOf((*net.Error)(nil)).Elem()) {
 		return true
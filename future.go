@@ -250,32 +250,86 @@ func (fvc _futureint64) TryGet(ns time.Duration) (int64, Error, bool) {
 	return gv.(int64), err, timedout
 }
 
+// FutureReply (for *Reply)
+//
+type FutureReply interface {
+	//	onError (execErr Error);
+	set(v *Reply)
+	Get() (*Reply, Error)
+	TryGet(timeoutnano time.Duration) (value *Reply, error Error, timedout bool)
+}
+type _futurereply chan result
+
+func newFutureReply() FutureReply        { return make(_futurereply, 1) }
+func (fvc _futurereply) onError(e Error) { send(fvc, nil, e) }
+func (fvc _futurereply) set(v *Reply)    { send(fvc, v, nil) }
+func (fvc _futurereply) Get() (v *Reply, error Error) {
+	gv, err := receive(fvc)
+	if err != nil {
+		return nil, err
+	}
+	return gv.(*Reply), err
+}
+func (fvc _futurereply) TryGet(ns time.Duration) (*Reply, Error, bool) {
+	gv, err, timedout := tryReceive(fvc, ns)
+	if timedout || err != nil {
+		return nil, err, timedout
+	}
+	return gv.(*Reply), err, timedout
+}
+
 // FutureFloat64
 //
 type FutureFloat64 interface {
+	//	onError (execErr Error);
+	set(v float64)
 	Get() (float64, Error)
 	TryGet(timeoutnano time.Duration) (v float64, error Error, timedout bool)
 }
-type _futurefloat64 struct {
-	future FutureBytes
-}
+type _futurefloat64 chan result
 
-func newFutureFloat64(future FutureBytes) FutureFloat64 {
-	return _futurefloat64{future}
-}
+func newFutureFloat64() FutureFloat64      { return make(_futurefloat64, 1) }
+func (fvc _futurefloat64) onError(e Error) { send(fvc, nil, e) }
+func (fvc _futurefloat64) set(v float64)   { send(fvc, v, nil) }
 func (fvc _futurefloat64) Get() (v float64, error Error) {
-	gv, err := fvc.future.Get()
+	gv, err := receive(fvc)
 	if err != nil {
 		return 0, err
 	}
-	v, err = Btof64(gv)
-	return v, nil
+	return gv.(float64), err
 }
 func (fvc _futurefloat64) TryGet(ns time.Duration) (float64, Error, bool) {
-	gv, err, timedout := fvc.future.TryGet(ns)
+	gv, err, timedout := tryReceive(fvc, ns)
 	if timedout || err != nil {
 		return float64(0), err, timedout
 	}
-	v, err := Btof64(gv)
-	return v, nil, timedout
+	return gv.(float64), err, timedout
+}
+
+// FutureBytesMap (for map[string][]byte)
+//
+type FutureBytesMap interface {
+	//	onError (execErr Error);
+	set(v map[string][]byte)
+	Get() (map[string][]byte, Error)
+	TryGet(timeoutnano time.Duration) (value map[string][]byte, error Error, timedout bool)
+}
+type _futurebytesmap chan result
+
+func newFutureBytesMap() FutureBytesMap             { return make(_futurebytesmap, 1) }
+func (fvc _futurebytesmap) onError(e Error)         { send(fvc, nil, e) }
+func (fvc _futurebytesmap) set(v map[string][]byte) { send(fvc, v, nil) }
+func (fvc _futurebytesmap) Get() (v map[string][]byte, error Error) {
+	gv, err := receive(fvc)
+	if err != nil {
+		return nil, err
+	}
+	return gv.(map[string][]byte), err
+}
+func (fvc _futurebytesmap) TryGet(ns time.Duration) (map[string][]byte, Error, bool) {
+	gv, err, timedout := tryReceive(fvc, ns)
+	if timedout || err != nil {
+		return nil, err, timedout
+	}
+	return gv.(map[string][]byte), err, timedout
 }
@@ -0,0 +1,187 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// hash support - see Client.Hmset and MarshalHash
+// -----------------------------------------------------------------------------
+
+// Returns the HMSET args of the key and fields, in order of the fields.
+func hashArgs(key string, fields map[string][]byte) [][]byte {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := [][]byte{[]byte(key)}
+	for _, name := range names {
+		args = append(args, []byte(name), fields[name])
+	}
+	return args
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Returns the hash fields of the exported fields of the struct (or pointer to
+// struct) v, e.g. for Hmset.
+//
+// Fields are named per their `redis:"name"` tag, or else their Go name.
+// Fields tagged `redis:"-"` are skipped, as are fields tagged with the
+// omitempty option (e.g. `redis:"name,omitempty"`) that have a zero value.
+//
+// Supported field types are string, []byte, bool, the integer and float
+// types, and types that implement encoding.TextMarshaler (e.g. time.Time).
+// Numbers and bools are formatted per strconv.
+func MarshalHash(v interface{}) (fields map[string][]byte, err Error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, newSystemErrorf("MarshalHash - expected a struct - got %T", v)
+	}
+	fields = make(map[string][]byte)
+	for _, f := range structFields(rv.Type()) {
+		fv := rv.Field(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		value, e := marshalField(fv)
+		if e != nil {
+			return nil, newSystemErrorWithCause("MarshalHash - field "+f.name, e)
+		}
+		fields[f.name] = value
+	}
+	return fields, nil
+}
+
+// Sets the exported fields of the struct pointed to by v to the values of the
+// hash fields, e.g. of Hgetall.  Struct fields without a hash field are not
+// changed, and hash fields without a struct field are ignored.  See
+// MarshalHash.
+func UnmarshalHash(fields map[string][]byte, v interface{}) Error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return newSystemErrorf("UnmarshalHash - expected a pointer to struct - got %T", v)
+	}
+	rv = rv.Elem()
+	for _, f := range structFields(rv.Type()) {
+		value, ok := fields[f.name]
+		if !ok {
+			continue
+		}
+		if e := unmarshalField(value, rv.Field(f.index)); e != nil {
+			return newSystemErrorWithCause("UnmarshalHash - field "+f.name, e)
+		}
+	}
+	return nil
+}
+
+// a struct field mapped to a hash field.
+type hashField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// Returns the hash fields of the exported fields of the struct type.
+func structFields(t reflect.Type) []hashField {
+	var fields []hashField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		tag := sf.Tag.Get("redis")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, hashField{i, name, opts == "omitempty"})
+	}
+	return fields
+}
+
+func marshalField(v reflect.Value) ([]byte, error) {
+	if v.Type().Implements(textMarshalerType) {
+		return v.Interface().(encoding.TextMarshaler).MarshalText()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+	case reflect.Bool:
+		return []byte(strconv.FormatBool(v.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []byte(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []byte(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return []byte(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func unmarshalField(value []byte, v reflect.Value) (e error) {
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(value)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(value))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.SetBytes(append([]byte(nil), value...))
+	case reflect.Bool:
+		var b bool
+		if b, e = strconv.ParseBool(string(value)); e == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, e = strconv.ParseInt(string(value), 10, v.Type().Bits()); e == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, e = strconv.ParseUint(string(value), 10, v.Type().Bits()); e == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, e = strconv.ParseFloat(string(value), v.Type().Bits()); e == nil {
+			v.SetFloat(f)
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return e
+}
@@ -0,0 +1,253 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeHashes is a minimal in-memory redis of hashes, supporting the hash
+// commands.
+type fakeHashes struct {
+	*fakeServer
+	mutex  sync.Mutex
+	hashes map[string]map[string]string
+}
+
+func newFakeHashes(t *testing.T) *fakeHashes {
+	s := &fakeHashes{hashes: make(map[string]map[string]string)}
+	s.fakeServer = newFakeServer(t, s.handle)
+	return s
+}
+
+func (s *fakeHashes) handle(args []string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if args[0] == "QUIT" {
+		return "+OK\r\n"
+	}
+	hash := s.hashes[args[1]]
+	if hash == nil {
+		hash = make(map[string]string)
+		s.hashes[args[1]] = hash
+	}
+	switch args[0] {
+	case "HSET", "HMSET":
+		for i := 2; i+1 < len(args); i += 2 {
+			hash[args[i]] = args[i+1]
+		}
+		return "+OK\r\n"
+	case "HSETNX":
+		if _, ok := hash[args[2]]; ok {
+			return ":0\r\n"
+		}
+		hash[args[2]] = args[3]
+		return ":1\r\n"
+	case "HGET", "HMGET":
+		reply := "*" + strconv.Itoa(len(args)-2) + "\r\n"
+		for _, field := range args[2:] {
+			if v, ok := hash[field]; ok {
+				reply += bulkReply(v)
+			} else {
+				reply += "$-1\r\n"
+			}
+		}
+		if args[0] == "HGET" {
+			return strings.TrimPrefix(reply, "*1\r\n")
+		}
+		return reply
+	case "HGETALL", "HKEYS", "HVALS":
+		var fields []string
+		for field := range hash {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		var elems []string
+		for _, field := range fields {
+			switch args[0] {
+			case "HGETALL":
+				elems = append(elems, field, hash[field])
+			case "HKEYS":
+				elems = append(elems, field)
+			case "HVALS":
+				elems = append(elems, hash[field])
+			}
+		}
+		reply := "*" + strconv.Itoa(len(elems)) + "\r\n"
+		for _, elem := range elems {
+			reply += bulkReply(elem)
+		}
+		return reply
+	case "HDEL":
+		n := 0
+		for _, field := range args[2:] {
+			if _, ok := hash[field]; ok {
+				delete(hash, field)
+				n++
+			}
+		}
+		return ":" + strconv.Itoa(n) + "\r\n"
+	case "HEXISTS":
+		if _, ok := hash[args[2]]; ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "HLEN":
+		return ":" + strconv.Itoa(len(hash)) + "\r\n"
+	case "HSTRLEN":
+		return ":" + strconv.Itoa(len(hash[args[2]])) + "\r\n"
+	case "HINCRBY":
+		n, _ := strconv.ParseInt(hash[args[2]], 10, 64)
+		by, e := strconv.ParseInt(args[3], 10, 64)
+		if e != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		hash[args[2]] = strconv.FormatInt(n+by, 10)
+		return ":" + hash[args[2]] + "\r\n"
+	case "HINCRBYFLOAT":
+		f, _ := strconv.ParseFloat(hash[args[2]], 64)
+		by, _ := strconv.ParseFloat(args[3], 64)
+		hash[args[2]] = strconv.FormatFloat(f+by, 'g', -1, 64)
+		return bulkReply(hash[args[2]])
+	}
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+func TestHashCommands(t *testing.T) {
+	server := newFakeHashes(t)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	if e := client.Hmset("h", map[string][]byte{"b": []byte("2"), "a": []byte("1")}); e != nil {
+		t.Fatalf("Hmset - %s", e)
+	}
+	if values, e := client.Hmget("h", "a", "missing", "b"); e != nil || len(values) != 3 ||
+		string(values[0]) != "1" || values[1] != nil || string(values[2]) != "2" {
+		t.Errorf("expected HMGET 1 nil 2 - got %q %v", values, e)
+	}
+	if ok, e := client.Hsetnx("h", "a", []byte("x")); e != nil || ok {
+		t.Errorf("expected HSETNX of existing field to fail - got %t %v", ok, e)
+	}
+	if ok, e := client.Hsetnx("h", "c", []byte("abc")); e != nil || !ok {
+		t.Errorf("expected HSETNX of new field - got %t %v", ok, e)
+	}
+	if n, e := client.Hstrlen("h", "c"); e != nil || n != 3 {
+		t.Errorf("expected HSTRLEN 3 - got %d %v", n, e)
+	}
+	if ok, e := client.Hexists("h", "c"); e != nil || !ok {
+		t.Errorf("expected HEXISTS - got %t %v", ok, e)
+	}
+	if n, e := client.Hincrby("h", "a", 41); e != nil || n != 42 {
+		t.Errorf("expected HINCRBY 42 - got %d %v", n, e)
+	}
+	if f, e := client.Hincrbyfloat("h", "b", 0.125); e != nil || f != 2.125 {
+		t.Errorf("expected HINCRBYFLOAT 2.125 - got %f %v", f, e)
+	}
+	if keys, e := client.Hkeys("h"); e != nil || len(keys) != 3 || string(keys[0]) != "a" {
+		t.Errorf("expected HKEYS a b c - got %q %v", keys, e)
+	}
+	if values, e := client.Hvals("h"); e != nil || len(values) != 3 || string(values[2]) != "abc" {
+		t.Errorf("expected HVALS 42 2.125 abc - got %q %v", values, e)
+	}
+	if n, e := client.Hdel("h", "a", "missing"); e != nil || n != 1 {
+		t.Errorf("expected HDEL 1 - got %d %v", n, e)
+	}
+	if n, e := client.Hlen("h"); e != nil || n != 2 {
+		t.Errorf("expected HLEN 2 - got %d %v", n, e)
+	}
+
+	async, e := NewAsynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer async.Quit()
+
+	async.Hmset("g", map[string][]byte{"x": []byte("1")})
+	fincr, _ := async.Hincrby("g", "x", 2)
+	fvals, _ := async.Hmget("g", "x")
+	flen, _ := async.Hlen("g")
+	if n, e := fincr.Get(); e != nil || n != 3 {
+		t.Errorf("expected async HINCRBY 3 - got %d %v", n, e)
+	}
+	if values, e := fvals.Get(); e != nil || len(values) != 1 || string(values[0]) != "3" {
+		t.Errorf("expected async HMGET 3 - got %q %v", values, e)
+	}
+	if n, e := flen.Get(); e != nil || n != 1 {
+		t.Errorf("expected async HLEN 1 - got %d %v", n, e)
+	}
+}
+
+type testSession struct {
+	ID       string    `redis:"id"`
+	User     []byte    `redis:"user"`
+	Visits   int       `redis:"visits"`
+	Score    float64   `redis:"score"`
+	Admin    bool      `redis:"admin,omitempty"`
+	Created  time.Time `redis:"created"`
+	Untagged uint8
+	Skipped  string `redis:"-"`
+	private  string
+}
+
+func TestMarshalHash(t *testing.T) {
+	created := time.Date(2012, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := &testSession{"s1", []byte("joe"), 3, 1.5, false, created, 7, "skipped", "private"}
+
+	fields, e := MarshalHash(s)
+	if e != nil {
+		t.Fatalf("MarshalHash - %s", e)
+	}
+	expected := map[string]string{
+		"id": "s1", "user": "joe", "visits": "3", "score": "1.5",
+		"created": "2012-01-02T03:04:05Z", "Untagged": "7",
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("expected fields %v - got %q", expected, fields)
+	}
+	for name, value := range expected {
+		if string(fields[name]) != value {
+			t.Errorf("field %s - expected %s - got %s", name, value, fields[name])
+		}
+	}
+
+	var read testSession
+	read.Skipped = "unchanged"
+	fields["admin"] = []byte("true")
+	fields["unknown"] = []byte("ignored")
+	if e := UnmarshalHash(fields, &read); e != nil {
+		t.Fatalf("UnmarshalHash - %s", e)
+	}
+	s.Admin, s.Skipped, s.private = true, "unchanged", ""
+	if !reflect.DeepEqual(&read, s) {
+		t.Errorf("expected %v - got %v", s, read)
+	}
+
+	fields["visits"] = []byte("many")
+	if e := UnmarshalHash(fields, &read); e == nil || !strings.Contains(e.Error(), "field visits") {
+		t.Errorf("expected parse error - got %v", e)
+	}
+	if e := UnmarshalHash(fields, read); e == nil {
+		t.Error("expected error for non pointer")
+	}
+	if _, e := MarshalHash(struct{ C chan int }{}); e == nil || !strings.Contains(e.Error(), "unsupported type") {
+		t.Errorf("expected unsupported type error - got %v", e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_hash(t *testing.T) {
+	log.Println("-- hash test completed")
+}
@@ -0,0 +1,203 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// connPool - supports SyncConnection interface
+// ----------------------------------------------------------------------------
+
+// A pool of synchronous connections.
+//
+// Each ServiceRequest checks out a connection, services the request on it,
+// and returns it to the pool.  Connections that raise a SystemError are
+// presumed broken and are closed instead of being returned to the pool.
+//
+// Pool limits are per ConnectionSpec - see MaxIdle, MaxActive, IdleTimeout
+// and WaitOnExhaustion.
+type connPool struct {
+	spec   *ConnectionSpec
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	idle   []*pooledConn // LIFO - most recently used is last
+	active int           // open connections, idle or checked out
+	closed bool
+}
+
+type pooledConn struct {
+	hdl      *connHdl
+	lastUsed time.Time
+}
+
+func newConnPool(spec *ConnectionSpec) *connPool {
+	p := &connPool{spec: spec}
+	p.cond = sync.NewCond(&p.mutex)
+	return p
+}
+
+// Creates a new pooled SyncConnection using the provided ConnectionSpec.
+// Unlike NewSyncConnection, connections are opened on demand and the
+// returned SyncConnection can be safely shared by multiple goroutines.
+//
+// A connection is opened here to verify the spec and it is retained as
+// the first idle connection of the pool.
+func NewPooledSyncConnection(spec *ConnectionSpec) (c SyncConnection, err Error) {
+	p := newConnPool(spec)
+	hdl, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	p.put(hdl, false)
+	return p, nil
+}
+
+// Implementation of SyncConnection.ServiceRequest.
+// QUIT closes the pool and all of its connections.
+func (p *connPool) ServiceRequest(cmd *Command, args [][]byte) (resp Response, err Error) {
+	if cmd == &QUIT {
+		p.close()
+		return
+	}
+
+	hdl, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	resp, err = hdl.ServiceRequest(cmd, args)
+	p.put(hdl, err != nil && !err.IsRedisError())
+
+	return
+}
+
+// Checks out a connection, reusing an idle connection if available.
+// Blocks if pool is exhausted and spec.poolWait is true.
+func (p *connPool) get() (hdl *connHdl, err Error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for {
+		if p.closed {
+			return nil, newSystemError("connection pool is closed")
+		}
+		p.evictIdle()
+		if n := len(p.idle); n > 0 {
+			hdl = p.idle[n-1].hdl
+			p.idle = p.idle[:n-1]
+			if hdl.isStale() {
+				closeConnHdl(hdl)
+				p.active--
+				continue
+			}
+			return hdl, nil
+		}
+		if p.spec.maxActive <= 0 || p.active < p.spec.maxActive {
+			break
+		}
+		if !p.spec.poolWait {
+			return nil, newSystemErrorf("connection pool exhausted - %d active connections", p.active)
+		}
+		p.cond.Wait()
+	}
+
+	// reserve the slot before dialing so that we don't hold the lock
+	// during net io.
+	p.active++
+	p.mutex.Unlock()
+	hdl, err = openConnHdl(p.spec)
+	p.mutex.Lock()
+	if err != nil {
+		p.active--
+		p.cond.Signal()
+	}
+	return
+}
+
+// Returns a checked out connection to the pool.
+// Broken connections and connections in excess of spec.maxIdle are closed.
+func (p *connPool) put(hdl *connHdl, broken bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if broken || p.closed || len(p.idle) >= p.spec.maxIdle {
+		p.active--
+		closeConnHdl(hdl)
+	} else {
+		p.idle = append(p.idle, &pooledConn{hdl, time.Now()})
+	}
+	p.cond.Signal()
+}
+
+// closes idle connections that have exceeded spec.idleTTL.
+// caller must hold the lock.
+func (p *connPool) evictIdle() {
+	if p.spec.idleTTL <= 0 {
+		return
+	}
+	expired := time.Now().Add(-p.spec.idleTTL)
+	n := 0
+	for n < len(p.idle) && p.idle[n].lastUsed.Before(expired) {
+		closeConnHdl(p.idle[n].hdl)
+		p.active--
+		n++
+	}
+	p.idle = p.idle[n:]
+}
+
+// closes all idle connections.  Checked out connections are closed
+// when returned.  Goroutines waiting on an exhausted pool are released.
+func (p *connPool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, pc := range p.idle {
+		closeConnHdl(pc.hdl)
+		p.active--
+	}
+	p.idle = nil
+	p.closed = true
+	p.cond.Broadcast()
+}
+
+// Creates and connects a new connHdl.  See NewSyncConnection.
+func openConnHdl(spec *ConnectionSpec) (hdl *connHdl, err Error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if hdl != nil {
+				closeConnHdl(hdl)
+				hdl = nil
+			}
+			err = connectError(e, "openConnHdl")
+		}
+	}()
+
+	hdl = newConnHdl(spec)
+	hdl.connect()
+	return
+}
+
+// disconnects the connHdl, ignoring any errors.
+func closeConnHdl(hdl *connHdl) {
+	defer func() {
+		if e := recover(); e != nil && debug() {
+			log.Println("closeConnHdl - ignoring error on disconnect: ", e)
+		}
+	}()
+	hdl.disconnect()
+}
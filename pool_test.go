@@ -0,0 +1,144 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"sync"
+	"testing"
+	"time"
+)
+
+func pingHandler(args []string) string {
+	switch args[0] {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		return "$3\r\nbar\r\n"
+	case "BREAK":
+		return ""
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func TestPoolReusesConnections(t *testing.T) {
+	server := newFakeServer(t, pingHandler)
+	defer server.close()
+
+	client, e := NewPooledSynchClientWithSpec(server.spec().MaxIdle(2))
+	if e != nil {
+		t.Fatalf("NewPooledSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if v, e := client.Get("foo"); e != nil || string(v) != "bar" {
+					t.Errorf("Get - value:%s error:%s", v, e)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// serial requests must all be serviced by a single idle connection
+	before := server.connectionCount()
+	for i := 0; i < 10; i++ {
+		if e := client.Ping(); e != nil {
+			t.Fatalf("Ping - %s", e)
+		}
+	}
+	if after := server.connectionCount(); after != before {
+		t.Errorf("expected idle connection reuse - connections before:%d after:%d", before, after)
+	}
+}
+
+func TestPoolExhaustion(t *testing.T) {
+	server := newFakeServer(t, pingHandler)
+	defer server.close()
+
+	spec := server.spec().MaxActive(1).WaitOnExhaustion(false)
+	conn, e := NewPooledSyncConnection(spec)
+	if e != nil {
+		t.Fatalf("NewPooledSyncConnection - %s", e)
+	}
+	pool := conn.(*connPool)
+	defer pool.close()
+
+	hdl, e := pool.get()
+	if e != nil {
+		t.Fatalf("get - %s", e)
+	}
+	if _, e := pool.ServiceRequest(&PING, [][]byte{}); e == nil {
+		t.Error("expected error on exhausted pool")
+	}
+
+	// with wait, request must be serviced once the connection is returned
+	spec.WaitOnExhaustion(true)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		pool.put(hdl, false)
+	}()
+	if _, e := pool.ServiceRequest(&PING, [][]byte{}); e != nil {
+		t.Errorf("expected request to be serviced after wait - %s", e)
+	}
+}
+
+func TestPoolEvictsBrokenConnections(t *testing.T) {
+	server := newFakeServer(t, pingHandler)
+	defer server.close()
+
+	conn, e := NewPooledSyncConnection(server.spec())
+	if e != nil {
+		t.Fatalf("NewPooledSyncConnection - %s", e)
+	}
+	pool := conn.(*connPool)
+	defer pool.close()
+
+	// Redis errors do not evict
+	if _, e := pool.ServiceRequest(&Command{"FOO", NO_ARG, STATUS, false}, [][]byte{}); e == nil || !e.IsRedisError() {
+		t.Fatalf("expected a RedisError - got %s", e)
+	}
+	if n := len(pool.idle); n != 1 {
+		t.Fatalf("expected 1 idle connection - got %d", n)
+	}
+
+	// system errors do
+	if _, e := pool.ServiceRequest(&Command{"BREAK", NO_ARG, STATUS, false}, [][]byte{}); e == nil || e.IsRedisError() {
+		t.Fatalf("expected a SystemError - got %s", e)
+	}
+	if n := len(pool.idle); n != 0 || pool.active != 0 {
+		t.Fatalf("expected broken connection to be evicted - idle:%d active:%d", n, pool.active)
+	}
+
+	// and the pool recovers with a new connection
+	if _, e := pool.ServiceRequest(&PING, [][]byte{}); e != nil {
+		t.Errorf("Ping after eviction - %s", e)
+	}
+}
+
+func TestPoolQuit(t *testing.T) {
+	server := newFakeServer(t, pingHandler)
+	defer server.close()
+
+	client, e := NewPooledSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewPooledSynchClientWithSpec - %s", e)
+	}
+	if e := client.Quit(); e != nil {
+		t.Fatalf("Quit - %s", e)
+	}
+	if e := client.Ping(); e == nil {
+		t.Error("expected error on Ping after Quit")
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_pt(t *testing.T) {
+	log.Println("-- pool test completed")
+}
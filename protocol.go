@@ -25,7 +25,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // ----------------------------------------------------------------------------
@@ -45,6 +49,21 @@ const (
 	true_byte       = byte('1')
 )
 
+// RESP3 special bytes - see ConnectionSpec.RESP3
+const (
+	null_byte     byte = byte('_')
+	bool_byte          = byte('#')
+	double_byte        = byte(',')
+	bignum_byte        = byte('(')
+	blob_err_byte      = byte('!')
+	verbatim_byte      = byte('=')
+	map_byte           = byte('%')
+	set_byte           = byte('~')
+	attr_byte          = byte('|')
+	push_byte          = byte('>')
+	t_byte             = byte('t')
+)
+
 type ctlbytes []byte
 
 var crlf_bytes ctlbytes = ctlbytes{cr_byte, lf_byte}
@@ -64,17 +83,20 @@ func CreateRequestBytes(cmd *Command, args [][]byte) []byte {
 			panic(newSystemErrorf("CreateRequestBytes(%s) - failed to create request buffer", cmd.Code))
 		}
 	}()
-	cmd_bytes := []byte(cmd.Code)
+	// subcommands e.g. SCRIPT LOAD are sent as distinct args
+	cmd_parts := strings.Fields(cmd.Code)
 
 	buffer := bytes.NewBufferString("")
 	buffer.WriteByte(count_byte)
-	buffer.Write([]byte(strconv.Itoa(len(args) + 1)))
-	buffer.Write(crlf_bytes)
-	buffer.WriteByte(size_byte)
-	buffer.Write([]byte(strconv.Itoa(len(cmd_bytes))))
-	buffer.Write(crlf_bytes)
-	buffer.Write(cmd_bytes)
+	buffer.Write([]byte(strconv.Itoa(len(args) + len(cmd_parts))))
 	buffer.Write(crlf_bytes)
+	for _, part := range cmd_parts {
+		buffer.WriteByte(size_byte)
+		buffer.Write([]byte(strconv.Itoa(len(part))))
+		buffer.Write(crlf_bytes)
+		buffer.Write([]byte(part))
+		buffer.Write(crlf_bytes)
+	}
 
 	for _, s := range args {
 		buffer.WriteByte(size_byte)
@@ -106,6 +128,12 @@ func CreateFuture(cmd *Command) (future interface{}) {
 	case VIRTUAL:
 		// REVU - treating virtual futures as FutureBools (always true)
 		future = newFutureBool()
+	case REPLY:
+		future = newFutureReply()
+	case DOUBLE:
+		future = newFutureFloat64()
+	case MAP:
+		future = newFutureBytesMap()
 	}
 	return
 }
@@ -114,7 +142,7 @@ func CreateFuture(cmd *Command) (future interface{}) {
 // based on the command type.
 func SetFutureResult(future interface{}, cmd *Command, r Response) {
 	if r.IsError() {
-		future.(FutureResult).onError(newRedisError(r.GetMessage()))
+		future.(FutureResult).onError(newRedisReplyError(r.GetMessage(), r.GetMessage()))
 	} else {
 		switch cmd.RespType {
 		case BOOLEAN:
@@ -132,10 +160,41 @@ func SetFutureResult(future interface{}, cmd *Command, r Response) {
 		case VIRTUAL:
 			// REVU - OK to treat virtual commands as FutureBool
 			future.(FutureBool).set(true)
+		case REPLY:
+			future.(FutureReply).set(r.GetReply())
+		case DOUBLE:
+			future.(FutureFloat64).set(r.GetDoubleValue())
+		case MAP:
+			future.(FutureBytesMap).set(r.GetMapData())
 		}
 	}
 }
 
+// Returns the Response for the (non error) result value of a future of the
+// command - the inverse of SetFutureResult.
+func futureResponse(cmd *Command, v interface{}) Response {
+	r := new(_response)
+	switch cmd.RespType {
+	case BOOLEAN, STATUS, VIRTUAL:
+		r.boolval = v.(bool)
+	case BULK:
+		r.bulkdata = v.([]byte)
+	case MULTI_BULK:
+		r.multibulkdata = v.([][]byte)
+	case NUMBER:
+		r.numval = v.(int64)
+	case STRING:
+		r.stringval = v.(string)
+	case REPLY:
+		r.reply = v.(*Reply)
+	case DOUBLE:
+		r.doubleval = v.(float64)
+	case MAP:
+		r.mapdata = v.(map[string][]byte)
+	}
+	return r
+}
+
 // ----------------------------------------------------------------------------
 // request processing
 // ----------------------------------------------------------------------------
@@ -151,7 +210,7 @@ func sendRequest(w io.Writer, data []byte) {
 
 	n, e := w.Write(data)
 	if e != nil {
-		panic(newSystemErrorf("%s() - connection Write wrote %d bytes only.", loginfo, n))
+		panic(newSystemErrorWithCause(fmt.Sprintf("%s() - connection Write wrote %d bytes only.", loginfo, n), e))
 	}
 
 	// doc isn't too clear but the underlying netFD may return n<len(data) AND
@@ -175,6 +234,9 @@ type Response interface {
 	GetStringValue() string
 	GetBulkData() []byte
 	GetMultiBulkData() [][]byte
+	GetReply() *Reply
+	GetDoubleValue() float64
+	GetMapData() map[string][]byte
 }
 type _response struct {
 	isError       bool
@@ -184,6 +246,9 @@ type _response struct {
 	stringval     string
 	bulkdata      []byte
 	multibulkdata [][]byte
+	reply         *Reply
+	doubleval     float64
+	mapdata       map[string][]byte
 }
 
 func (r *_response) IsError() bool          { return r.isError }
@@ -195,6 +260,13 @@ func (r *_response) GetBulkData() []byte    { return r.bulkdata }
 func (r *_response) GetMultiBulkData() [][]byte {
 	return r.multibulkdata
 }
+func (r *_response) GetReply() *Reply { return r.reply }
+func (r *_response) GetDoubleValue() float64 {
+	return r.doubleval
+}
+func (r *_response) GetMapData() map[string][]byte {
+	return r.mapdata
+}
 
 // ----------------------------------------------------------------------------
 // response processing
@@ -206,18 +278,46 @@ func (r *_response) GetMultiBulkData() [][]byte {
 // errors as sent from Redis server.  (Note err will be nil in that case)
 //
 // Any errors (whether runtime or bugs) are returned as redis.Error.
+//
+// RESP3 replies are accepted per the response type of the command, e.g. maps
+// (as their keys and values, in turn) for MULTI_BULK, maps for MAP, and
+// doubles for DOUBLE.  Attributes are discarded, except for REPLY, and
+// push frames preceding the reply are discarded.
 func GetResponse(reader *bufio.Reader, cmd *Command) (resp Response, err Error) {
+	return getResponse(reader, cmd, nil)
+}
+
+// GetResponse, with push frames preceding the reply passed to onPush, if
+// not nil.
+func getResponse(reader *bufio.Reader, cmd *Command, onPush func(*Reply)) (resp Response, err Error) {
 
 	defer func() {
 		err = onRecover(recover(), "GetResponse")
 	}()
 
 	buf := readToCRLF(reader)
+	for buf[0] == push_byte {
+		push := readReply(reader, buf)
+		if onPush != nil {
+			onPush(push)
+		}
+		buf = readToCRLF(reader)
+	}
+
+	var attrs *Reply
+	if buf[0] == attr_byte {
+		attrs = readAggregate(reader, buf, REPLY_MAP)
+		buf = readToCRLF(reader)
+	}
 
 	// Redis error
-	if buf[0] == err_byte {
+	switch buf[0] {
+	case err_byte:
 		resp = &_response{msg: string(buf[1:]), isError: true}
 		return
+	case blob_err_byte:
+		resp = &_response{msg: string(readBulk(reader, buf)), isError: true}
+		return
 	}
 
 	switch cmd.RespType {
@@ -229,11 +329,17 @@ func GetResponse(reader *bufio.Reader, cmd *Command) (resp Response, err Error)
 		resp = &_response{stringval: string(buf[1:])}
 		return
 	case BOOLEAN:
+		if buf[0] == bool_byte {
+			resp = &_response{boolval: buf[1] == t_byte}
+			return
+		}
 		assertCtlByte(buf, num_byte, "BOOLEAN")
 		resp = &_response{boolval: buf[1] == true_byte}
 		return
 	case NUMBER:
-		assertCtlByte(buf, num_byte, "NUMBER")
+		if buf[0] != bignum_byte {
+			assertCtlByte(buf, num_byte, "NUMBER")
+		}
 		n, e := strconv.ParseInt(string(buf[1:]), 10, 64)
 		assertNotError(e, "in GetResponse - parse error in NUMBER response")
 		resp = &_response{numval: n}
@@ -242,22 +348,95 @@ func GetResponse(reader *bufio.Reader, cmd *Command) (resp Response, err Error)
 		resp = &_response{boolval: true}
 		return
 	case BULK:
-		assertCtlByte(buf, size_byte, "BULK")
-		size, e := strconv.Atoi(string(buf[1:]))
-		assertNotError(e, "in GetResponse - parse error in BULK size")
-		resp = &_response{bulkdata: readBulkData(reader, size)}
+		resp = &_response{bulkdata: readBulk(reader, buf)}
 		return
 	case MULTI_BULK:
-		assertCtlByte(buf, count_byte, "MULTI_BULK")
-		cnt, e := strconv.Atoi(string(buf[1:]))
-		assertNotError(e, "in GetResponse - parse error in MULTIBULK cnt")
-		resp = &_response{multibulkdata: readMultiBulkData(reader, cnt)}
+		resp = &_response{multibulkdata: readMultiBulk(reader, buf)}
+		return
+	case REPLY:
+		reply := readReply(reader, buf)
+		reply.Attrs = attrs
+		resp = &_response{reply: reply}
+		return
+	case DOUBLE:
+		resp = &_response{doubleval: readDouble(reader, buf)}
+		return
+	case MAP:
+		resp = &_response{mapdata: readMap(reader, buf)}
 		return
 	}
 
 	panic(fmt.Errorf("BUG - GetResponse - this should not have been reached"))
 }
 
+// Gets the responses to a MULTI, <cmds>, EXEC request sequence.  All
+// replies of the sequence are consumed.
+//
+// The returned responses are per command and may have (application level)
+// errors as sent from Redis server.  If Redis discards the transaction (e.g.
+// on errors in queued commands) a RedisError is returned, and if EXEC was
+// aborted because WATCHed keys were modified, an ExecAbortedError is returned.
+//
+// Any errors (whether runtime or bugs) are returned as redis.Error.
+func getTransactionResponse(reader *bufio.Reader, cmds []*Command) (resps []Response, err Error) {
+
+	defer func() {
+		if e := onRecover(recover(), "getTransactionResponse"); e != nil {
+			resps, err = nil, e
+		}
+	}()
+
+	// MULTI can only fail if the connection is already in a transaction.
+	buf := readToCRLF(reader)
+	if buf[0] == err_byte {
+		panic(newSystemErrorf("<BUG> MULTI raised error: %s", buf[1:]))
+	}
+
+	// queued commands reply with +QUEUED or ERR
+	queueErrs := make([]Response, len(cmds))
+	qerrcnt := 0
+	for i := range cmds {
+		buf = readToCRLF(reader)
+		if buf[0] == err_byte {
+			queueErrs[i] = &_response{msg: string(buf[1:]), isError: true}
+			qerrcnt++
+		}
+	}
+
+	// EXEC
+	buf = readToCRLF(reader)
+	if buf[0] == err_byte {
+		return nil, newRedisError(string(buf[1:]))
+	}
+	if buf[0] == null_byte {
+		return nil, newExecAbortedError()
+	}
+	assertCtlByte(buf, count_byte, "EXEC")
+	cnt, e := strconv.Atoi(string(buf[1:]))
+	assertNotError(e, "in getTransactionResponse - parse error in EXEC cnt")
+	if cnt < 0 {
+		return nil, newExecAbortedError()
+	}
+	if cnt != len(cmds)-qerrcnt {
+		panic(newSystemErrorf("<BUG> EXEC returned %d results for %d queued commands", cnt, len(cmds)-qerrcnt))
+	}
+
+	// (pre 2.6.5) Redis executes the commands that were queued without errors
+	resps = make([]Response, len(cmds))
+	for i, cmd := range cmds {
+		if queueErrs[i] != nil {
+			resps[i] = queueErrs[i]
+			continue
+		}
+		resp, e := GetResponse(reader, cmd)
+		if e != nil {
+			panic(e)
+		}
+		resps[i] = resp
+	}
+	return
+}
+
 // panics on error (with redis.Error)
 func assertCtlByte(buf []byte, b byte, info string) {
 	if buf[0] != b {
@@ -282,6 +461,9 @@ const (
 	SUBSCRIBE_ACK PubSubMType = iota
 	UNSUBSCRIBE_ACK
 	MESSAGE
+	PSUBSCRIBE_ACK
+	PUNSUBSCRIBE_ACK
+	PMESSAGE
 )
 
 func (t PubSubMType) String() string {
@@ -292,26 +474,44 @@ func (t PubSubMType) String() string {
 		return "UNSUBSCRIBE_ACK"
 	case MESSAGE:
 		return "MESSAGE"
+	case PSUBSCRIBE_ACK:
+		return "PSUBSCRIBE_ACK"
+	case PUNSUBSCRIBE_ACK:
+		return "PUNSUBSCRIBE_ACK"
+	case PMESSAGE:
+		return "PMESSAGE"
 	}
 	panic(newSystemErrorf("BUG - unknown PubSubMType %d", t))
 }
 
 // Conforms to the payload as received from wire.
-// If Type is MESSAGE, then Body will contain a message, and
-// SubscriptionCnt will be -1.
+// If Type is MESSAGE or PMESSAGE, then Body will contain a message, and
+// SubscriptionCnt will be -1.  For PMESSAGE, Pattern is the subscribed
+// pattern and Topic is the channel the message was published to.
 // otherwise, it is expected that SubscriptionCnt will contain subscription-info,
-// e.g. number of subscribed channels, and data will be nil.
+// e.g. number of subscribed channels, and data will be nil.  (For pattern
+// acks, Topic is the pattern.)
+//
+// Received is the (local) time the message was read from the connection.
+//
+// Invalidation messages of CLIENT TRACKING (on topic __redis__:invalidate)
+// are MESSAGEs with the invalidated keys in Keys, and a nil Body.  Keys is
+// nil if all keys are invalidated, e.g. on FLUSHALL.
 type Message struct {
 	Type            PubSubMType
 	Topic           string
+	Pattern         string
 	Body            []byte
 	SubscriptionCnt int
+	Received        time.Time
+	Keys            [][]byte
 }
 
 func (m Message) String() string {
-	return fmt.Sprintf("Message [type:%s topic:%s body:<%s> subcnt:%d]",
+	return fmt.Sprintf("Message [type:%s topic:%s pattern:%s body:<%s> subcnt:%d]",
 		m.Type,
 		m.Topic,
+		m.Pattern,
 		m.Body,
 		m.SubscriptionCnt,
 	)
@@ -322,9 +522,24 @@ func newMessage(topic string, Body []byte) *Message {
 	m.Type = MESSAGE
 	m.Topic = topic
 	m.Body = Body
+	m.SubscriptionCnt = -1
+	m.Received = time.Now()
 	return &m
 }
 
+func newInvalidationMessage(keys [][]byte) *Message {
+	m := newMessage(trackingChannel, nil)
+	m.Keys = keys
+	return m
+}
+
+func newPatternMessage(pattern string, topic string, Body []byte) *Message {
+	m := newMessage(topic, Body)
+	m.Type = PMESSAGE
+	m.Pattern = pattern
+	return m
+}
+
 func newPubSubAck(Type PubSubMType, topic string, scnt int) *Message {
 	m := Message{}
 	m.Type = Type
@@ -352,12 +567,23 @@ func GetPubSubResponse(r *bufio.Reader) (msg *Message, err Error) {
 	}()
 
 	buf := readToCRLF(r)
-	assertCtlByte(buf, count_byte, "PubSub Sequence")
+	if buf[0] != push_byte { // RESP3 pubsub messages are push frames
+		assertCtlByte(buf, count_byte, "PubSub Sequence")
+	}
 
 	num, e := strconv.ParseInt(string(buf[1:len(buf)]), 10, 64)
 	assertNotError(e, "in getPubSubResponse - ParseInt")
-	if num != 3 {
-		panic(fmt.Errorf("<BUG> Expecting *3 for len in response - got %d - buf: %s", num, buf))
+
+	// RESP3 tracking invalidation: >2 invalidate <keys>
+	if num == 2 && buf[0] == push_byte {
+		if kind := string(readMultiBulkData(r, 1)[0]); kind != "invalidate" {
+			panic(fmt.Errorf("<BUG> - unknown push message type %s", kind))
+		}
+		msg = newInvalidationMessage(readMultiBulk(r, readToCRLF(r)))
+		return
+	}
+	if num != 3 && num != 4 {
+		panic(fmt.Errorf("<BUG> Expecting *3 or *4 for len in response - got %d - buf: %s", num, buf))
 	}
 
 	header := readMultiBulkData(r, 2)
@@ -365,12 +591,26 @@ func GetPubSubResponse(r *bufio.Reader) (msg *Message, err Error) {
 	msgtype := string(header[0])
 	subid := string(header[1])
 
+	// pmessage <pattern> <channel> <payload>
+	var channel string
+	if msgtype == "pmessage" {
+		if num != 4 {
+			panic(fmt.Errorf("<BUG> Expecting *4 for len of pmessage - got %d", num))
+		}
+		channel = string(readMultiBulkData(r, 1)[0])
+	}
+
 	buf = readToCRLF(r)
 
+	// RESP2 tracking invalidation: the keys (or nil) are the payload
+	if msgtype == "message" && buf[0] != size_byte {
+		msg = newInvalidationMessage(readMultiBulk(r, buf))
+		return
+	}
+
 	n, e := strconv.Atoi(string(buf[1:]))
 	assertNotError(e, "in getPubSubResponse - pubsub msg seq 3 line - number parse error")
 
-	// TODO - REVU decisiont to conflate P/SUB and P/UNSUB
 	switch msgtype {
 	case "subscribe":
 		assertCtlByte(buf, num_byte, "subscribe")
@@ -381,9 +621,17 @@ func GetPubSubResponse(r *bufio.Reader) (msg *Message, err Error) {
 	case "message":
 		assertCtlByte(buf, size_byte, "MESSAGE")
 		msg = newMessage(subid, readBulkData(r, int(n)))
-	// TODO
-	case "psubscribe", "punsubscribe", "pmessage":
-		panic(fmt.Errorf("<BUG> - pattern-based message type %s not implemented", msgtype))
+	case "psubscribe":
+		assertCtlByte(buf, num_byte, "psubscribe")
+		msg = newPubSubAck(PSUBSCRIBE_ACK, subid, n)
+	case "punsubscribe":
+		assertCtlByte(buf, num_byte, "punsubscribe")
+		msg = newPubSubAck(PUNSUBSCRIBE_ACK, subid, n)
+	case "pmessage":
+		assertCtlByte(buf, size_byte, "PMESSAGE")
+		msg = newPatternMessage(subid, channel, readBulkData(r, int(n)))
+	default:
+		panic(fmt.Errorf("<BUG> - unknown pubsub message type %s", msgtype))
 	}
 
 	return
@@ -436,6 +684,167 @@ func readBulkData(r *bufio.Reader, n int) (data []byte) {
 	return
 }
 
+// Reads a (possibly nested) reply per its wire type.  The first line of
+// the reply is assumed to have been read and is provided in buf.
+//
+// panics on errors (with redis.Error)
+func readReply(r *bufio.Reader, buf []byte) *Reply {
+	switch buf[0] {
+	case ok_byte:
+		return &Reply{Type: REPLY_STATUS, Status: string(buf[1:])}
+	case err_byte:
+		return &Reply{Type: REPLY_ERROR, Status: string(buf[1:])}
+	case num_byte:
+		n, e := strconv.ParseInt(string(buf[1:]), 10, 64)
+		assertNotError(e, "readReply - parse error in integer reply")
+		return &Reply{Type: REPLY_INTEGER, Integer: n}
+	case size_byte:
+		size, e := strconv.Atoi(string(buf[1:]))
+		assertNotError(e, "readReply - parse error in bulk size")
+		if size < 0 {
+			return &Reply{Type: REPLY_NIL}
+		}
+		return &Reply{Type: REPLY_BULK, Bulk: readBulkData(r, size)}
+	case count_byte:
+		return readAggregate(r, buf, REPLY_ARRAY)
+	case null_byte:
+		return &Reply{Type: REPLY_NIL}
+	case bool_byte:
+		return &Reply{Type: REPLY_BOOLEAN, Boolean: buf[1] == t_byte}
+	case double_byte:
+		return &Reply{Type: REPLY_DOUBLE, Double: readDouble(r, buf)}
+	case bignum_byte:
+		n, ok := new(big.Int).SetString(string(buf[1:]), 10)
+		if !ok {
+			panic(newSystemErrorf("readReply - parse error in big number %s", buf[1:]))
+		}
+		return &Reply{Type: REPLY_BIGNUM, BigNumber: n}
+	case blob_err_byte:
+		return &Reply{Type: REPLY_ERROR, Status: string(readBulk(r, buf))}
+	case verbatim_byte:
+		size, e := strconv.Atoi(string(buf[1:]))
+		assertNotError(e, "readReply - parse error in verbatim size")
+		data := readBulkData(r, size)
+		if len(data) < 4 || data[3] != ':' {
+			panic(newSystemErrorf("readReply - malformed verbatim string %q", data))
+		}
+		return &Reply{Type: REPLY_VERBATIM, Format: string(data[:3]), Bulk: data[4:]}
+	case map_byte:
+		return readAggregate(r, buf, REPLY_MAP)
+	case set_byte:
+		return readAggregate(r, buf, REPLY_SET)
+	case push_byte:
+		return readAggregate(r, buf, REPLY_PUSH)
+	case attr_byte:
+		attrs := readAggregate(r, buf, REPLY_MAP)
+		reply := readReply(r, readToCRLF(r))
+		reply.Attrs = attrs
+		return reply
+	}
+	panic(newSystemErrorf("readReply - unexpected control byte '%s'", string(buf[0])))
+}
+
+// Reads the elements of an aggregate (array, map, set, push, or attribute)
+// reply of the given type.  The elements of maps (and attributes) are the
+// keys and values, in turn.
+//
+// panics on errors (with redis.Error)
+func readAggregate(r *bufio.Reader, buf []byte, t ReplyType) *Reply {
+	cnt, e := strconv.Atoi(string(buf[1:]))
+	assertNotError(e, "readReply - parse error in aggregate cnt")
+	if cnt < 0 {
+		return &Reply{Type: REPLY_NIL}
+	}
+	if buf[0] == map_byte || buf[0] == attr_byte {
+		cnt *= 2
+	}
+	elems := make([]*Reply, cnt)
+	for i := range elems {
+		elems[i] = readReply(r, readToCRLF(r))
+	}
+	return &Reply{Type: t, Array: elems}
+}
+
+// Reads the (RESP2 or RESP3) string reply, per the first line in buf, as
+// bytes.  Nil replies are returned as nil, verbatim strings without their
+// format, and numbers, doubles and booleans as text.
+//
+// panics on errors (with redis.Error)
+func readBulk(r *bufio.Reader, buf []byte) []byte {
+	switch buf[0] {
+	case size_byte, blob_err_byte:
+		size, e := strconv.Atoi(string(buf[1:]))
+		assertNotError(e, "readBulk - parse error in bulk size")
+		return readBulkData(r, size)
+	case verbatim_byte:
+		return readReply(r, buf).Bulk
+	case null_byte:
+		return nil
+	case ok_byte, num_byte, double_byte, bignum_byte, bool_byte:
+		return buf[1:]
+	}
+	panic(newSystemErrorf("readBulk - unexpected control byte '%s'", string(buf[0])))
+}
+
+// Reads the (RESP2 or RESP3) multibulk reply, per the first line in buf.
+// The keys and values of map replies are returned in turn.
+//
+// panics on errors (with redis.Error)
+func readMultiBulk(r *bufio.Reader, buf []byte) [][]byte {
+	switch buf[0] {
+	case count_byte, set_byte, push_byte, map_byte:
+	case null_byte:
+		return nil
+	default:
+		assertCtlByte(buf, count_byte, "MULTI_BULK")
+	}
+	cnt, e := strconv.Atoi(string(buf[1:]))
+	assertNotError(e, "in GetResponse - parse error in MULTIBULK cnt")
+	if cnt < 0 {
+		return nil
+	}
+	if buf[0] == map_byte {
+		cnt *= 2
+	}
+	data := make([][]byte, cnt)
+	for i := range data {
+		data[i] = readBulk(r, readToCRLF(r))
+	}
+	return data
+}
+
+// Reads the map reply, per the first line in buf, i.e. a RESP3 map, or (in
+// RESP2) a multibulk of the keys and values in turn.
+//
+// panics on errors (with redis.Error)
+func readMap(r *bufio.Reader, buf []byte) map[string][]byte {
+	data := readMultiBulk(r, buf)
+	if len(data)%2 != 0 {
+		panic(newSystemErrorf("readMap - odd number of elements (%d) in map reply", len(data)))
+	}
+	m := make(map[string][]byte, len(data)/2)
+	for i := 0; i < len(data); i += 2 {
+		m[string(data[i])] = data[i+1]
+	}
+	return m
+}
+
+// Reads the double reply, per the first line in buf, i.e. a RESP3 double,
+// or (in RESP2) a bulk string.  Nil replies are returned as NaN.
+//
+// panics on errors (with redis.Error)
+func readDouble(r *bufio.Reader, buf []byte) float64 {
+	var data []byte
+	if buf[0] == double_byte {
+		data = buf[1:]
+	} else if data = readBulk(r, buf); data == nil {
+		return math.NaN()
+	}
+	f, e := strconv.ParseFloat(string(data), 64)
+	assertNotError(e, "readDouble - parse error in double")
+	return f
+}
+
 // Reads a multibulk response of given expected elements.
 // The initial *num\r\n is assumed to have been consumed.
 //
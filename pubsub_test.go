@@ -0,0 +1,340 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetPubSubResponse(t *testing.T) {
+	wire := "*3\r\n" + bulkReply("psubscribe") + bulkReply("news.*") + ":1\r\n" +
+		pubsubMessage("pmessage", "news.*", "news.tech", "hello") +
+		pubsubMessage("message", "news.tech", "hi")
+
+	reader := bufio.NewReader(bytes.NewBufferString(wire))
+	expected := []Message{
+		{Type: PSUBSCRIBE_ACK, Topic: "news.*", SubscriptionCnt: 1},
+		{Type: PMESSAGE, Topic: "news.tech", Pattern: "news.*", Body: []byte("hello"), SubscriptionCnt: -1},
+		{Type: MESSAGE, Topic: "news.tech", Body: []byte("hi"), SubscriptionCnt: -1},
+	}
+	for _, exp := range expected {
+		msg, e := GetPubSubResponse(reader)
+		if e != nil {
+			t.Fatalf("GetPubSubResponse - %s", e)
+		}
+		if msg.String() != exp.String() {
+			t.Errorf("expected %s - got %s", exp, msg)
+		}
+	}
+}
+
+func receiveMessage(t *testing.T, ch PubSubChannel) []byte {
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+	return nil
+}
+
+func TestPatternSubscriptions(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewPubSubClientWithSpec(server.spec().Protocol(REDIS_PUBSUB))
+	if e != nil {
+		t.Fatalf("NewPubSubClientWithSpec - %s", e)
+	}
+
+	// pattern and literal subscriptions to the same topic coexist
+	if e := client.Subscribe("news.tech", "news.*"); e != nil {
+		t.Fatalf("Subscribe - %s", e)
+	}
+	if e := client.PSubscribe("news.*"); e != nil {
+		t.Fatalf("PSubscribe - %s", e)
+	}
+	tech := client.Messages("news.tech")
+	literal := client.Messages("news.*")
+	pattern := client.PatternMessages("news.*")
+	if tech == nil || literal == nil || pattern == nil {
+		t.Fatal("expected active subscriptions")
+	}
+	if client.PatternMessages("news.tech") != nil {
+		t.Error("expected no pattern subscription to news.tech")
+	}
+
+	server.push(pubsubMessage("message", "news.tech", "m1"))
+	server.push(pubsubMessage("pmessage", "news.*", "news.tech", "m2"))
+	server.push(pubsubMessage("message", "news.*", "m3"))
+	if msg := receiveMessage(t, tech); string(msg) != "m1" {
+		t.Errorf("news.tech - expected m1 got %s", msg)
+	}
+	if msg := receiveMessage(t, pattern); string(msg) != "m2" {
+		t.Errorf("pattern news.* - expected m2 got %s", msg)
+	}
+	if msg := receiveMessage(t, literal); string(msg) != "m3" {
+		t.Errorf("literal news.* - expected m3 got %s", msg)
+	}
+
+	// punsubscribe does not affect the literal subscription
+	if e := client.PUnsubscribe(); e != nil {
+		t.Fatalf("PUnsubscribe - %s", e)
+	}
+	if _, ok := <-pattern; ok {
+		t.Error("expected pattern channel to be closed")
+	}
+	if client.PatternMessages("news.*") != nil {
+		t.Error("expected pattern subscription to be removed")
+	}
+	if len(client.Subscriptions()) != 2 {
+		t.Errorf("expected 2 subscriptions - got %v", client.Subscriptions())
+	}
+
+	if e := client.Unsubscribe("news.tech"); e != nil {
+		t.Fatalf("Unsubscribe - %s", e)
+	}
+	if _, ok := <-tech; ok {
+		t.Error("expected news.tech channel to be closed")
+	}
+	server.push(pubsubMessage("message", "news.*", "m4"))
+	if msg := receiveMessage(t, literal); string(msg) != "m4" {
+		t.Errorf("literal news.* - expected m4 got %s", msg)
+	}
+}
+
+func TestEnvelopeSubscriptions(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewPubSubClientWithSpec(server.spec().Protocol(REDIS_PUBSUB))
+	if e != nil {
+		t.Fatalf("NewPubSubClientWithSpec - %s", e)
+	}
+
+	if e := client.Subscribe("plain"); e != nil {
+		t.Fatalf("Subscribe - %s", e)
+	}
+	if e := client.SubscribeEnvelopes("news.tech"); e != nil {
+		t.Fatalf("SubscribeEnvelopes - %s", e)
+	}
+	if e := client.PSubscribeEnvelopes("news.*"); e != nil {
+		t.Fatalf("PSubscribeEnvelopes - %s", e)
+	}
+	if client.Messages("news.tech") != nil || client.Envelopes("plain") != nil {
+		t.Error("expected a single delivery mode per subscription")
+	}
+	plain := client.Messages("plain")
+	literal := client.Envelopes("news.tech")
+	pattern := client.PatternEnvelopes("news.*")
+
+	before := time.Now()
+	server.push(pubsubMessage("message", "plain", "m0"))
+	server.push(pubsubMessage("message", "news.tech", "m1"))
+	server.push(pubsubMessage("pmessage", "news.*", "news.tech", "m2"))
+
+	if msg := receiveMessage(t, plain); string(msg) != "m0" {
+		t.Errorf("plain - expected m0 got %s", msg)
+	}
+	var msg *Message
+	select {
+	case msg = <-literal:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for envelope")
+	}
+	if msg.Type != MESSAGE || msg.Topic != "news.tech" || msg.Pattern != "" || string(msg.Body) != "m1" {
+		t.Errorf("unexpected envelope %s", msg)
+	}
+	if msg.Received.Before(before) {
+		t.Errorf("unexpected receive time %s", msg.Received)
+	}
+	select {
+	case msg = <-pattern:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for envelope")
+	}
+	if msg.Type != PMESSAGE || msg.Topic != "news.tech" || msg.Pattern != "news.*" || string(msg.Body) != "m2" {
+		t.Errorf("unexpected envelope %s", msg)
+	}
+
+	if e := client.Unsubscribe("news.tech"); e != nil {
+		t.Fatalf("Unsubscribe - %s", e)
+	}
+	if _, ok := <-literal; ok {
+		t.Error("expected envelope channel to be closed")
+	}
+}
+
+func TestPubSubQuit(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewPubSubClientWithSpec(server.spec().Protocol(REDIS_PUBSUB))
+	if e != nil {
+		t.Fatalf("NewPubSubClientWithSpec - %s", e)
+	}
+	if e := client.Subscribe("news.tech", "news.art"); e != nil {
+		t.Fatalf("Subscribe - %s", e)
+	}
+	if e := client.PSubscribeEnvelopes("news.*"); e != nil {
+		t.Fatalf("PSubscribeEnvelopes - %s", e)
+	}
+	literal := client.Messages("news.tech")
+	pattern := client.PatternEnvelopes("news.*")
+
+	// undelivered messages block the response processor, and the
+	// unsubscribe acks are not received
+	for i := 0; i < 200; i++ {
+		server.push(pubsubMessage("message", "news.art", "m"))
+	}
+
+	if e := client.Quit(); e != nil {
+		t.Fatalf("Quit - %s", e)
+	}
+	if _, ok := <-literal; ok {
+		t.Error("expected channel to be closed")
+	}
+	if _, ok := <-pattern; ok {
+		t.Error("expected envelope channel to be closed")
+	}
+	if n := len(client.Subscriptions()); n != 0 {
+		t.Errorf("expected no subscriptions - got %d", n)
+	}
+
+	e = client.Subscribe("news.tech")
+	if _, ok := e.(ConnectionClosedError); !ok {
+		t.Errorf("expected ConnectionClosedError on Subscribe after Quit - got %v", e)
+	}
+	if e := client.Quit(); e != nil {
+		t.Errorf("expected repeated Quit to be a nop - got %s", e)
+	}
+}
+
+// subscribedRedis is a fakeRedis session server that records the
+// (P)SUBSCRIBE requests of each connection, in order of the connections.
+type subscribedRedis struct {
+	*fakeServer
+	mutex      sync.Mutex
+	subscribed [][]string
+}
+
+func newSubscribedRedis(t *testing.T) *subscribedRedis {
+	s := &subscribedRedis{}
+	redis := newFakeRedis()
+	s.fakeServer = newFakeSessionServer(t, func() func(args []string) string {
+		handler := redis.session()
+		s.mutex.Lock()
+		conn := len(s.subscribed)
+		s.subscribed = append(s.subscribed, nil)
+		s.mutex.Unlock()
+
+		return func(args []string) string {
+			if args[0] == "SUBSCRIBE" || args[0] == "PSUBSCRIBE" {
+				s.mutex.Lock()
+				s.subscribed[conn] = append(s.subscribed[conn], strings.Join(args, " "))
+				s.mutex.Unlock()
+			}
+			return handler(args)
+		}
+	})
+	return s
+}
+
+// returns the (P)SUBSCRIBE requests of the i-th connection.
+func (s *subscribedRedis) subscriptions(i int) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if i >= len(s.subscribed) {
+		return nil
+	}
+	subscribed := append([]string{}, s.subscribed[i]...)
+	sort.Strings(subscribed)
+	return subscribed
+}
+
+func TestPubSubResubscribe(t *testing.T) {
+	server := newSubscribedRedis(t)
+	defer server.close()
+
+	spec := reconnectTestSpec(server.fakeServer).Protocol(REDIS_PUBSUB)
+	client, e := NewPubSubClientWithSpec(spec)
+	if e != nil {
+		t.Fatalf("NewPubSubClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	if e := client.Subscribe("news.tech"); e != nil {
+		t.Fatalf("Subscribe - %s", e)
+	}
+	if e := client.PSubscribe("news.*"); e != nil {
+		t.Fatalf("PSubscribe - %s", e)
+	}
+	literal := client.Messages("news.tech")
+	pattern := client.PatternMessages("news.*")
+
+	before := time.Now()
+	server.drop()
+
+	var gap *Gap
+	select {
+	case gap = <-client.Gaps():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for gap notification")
+	}
+	if gap.Start.Before(before) || gap.End.Before(gap.Start) {
+		t.Errorf("unexpected gap period %s - %s", gap.Start, gap.End)
+	}
+	if len(gap.Subscriptions) != 2 {
+		t.Errorf("expected 2 renewed subscriptions - got %v", gap.Subscriptions)
+	}
+
+	// the subscriptions are renewed on the new connection
+	expected := "PSUBSCRIBE news.*,SUBSCRIBE news.tech"
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if server.connectionCount() == 2 && strings.Join(server.subscriptions(1), ",") == expected {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := server.connectionCount(); n != 2 {
+		t.Fatalf("expected 2 connections - got %d", n)
+	}
+	if renewed := strings.Join(server.subscriptions(1), ","); renewed != expected {
+		t.Fatalf("expected %s on the new connection - got %s", expected, renewed)
+	}
+
+	// renewed subscriptions deliver on the original channels
+	received := func(ch PubSubChannel, raw string) bool {
+		for i := 0; i < 10; i++ {
+			server.pushTo(1, raw)
+			select {
+			case msg := <-ch:
+				return string(msg) == "m"
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		return false
+	}
+	if !received(literal, pubsubMessage("message", "news.tech", "m")) {
+		t.Error("expected message on renewed subscription")
+	}
+	if !received(pattern, pubsubMessage("pmessage", "news.*", "news.art", "m")) {
+		t.Error("expected message on renewed pattern subscription")
+	}
+	if n := len(client.Subscriptions()); n != 2 {
+		t.Errorf("expected 2 subscriptions - got %d", n)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_pst(t *testing.T) {
+	log.Println("-- pubsub test completed")
+}
@@ -44,29 +44,74 @@ func NewPubSubClientWithSpec(spec *ConnectionSpec) (PubSubClient, Error) {
 }
 
 func (c *pubsubClient) Messages(topic string) PubSubChannel {
+	if s := c.subscription(SubscriptionKey{topic, false}); s != nil && s.Channel != nil {
+		return s.Channel
+	}
+	return nil
+}
+
+func (c *pubsubClient) PatternMessages(pattern string) PubSubChannel {
+	if s := c.subscription(SubscriptionKey{pattern, true}); s != nil && s.Channel != nil {
+		return s.Channel
+	}
+	return nil
+}
+
+func (c *pubsubClient) Envelopes(topic string) MessageChannel {
+	if s := c.subscription(SubscriptionKey{topic, false}); s != nil && s.Envelopes != nil {
+		return s.Envelopes
+	}
+	return nil
+}
+
+func (c *pubsubClient) PatternEnvelopes(pattern string) MessageChannel {
+	if s := c.subscription(SubscriptionKey{pattern, true}); s != nil && s.Envelopes != nil {
+		return s.Envelopes
+	}
+	return nil
+}
+
+func (c *pubsubClient) Gaps() GapChannel {
+	return c.conn.Gaps()
+}
+
+// returns the subscription once activated, or nil if not subscribed.
+func (c *pubsubClient) subscription(key SubscriptionKey) *Subscription {
 	// REVU - only after impl blocking subscribe in connection#ServiceRequest
-	if s := c.conn.Subscriptions()[topic]; s != nil {
+	if s := c.conn.Subscriptions()[key]; s != nil {
+		if s.IsActive {
+			return s
+		}
 		ok, err := s.activated.Get()
 		if err != nil {
-			panic("BUG")
+			// subscription failed, e.g. connection closed
+			return nil
 		}
 		if ok {
-			return s.Channel
+			return s
 		} else {
 			panic("BUG - isActivated.Get() returned nil err and false future results")
 		}
 	}
-	//	if s := c.conn.Subscriptions()[topic]; s != nil {
-	//		return s.Channel
-	//	}
 	return nil
 }
 
 func (c *pubsubClient) Subscriptions() []string {
 	topics := make([]string, 0)
-	for topic, s := range c.conn.Subscriptions() {
+	for key, s := range c.conn.Subscriptions() {
 		if s.IsActive {
-			topics = append(topics, topic)
+			topics = append(topics, key.Topic)
+		}
+	}
+	return topics
+}
+
+// returns the active literal or pattern subscriptions.
+func (c *pubsubClient) subscriptions(pattern bool) []string {
+	topics := make([]string, 0)
+	for key, s := range c.conn.Subscriptions() {
+		if s.IsActive && key.Pattern == pattern {
+			topics = append(topics, key.Topic)
 		}
 	}
 	return topics
@@ -83,10 +128,41 @@ func (c *pubsubClient) Subscribe(topic string, otherTopics ...string) (err Error
 	return
 }
 
+// REVU - why not async semantics?
+func (c *pubsubClient) PSubscribe(pattern string, otherPatterns ...string) (err Error) {
+	args := appendAndConvert(pattern, otherPatterns...)
+	_, err = c.conn.ServiceRequest(&PSUBSCRIBE, args)
+	return
+}
+
+func (c *pubsubClient) SubscribeEnvelopes(topic string, otherTopics ...string) (err Error) {
+	args := appendAndConvert(topic, otherTopics...)
+	_, err = c.conn.ServiceSubscription(&SUBSCRIBE, ENVELOPE, args)
+	return
+}
+
+func (c *pubsubClient) PSubscribeEnvelopes(pattern string, otherPatterns ...string) (err Error) {
+	args := appendAndConvert(pattern, otherPatterns...)
+	_, err = c.conn.ServiceSubscription(&PSUBSCRIBE, ENVELOPE, args)
+	return
+}
+
 // REVU - why not async semantics?
 func (c *pubsubClient) Unsubscribe(topics ...string) (err Error) {
+	return c.unsubscribe(&UNSUBSCRIBE, topics)
+}
+
+// REVU - why not async semantics?
+func (c *pubsubClient) PUnsubscribe(patterns ...string) (err Error) {
+	return c.unsubscribe(&PUNSUBSCRIBE, patterns)
+}
+
+func (c *pubsubClient) unsubscribe(cmd *Command, topics []string) (err Error) {
 	if topics == nil {
-		topics = c.Subscriptions()
+		topics = c.subscriptions(cmd == &PUNSUBSCRIBE)
+	}
+	if len(topics) == 0 {
+		return
 	}
 	var otherTopics []string = nil
 	if len(topics) > 1 {
@@ -94,14 +170,16 @@ func (c *pubsubClient) Unsubscribe(topics ...string) (err Error) {
 	}
 	args := appendAndConvert(topics[0], otherTopics...)
 	//	var ok bool
-	_, err = c.conn.ServiceRequest(&UNSUBSCRIBE, args)
+	_, err = c.conn.ServiceRequest(cmd, args)
 	//	if err == nil {
 	//		err = NewError(REDIS_ERR, "Subscribe() NOT IMPLEMENTED")
 	//	}
 	return
 }
 
-// REVU - why not async semantics?
-func (c *pubsubClient) Quit() Error {
-	return newSystemError("Quit() NOT IMPLEMENTED")
+// Unsubscribes from all topics and patterns and closes the connection.
+// All subscription channels are closed on return.
+func (c *pubsubClient) Quit() (err Error) {
+	_, err = c.conn.ServiceRequest(&QUIT, [][]byte{})
+	return
 }
@@ -93,7 +93,9 @@
 package redis
 
 import (
+	"context"
 	"flag"
+	"time"
 )
 
 // The synchronous call semantics Client interface.
@@ -111,6 +113,10 @@ import (
 // All methods may return an redis.Error, which is either a Redis error (from
 // the server), or a system error indicating a runtime issue (or bug).
 // See Error in this package for details of its interface.
+//
+// On an io fault (e.g. a read or write timeout per the ConnectionSpec) the
+// connection is closed and the request fails with a system error.  The next
+// request redials the connection.
 type Client interface {
 
 	// Redis QUIT command.
@@ -134,6 +140,11 @@ type Client interface {
 	// Redis KEYS command.
 	Keys(key string) (result []string, err Error)
 
+	// Redis SORT command.
+	// Returns the sorted elements, or if stored (per SortSpec.Store) their
+	// count.  spec may be nil.
+	Sort(key string, spec *SortSpec) (result SortResult, err Error)
+
 	// Redis EXISTS command.
 	Exists(key string) (result bool, err Error)
 
@@ -276,6 +287,7 @@ type Client interface {
 	Zcard(key string) (result int64, err Error)
 
 	// Redis ZSCORE command.
+	// Returns an error if the member (or key) does not exist.
 	Zscore(key string, arg1 []byte) (result float64, err Error)
 
 	// Redis ZRANGE command.
@@ -287,6 +299,65 @@ type Client interface {
 	// Redis ZRANGEBYSCORE command.
 	Zrangebyscore(key string, arg1 float64, arg2 float64) (result [][]byte, err Error)
 
+	// Redis ZADD command, of multiple members.
+	// Returns the number of added (or, per ZADD_CH, changed) members.
+	ZaddMembers(key string, members []ZMember, flags ...ZAddFlag) (result int64, err Error)
+
+	// Redis ZADD INCR command.
+	// Increments the score of the member, and returns the new score, or NaN if
+	// the update was prevented per the flags (e.g. ZADD_NX).
+	ZaddIncr(key string, member []byte, increment float64, flags ...ZAddFlag) (result float64, err Error)
+
+	// Redis ZINCRBY command.
+	Zincrby(key string, increment float64, member []byte) (result float64, err Error)
+
+	// Redis ZRANK command.
+	// Returns -1 if not a member.
+	Zrank(key string, member []byte) (result int64, err Error)
+
+	// Redis ZREVRANK command.
+	// Returns -1 if not a member.
+	Zrevrank(key string, member []byte) (result int64, err Error)
+
+	// Redis ZCOUNT command.
+	Zcount(key string, min ZBound, max ZBound) (result int64, err Error)
+
+	// Redis ZREMRANGEBYSCORE command.
+	// Returns the number of removed members.
+	Zremrangebyscore(key string, min ZBound, max ZBound) (result int64, err Error)
+
+	// Redis ZREMRANGEBYRANK command.
+	// Returns the number of removed members.
+	Zremrangebyrank(key string, start int64, stop int64) (result int64, err Error)
+
+	// Redis ZRANGE WITHSCORES command.
+	ZrangeWithScores(key string, start int64, stop int64) (result []ZMember, err Error)
+
+	// Redis ZREVRANGE WITHSCORES command.
+	ZrevrangeWithScores(key string, start int64, stop int64) (result []ZMember, err Error)
+
+	// Redis ZRANGEBYSCORE command, of the (exclusive or infinite) bounds and
+	// LIMIT of the spec.
+	ZrangebyscoreWithSpec(key string, spec *ZRangeSpec) (result [][]byte, err Error)
+
+	// Redis ZRANGEBYSCORE WITHSCORES command.  See ZrangebyscoreWithSpec.
+	ZrangebyscoreWithScores(key string, spec *ZRangeSpec) (result []ZMember, err Error)
+
+	// Redis ZREVRANGEBYSCORE command.
+	// Returns the members of the range of the spec, from its max to its min.
+	Zrevrangebyscore(key string, spec *ZRangeSpec) (result [][]byte, err Error)
+
+	// Redis ZREVRANGEBYSCORE WITHSCORES command.  See Zrevrangebyscore.
+	ZrevrangebyscoreWithScores(key string, spec *ZRangeSpec) (result []ZMember, err Error)
+
+	// Redis ZUNIONSTORE command.
+	// weights (of the keys, in order) may be nil, and aggregate "" (i.e. SUM).
+	// Returns the number of members of dst.
+	Zunionstore(dst string, keys []string, weights []float64, aggregate ZAggregate) (result int64, err Error)
+
+	// Redis ZINTERSTORE command.  See Zunionstore.
+	Zinterstore(dst string, keys []string, weights []float64, aggregate ZAggregate) (result int64, err Error)
+
 	// Redis HGET command.
 	Hget(key string, hashkey string) (result []byte, err Error)
 
@@ -294,7 +365,100 @@ type Client interface {
 	Hset(key string, hashkey string, arg1 []byte) Error
 
 	// Redis HGETALL command.
-	Hgetall(key string) (result [][]byte, err Error)
+	Hgetall(key string) (result map[string][]byte, err Error)
+
+	// Redis HMSET command.
+	// See MarshalHash to set the fields of a struct.
+	Hmset(key string, fields map[string][]byte) Error
+
+	// Redis HMGET command.
+	// Values of missing fields are nil.
+	Hmget(key string, field string, otherFields ...string) (result [][]byte, err Error)
+
+	// Redis HDEL command.
+	// Returns the number of deleted fields.
+	Hdel(key string, field string, otherFields ...string) (result int64, err Error)
+
+	// Redis HEXISTS command.
+	Hexists(key string, field string) (result bool, err Error)
+
+	// Redis HLEN command.
+	Hlen(key string) (result int64, err Error)
+
+	// Redis HKEYS command.
+	Hkeys(key string) (result [][]byte, err Error)
+
+	// Redis HVALS command.
+	Hvals(key string) (result [][]byte, err Error)
+
+	// Redis HINCRBY command.
+	Hincrby(key string, field string, arg1 int64) (result int64, err Error)
+
+	// Redis HINCRBYFLOAT command.
+	Hincrbyfloat(key string, field string, arg1 float64) (result float64, err Error)
+
+	// Redis HSETNX command.
+	Hsetnx(key string, field string, arg1 []byte) (result bool, err Error)
+
+	// Redis HSTRLEN command.
+	Hstrlen(key string, field string) (result int64, err Error)
+
+	// Redis SCAN command.
+	// Returns an iterator over the keys, which follows the cursor until it
+	// returns 0.  spec may be nil.  See ScanIterator.
+	Scan(spec *ScanSpec) ScanIterator
+
+	// Redis SSCAN command.
+	// Returns an iterator over the members of the set.  See Scan.
+	Sscan(key string, spec *ScanSpec) ScanIterator
+
+	// Redis HSCAN command.
+	// Returns an iterator over the fields and values (in turn) of the hash.
+	// See Scan.
+	Hscan(key string, spec *ScanSpec) ScanIterator
+
+	// Redis ZSCAN command.
+	// Returns an iterator over the members and scores (in turn) of the
+	// sorted set.  See Scan.
+	Zscan(key string, spec *ScanSpec) ScanIterator
+
+	// Redis XADD command.
+	// Adds the entry of the fields to the stream, with the id, or if "", an
+	// ID generated by the server.  trim may be nil.
+	// Returns the ID of the entry.
+	Xadd(key string, id string, fields map[string][]byte, trim *StreamTrimSpec) (result string, err Error)
+
+	// Redis XRANGE command.
+	// Returns the entries from start to end ("-" and "+" for the first and
+	// last entries), at most count if count > 0.
+	Xrange(key string, start, end string, count int64) (result []StreamEntry, err Error)
+
+	// Redis XREVRANGE command.  See Xrange.
+	// Returns the entries from end to start, in reverse order.
+	Xrevrange(key string, end, start string, count int64) (result []StreamEntry, err Error)
+
+	// Redis XLEN command.
+	Xlen(key string) (result int64, err Error)
+
+	// Redis XDEL command.
+	// Returns the number of entries deleted.
+	Xdel(key string, id string, otherIds ...string) (result int64, err Error)
+
+	// Redis XTRIM command.
+	// Returns the number of entries evicted.
+	Xtrim(key string, trim *StreamTrimSpec) (result int64, err Error)
+
+	// Redis XREAD command.
+	// Returns the entries (at most count per stream if count > 0) of the
+	// streams after their IDs (by key), by key of the streams with entries.
+	Xread(streams map[string]string, count int64) (result map[string][]StreamEntry, err Error)
+
+	// Redis XREAD BLOCK command.  See Xread.
+	// Blocks until any of the streams has entries after its ID ("$" for
+	// new entries only) for up to timeout (0 blocks indefinitely), on a
+	// dedicated connection of pooled clients.  Returns nil on timeout.
+	// Not supported by cluster clients.
+	XreadBlock(streams map[string]string, count int64, timeout time.Duration) (result map[string][]StreamEntry, err Error)
 
 	// Redis FLUSHDB command.
 	Flushdb() Error
@@ -317,6 +481,56 @@ type Client interface {
 	// Returns the number of PubSub subscribers that received the message.
 	// OR error if any.
 	Publish(channel string, message []byte) (recieverCout int64, err Error)
+
+	// Redis MULTI command.
+	// Returns a new Transaction using this client's connection.  (Pooled
+	// clients dedicate a pool connection to the transaction.)
+	// See Transaction for details.
+	Multi() (tx Transaction, err Error)
+
+	// Redis EVAL command.
+	// keys and args are available to the script as KEYS and ARGV.
+	// Scripts can return any type of reply, see Reply.
+	Eval(script string, keys []string, args [][]byte) (result *Reply, err Error)
+
+	// Redis EVALSHA command.
+	// See Script for transparent fallback to EVAL.
+	Evalsha(sha1 string, keys []string, args [][]byte) (result *Reply, err Error)
+
+	// Redis SCRIPT LOAD command.
+	// Returns the sha1 of the loaded script.
+	ScriptLoad(script string) (sha1 string, err Error)
+
+	// Redis SCRIPT EXISTS command.
+	ScriptExists(sha1 string, otherSha1s ...string) (result []bool, err Error)
+
+	// Redis SCRIPT FLUSH command.
+	ScriptFlush() Error
+
+	// Sends an arbitrary Redis command, e.g. Do("OBJECT", "ENCODING", "foo").
+	// Supported args are []byte, string, integer, float, and bool types.
+	//
+	// The command name is split on whitespace, so subcommands may be part
+	// of it, e.g. Do("OBJECT ENCODING", "foo").  Commands that change the
+	// state of the connection are not supported: QUIT, MONITOR, the PubSub
+	// commands, SELECT, AUTH, HELLO, RESET, CLIENT REPLY, and the
+	// transaction commands (MULTI, EXEC, DISCARD, WATCH, UNWATCH) - use
+	// Quit, PubSubClient, the ConnectionSpec, and Multi.
+	Do(name string, args ...interface{}) (result *Reply, err Error)
+
+	// Returns a Client bound to ctx, sharing this client's connection(s).
+	// Requests made after ctx is done fail, and requests waiting on their
+	// response return, with a SystemError whose Cause() is ctx.Err().
+	// The abandoned request completes on the connection and its response
+	// is discarded.  Note that the connection of a non-pooled client is
+	// held by the abandoned request until its response arrives, so absent
+	// a ConnectionSpec.ReadTimeout subsequent requests of both clients may
+	// block indefinitely on an unresponsive server.
+	//
+	// Transactions (Multi) and blocking requests (e.g. XreadBlock) of the
+	// returned Client are serviced as by this client, and fail if ctx is
+	// done when they start, but are not otherwise bound to ctx.
+	WithContext(ctx context.Context) Client
 }
 
 // The asynchronous client interface provides asynchronous call semantics with
@@ -335,6 +549,8 @@ type Client interface {
 type AsyncClient interface {
 
 	// Redis QUIT command.
+	// The connection is closed once QUIT is processed.  Requests pending at
+	// that point (or made afterwards) fail with a ConnectionClosedError.
 	Quit() (status FutureBool, err Error)
 
 	// Redis GET command.
@@ -355,6 +571,9 @@ type AsyncClient interface {
 	// Redis KEYS command.
 	Keys(key string) (result FutureKeys, err Error)
 
+	// Redis SORT command.  See Client.Sort.
+	Sort(key string, spec *SortSpec) (result FutureSortResult, err Error)
+
 	// Redis EXISTS command.
 	Exists(key string) (result FutureBool, err Error)
 
@@ -488,6 +707,7 @@ type AsyncClient interface {
 	Zcard(key string) (result FutureInt64, err Error)
 
 	// Redis ZSCORE command.
+	// The future's error is set if the member (or key) does not exist.
 	Zscore(key string, arg1 []byte) (result FutureFloat64, err Error)
 
 	// Redis ZRANGE command.
@@ -499,6 +719,139 @@ type AsyncClient interface {
 	// Redis ZRANGEBYSCORE command.
 	Zrangebyscore(key string, arg1 float64, arg2 float64) (result FutureBytesArray, err Error)
 
+	// Redis ZADD command, of multiple members.
+	ZaddMembers(key string, members []ZMember, flags ...ZAddFlag) (result FutureInt64, err Error)
+
+	// Redis ZADD INCR command.
+	ZaddIncr(key string, member []byte, increment float64, flags ...ZAddFlag) (result FutureFloat64, err Error)
+
+	// Redis ZINCRBY command.
+	Zincrby(key string, increment float64, member []byte) (result FutureFloat64, err Error)
+
+	// Redis ZRANK command.
+	Zrank(key string, member []byte) (result FutureRank, err Error)
+
+	// Redis ZREVRANK command.
+	Zrevrank(key string, member []byte) (result FutureRank, err Error)
+
+	// Redis ZCOUNT command.
+	Zcount(key string, min ZBound, max ZBound) (result FutureInt64, err Error)
+
+	// Redis ZREMRANGEBYSCORE command.
+	Zremrangebyscore(key string, min ZBound, max ZBound) (result FutureInt64, err Error)
+
+	// Redis ZREMRANGEBYRANK command.
+	Zremrangebyrank(key string, start int64, stop int64) (result FutureInt64, err Error)
+
+	// Redis ZRANGE WITHSCORES command.
+	ZrangeWithScores(key string, start int64, stop int64) (result FutureZMembers, err Error)
+
+	// Redis ZREVRANGE WITHSCORES command.
+	ZrevrangeWithScores(key string, start int64, stop int64) (result FutureZMembers, err Error)
+
+	// Redis ZRANGEBYSCORE command, of the (exclusive or infinite) bounds and
+	// LIMIT of the spec.
+	ZrangebyscoreWithSpec(key string, spec *ZRangeSpec) (result FutureBytesArray, err Error)
+
+	// Redis ZRANGEBYSCORE WITHSCORES command.  See ZrangebyscoreWithSpec.
+	ZrangebyscoreWithScores(key string, spec *ZRangeSpec) (result FutureZMembers, err Error)
+
+	// Redis ZREVRANGEBYSCORE command.
+	Zrevrangebyscore(key string, spec *ZRangeSpec) (result FutureBytesArray, err Error)
+
+	// Redis ZREVRANGEBYSCORE WITHSCORES command.  See Zrevrangebyscore.
+	ZrevrangebyscoreWithScores(key string, spec *ZRangeSpec) (result FutureZMembers, err Error)
+
+	// Redis ZUNIONSTORE command.
+	Zunionstore(dst string, keys []string, weights []float64, aggregate ZAggregate) (result FutureInt64, err Error)
+
+	// Redis ZINTERSTORE command.  See Zunionstore.
+	Zinterstore(dst string, keys []string, weights []float64, aggregate ZAggregate) (result FutureInt64, err Error)
+
+	// Redis HGET command.
+	Hget(key string, hashkey string) (result FutureBytes, err Error)
+
+	// Redis HSET command.
+	Hset(key string, hashkey string, arg1 []byte) (status FutureBool, err Error)
+
+	// Redis HGETALL command.
+	Hgetall(key string) (result FutureBytesMap, err Error)
+
+	// Redis HMSET command.
+	Hmset(key string, fields map[string][]byte) (status FutureBool, err Error)
+
+	// Redis HMGET command.
+	Hmget(key string, field string, otherFields ...string) (result FutureBytesArray, err Error)
+
+	// Redis HDEL command.
+	Hdel(key string, field string, otherFields ...string) (result FutureInt64, err Error)
+
+	// Redis HEXISTS command.
+	Hexists(key string, field string) (result FutureBool, err Error)
+
+	// Redis HLEN command.
+	Hlen(key string) (result FutureInt64, err Error)
+
+	// Redis HKEYS command.
+	Hkeys(key string) (result FutureBytesArray, err Error)
+
+	// Redis HVALS command.
+	Hvals(key string) (result FutureBytesArray, err Error)
+
+	// Redis HINCRBY command.
+	Hincrby(key string, field string, arg1 int64) (result FutureInt64, err Error)
+
+	// Redis HINCRBYFLOAT command.
+	Hincrbyfloat(key string, field string, arg1 float64) (result FutureFloat64, err Error)
+
+	// Redis HSETNX command.
+	Hsetnx(key string, field string, arg1 []byte) (result FutureBool, err Error)
+
+	// Redis HSTRLEN command.
+	Hstrlen(key string, field string) (result FutureInt64, err Error)
+
+	// Redis SCAN command.
+	// Fetches the page of keys at the cursor (0 for the first page).  The
+	// iteration is complete once the page's cursor is 0.  spec may be nil.
+	Scan(cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error)
+
+	// Redis SSCAN command.  See Scan.
+	Sscan(key string, cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error)
+
+	// Redis HSCAN command.  See Scan.
+	Hscan(key string, cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error)
+
+	// Redis ZSCAN command.  See Scan.
+	Zscan(key string, cursor uint64, spec *ScanSpec) (result FutureScanPage, err Error)
+
+	// Redis XADD command.  See Client.Xadd.
+	// The future's value is the ID of the entry.
+	Xadd(key string, id string, fields map[string][]byte, trim *StreamTrimSpec) (result FutureBytes, err Error)
+
+	// Redis XRANGE command.  See Client.Xrange.
+	Xrange(key string, start, end string, count int64) (result FutureStreamEntries, err Error)
+
+	// Redis XREVRANGE command.  See Client.Xrevrange.
+	Xrevrange(key string, end, start string, count int64) (result FutureStreamEntries, err Error)
+
+	// Redis XLEN command.
+	Xlen(key string) (result FutureInt64, err Error)
+
+	// Redis XDEL command.
+	Xdel(key string, id string, otherIds ...string) (result FutureInt64, err Error)
+
+	// Redis XTRIM command.
+	Xtrim(key string, trim *StreamTrimSpec) (result FutureInt64, err Error)
+
+	// Redis XREAD command.  See Client.Xread.
+	Xread(streams map[string]string, count int64) (result FutureStreams, err Error)
+
+	// Redis XREAD BLOCK command.  See Client.XreadBlock.
+	// The request is sent on a dedicated connection (rather than the
+	// pipeline, which it would stall) of a pool per client, retained per
+	// spec MaxIdle once the future is set.  Not supported by cluster clients.
+	XreadBlock(streams map[string]string, count int64, timeout time.Duration) (result FutureStreams, err Error)
+
 	// Redis FLUSHDB command.
 	Flushdb() (status FutureBool, err Error)
 
@@ -520,6 +873,120 @@ type AsyncClient interface {
 	// Returns the future for number of PubSub subscribers that received the message.
 	// OR error if any.
 	Publish(channel string, message []byte) (recieverCountFuture FutureInt64, err Error)
+
+	// Redis MULTI command.
+	// Returns a new AsyncTransaction that is executed on this client's
+	// pipeline, or, if keys are watched, on a dedicated connection.
+	// See AsyncTransaction for details.
+	Multi() (tx AsyncTransaction, err Error)
+
+	// Redis EVAL command.
+	// keys and args are available to the script as KEYS and ARGV.
+	// Scripts can return any type of reply, see Reply.
+	Eval(script string, keys []string, args [][]byte) (result FutureReply, err Error)
+
+	// Redis EVALSHA command.
+	// See Script for transparent fallback to EVAL.
+	Evalsha(sha1 string, keys []string, args [][]byte) (result FutureReply, err Error)
+
+	// Redis SCRIPT LOAD command.
+	// The future result is the sha1 of the loaded script.
+	ScriptLoad(script string) (result FutureBytes, err Error)
+
+	// Redis SCRIPT EXISTS command.
+	ScriptExists(sha1 string, otherSha1s ...string) (result FutureBools, err Error)
+
+	// Redis SCRIPT FLUSH command.
+	ScriptFlush() (status FutureBool, err Error)
+
+	// Queues an arbitrary Redis command.  See Client.Do.
+	Do(name string, args ...interface{}) (result FutureReply, err Error)
+
+	// Returns an AsyncClient bound to ctx, sharing this client's connection.
+	// Requests made after ctx is done fail, and the futures of pending
+	// requests are set, with a SystemError whose Cause() is ctx.Err().
+	// Abandoned requests remain in the pipeline and their responses are
+	// discarded.
+	//
+	// Transactions (Multi) and blocking requests (e.g. XreadBlock) of the
+	// returned AsyncClient are serviced as by this client.  The futures
+	// of the transaction's requests and of XreadBlock are not bound to ctx.
+	WithContext(ctx context.Context) AsyncClient
+}
+
+// Redis transaction (MULTI/EXEC) of a Client, with optimistic locking (WATCH).
+//
+// Commands are queued locally until Exec.  On Exec, the MULTI, <commands>, EXEC
+// sequence is sent as a contiguous block on a single connection, and the
+// responses to the queued commands are returned.
+//
+// Watch sends the Redis WATCH command immediately, so (as with Redis) watch
+// the keys before reading the values that the transaction depends on.  If a
+// watched key is modified before EXEC, the transaction is aborted by Redis and
+// Exec returns an ExecAbortedError.
+//
+// A Transaction can only be executed (or discarded) once and it is not safe
+// for concurrent use by multiple go routines.
+type Transaction interface {
+	// Redis WATCH command.
+	// Watches the keys (on the connection dedicated to this transaction)
+	Watch(key string, otherKeys ...string) Error
+
+	// Redis UNWATCH command.
+	Unwatch() Error
+
+	// Queues the command with its args, e.g. Queue(&INCR, []byte("counter")).
+	Queue(cmd *Command, args ...[]byte) Error
+
+	// Queues an arbitrary Redis command - see Client.Do.
+	// Its result is the GetReply() of its response.
+	Do(name string, args ...interface{}) Error
+
+	// Redis MULTI ... EXEC command sequence.
+	// Returns the responses to the queued commands, in order.  The result of
+	// a command is per its Command.RespType, e.g. GetNumberValue() for INCR.
+	// The response of a command that Redis failed (e.g. INCR of a non-integer
+	// value) is an error response (IsError).
+	// Returns the error, if any, e.g. an ExecAbortedError.
+	Exec() (results []Response, err Error)
+
+	// Discards the queued commands and unwatches any watched keys.
+	Discard() Error
+}
+
+// Redis transaction (MULTI/EXEC) of an AsyncClient, with optimistic locking
+// (WATCH).
+//
+// Commands are issued using the AsyncClient methods of the AsyncTransaction
+// and are queued locally until Exec.  The future results of queued commands
+// are set once EXEC is processed.  On Exec, the MULTI, <commands>, EXEC
+// sequence is sent as a contiguous block on a single connection.
+//
+// Watch, and the ExecAbortedError of an aborted transaction, are as with
+// Transaction.  The error is set on Exec's future (and the futures of all
+// queued commands).
+//
+// An AsyncTransaction can only be executed (or discarded) once and it is not
+// safe for concurrent use by multiple go routines.
+type AsyncTransaction interface {
+	// Queue commands - futures are set on Exec.
+	AsyncClient
+
+	// Redis WATCH command.
+	// Watches the keys (on the connection dedicated to this transaction)
+	Watch(key string, otherKeys ...string) Error
+
+	// Redis UNWATCH command.
+	Unwatch() Error
+
+	// Redis MULTI ... EXEC command sequence.
+	// The returned future is set to true if the transaction was executed,
+	// or with the error, if any, e.g. an ExecAbortedError.
+	Exec() (status FutureBool, err Error)
+
+	// Discards the queued commands (their futures are set with an error) and
+	// unwatches any watched keys.
+	Discard() Error
 }
 
 // REVU - ALL THE COMMENS NEEDS REVIEW AND REVISION
@@ -547,7 +1014,9 @@ type AsyncClient interface {
 // clients (either sync or async); see the Publish() method on Client and AsyncClient.
 //
 // Once created, the PubSub client has a message channel (of type <-chan []byte)
-// that the end-user can select, dequeue, etc.
+// that the end-user can select, dequeue, etc.  Alternatively, subscriptions made
+// via SubscribeEnvelopes and PSubscribeEnvelopes deliver Message envelopes (of
+// type <-chan *Message), identifying the channel of each message.
 //
 // This client (very) slightly
 // modifies the native pubsub client's semantics in that it does NOT post the
@@ -558,16 +1027,11 @@ type AsyncClient interface {
 // The subscribe and unsubscribe methods are both blocking (synchronous).  The
 // messages published via the incoming chan are naturally asynchronous.
 //
-// Given the fact that Redis PSUBSCRIBE to channel names that do NOT end in *
-// is identical to SUBSCRIBE to the same, PubSubClient only exposes Subscribe and
-// Unsubscribe methods and supporting implementation are expected to always use
-// the Redis PSUBSCRIBE and PUNSUBSCRIBE commands.  For example, if one issues
-// PSUBSCRIBE foo/* (via telnet) to Redis, and then UNSUBSCRIBE or PUNSUBSCRIBE foo/bar,
-// messages published to foo/bar will still be received in the (telnet) client.  So
-// given that Redis does NOT filter subscriptions and it merely has a 1-1 mapping
-// to subscribed and unsubscribed patterns, PSUBSCRIBE foo is equivalent to SUBSCRIBE foo.
-// These facts inform the design decision to keep the API of PubSubClient simple and
-// not expose explicit pattern or explicit (un)subscription.
+// Pattern subscriptions (PSubscribe) and literal subscriptions (Subscribe) are
+// distinct, per Redis semantics.  For example, if one issues PSUBSCRIBE foo/*
+// and SUBSCRIBE foo/bar, messages published to foo/bar are received on both
+// the PatternMessages("foo/*") and the Messages("foo/bar") channels, and
+// UNSUBSCRIBE foo/bar has no effect on the pattern subscription.
 //
 // Also note that (per Redis semantics) ALL subscribed channels will publish to the
 // single chan exposed by this client.  For practical applications, you will minimally
@@ -581,36 +1045,88 @@ type AsyncClient interface {
 type PubSubClient interface {
 
 	// returns the incoming messages channel for this client, or nil
-	// if no such subscription is active.
+	// if no such subscription is active (or it delivers envelopes).
 	// In event of Unsubscribing from a Redis channel, the
 	// client will close this channel.
 	Messages(topic string) PubSubChannel
 
+	// returns the incoming messages channel of the pattern subscription,
+	// or nil if no such subscription is active.  Messages published to any
+	// channel matching the pattern are forwarded on this channel.
+	// In event of PUnsubscribing from the pattern, the client will close
+	// this channel.
+	PatternMessages(pattern string) PubSubChannel
+
+	// returns the incoming message envelopes channel of the subscription,
+	// or nil if no such subscription is active, or the subscription was
+	// not made via SubscribeEnvelopes.  Unlike Messages, the received
+	// Messages identify the channel (and pattern) and time of receipt.
+	// In event of Unsubscribing from the channel, the client will close
+	// this channel.
+	Envelopes(topic string) MessageChannel
+
+	// As Envelopes, for subscriptions made via PSubscribeEnvelopes.
+	PatternEnvelopes(pattern string) MessageChannel
+
 	// return the subscribed channel ids, whether specificly named, or
 	// pattern based.
 	Subscriptions() []string
 
-	// Redis PSUBSCRIBE command.
+	// returns the gap notification channel.
+	// Per spec, the client reconnects on connection faults and renews all
+	// subscriptions, which remain open.  A Gap is then sent on this channel,
+	// as messages published meanwhile may have been missed.  Notifications
+	// are dropped if the channel is not consumed.
+	// The client closes this channel on Quit or unrecoverable faults.
+	Gaps() GapChannel
+
+	// Redis SUBSCRIBE command.
 	// Subscribes to one or more pubsub channels.
 	// This is a blocking call.
-	// Channel names can be explicit or pattern based (ending in '*')
 	//
-	// Returns the number of currently subscribed channels OR error (if any)
+	// Returns error (if any)
 	//	Subscribe(channel string, otherChannels ...string) (messages PubSubChannel, subscriptionCount int, err Error)
 	Subscribe(topic string, otherTopics ...string) (err Error)
 
-	// Redis PUNSUBSCRIBE command.
+	// Redis PSUBSCRIBE command.
+	// Subscribes to one or more glob-style patterns, e.g. "news.*"
+	// This is a blocking call.
+	//
+	// Returns error (if any)
+	PSubscribe(pattern string, otherPatterns ...string) (err Error)
+
+	// Redis SUBSCRIBE command.
+	// As Subscribe, but messages are delivered as Message envelopes.
+	// See Envelopes.
+	SubscribeEnvelopes(topic string, otherTopics ...string) (err Error)
+
+	// Redis PSUBSCRIBE command.
+	// As PSubscribe, but messages are delivered as Message envelopes.
+	// See PatternEnvelopes.
+	PSubscribeEnvelopes(pattern string, otherPatterns ...string) (err Error)
+
+	// Redis UNSUBSCRIBE command.
 	// unsubscribe from 1 or more pubsub channels.  If arg is nil,
-	// client unsubcribes from ALL subscribed channels.
+	// client unsubcribes from ALL subscribed (literal) channels.
 	// This is a blocking call.
-	// Channel names can be explicit or pattern based (ending in '*')
 	//
-	// Returns the number of currently subscribed channels OR error (if any)
+	// Returns error (if any)
 	Unsubscribe(channels ...string) (err Error)
 
+	// Redis PUNSUBSCRIBE command.
+	// unsubscribe from 1 or more patterns.  If arg is nil, client
+	// unsubcribes from ALL subscribed patterns.
+	// This is a blocking call.
+	//
+	// Returns error (if any)
+	PUnsubscribe(patterns ...string) (err Error)
+
 	// Quit closes the client and client reference can be disposed.
+	// Unsubscribes from all topics and patterns, closes all subscription
+	// channels, and closes the connection.
 	// This is a blocking call.
-	// Returns error, if any, e.g. network issues.
+	// Returns error, if any, e.g. network issues.  Subsequent calls on the
+	// client return a ConnectionClosedError.
 	Quit() Error
 }
 
@@ -618,6 +1134,15 @@ type PubSubClient interface {
 // See PubSubClient interface for details.
 type PubSubChannel <-chan []byte
 
+// MessageChannels are used by clients to forward received PubSub messages from
+// Redis, along with their channel, pattern, and time of receipt.
+// See PubSubClient interface for details.
+type MessageChannel <-chan *Message
+
+// GapChannels are used by clients to notify of reconnects of PubSub
+// connections.  See PubSubClient interface for details.
+type GapChannel <-chan *Gap
+
 // ----------------------------------------------------------------------------
 // package initiatization and internal ops and flags
 // ----------------------------------------------------------------------------
@@ -14,7 +14,10 @@
 
 package redis
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // FutureKeys
 //
@@ -74,6 +77,34 @@ func (fvc _futureinfo) TryGet(ns time.Duration) (map[string]string, Error, bool)
 	return parseInfo(gv), nil, timedout
 }
 
+// FutureBools
+//
+type FutureBools interface {
+	Get() ([]bool, Error)
+	TryGet(timeoutnano time.Duration) (result []bool, error Error, timedout bool)
+}
+type _futurebools struct {
+	future FutureReply
+}
+
+func newFutureBools(future FutureReply) FutureBools {
+	return _futurebools{future}
+}
+func (fvc _futurebools) Get() (v []bool, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return nil, err
+	}
+	return replyToBools(gv), nil
+}
+func (fvc _futurebools) TryGet(ns time.Duration) ([]bool, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return nil, err, timedout
+	}
+	return replyToBools(gv), nil, timedout
+}
+
 // FutureKeyType
 //
 type FutureKeyType interface {
@@ -103,3 +134,226 @@ func (fvc _futurekeytype) TryGet(ns time.Duration) (KeyType, Error, bool) {
 	}
 	return GetKeyType(gv), nil, timedout
 }
+
+// FutureScanPage
+//
+type FutureScanPage interface {
+	Get() (ScanPage, Error)
+	TryGet(timeoutnano time.Duration) (page ScanPage, error Error, timedout bool)
+}
+type _futurescanpage struct {
+	future FutureReply
+}
+
+func newFutureScanPage(future FutureReply) FutureScanPage {
+	return _futurescanpage{future}
+}
+func (fvc _futurescanpage) Get() (v ScanPage, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return v, err
+	}
+	return replyToScanPage(gv)
+}
+func (fvc _futurescanpage) TryGet(ns time.Duration) (ScanPage, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return ScanPage{}, err, timedout
+	}
+	v, err := replyToScanPage(gv)
+	return v, err, false
+}
+
+// FutureZMembers
+//
+type FutureZMembers interface {
+	Get() ([]ZMember, Error)
+	TryGet(timeoutnano time.Duration) (members []ZMember, error Error, timedout bool)
+}
+type _futurezmembers struct {
+	future FutureReply
+}
+
+func newFutureZMembers(future FutureReply) FutureZMembers {
+	return _futurezmembers{future}
+}
+func (fvc _futurezmembers) Get() (v []ZMember, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return nil, err
+	}
+	return replyToZMembers(gv)
+}
+func (fvc _futurezmembers) TryGet(ns time.Duration) ([]ZMember, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return nil, err, timedout
+	}
+	v, err := replyToZMembers(gv)
+	return v, err, false
+}
+
+// FutureRank
+//
+type FutureRank interface {
+	Get() (int64, Error)
+	TryGet(timeoutnano time.Duration) (rank int64, error Error, timedout bool)
+}
+type _futurerank struct {
+	future FutureReply
+}
+
+func newFutureRank(future FutureReply) FutureRank {
+	return _futurerank{future}
+}
+func (fvc _futurerank) Get() (v int64, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return 0, err
+	}
+	return replyToRank(gv), nil
+}
+func (fvc _futurerank) TryGet(ns time.Duration) (int64, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return 0, err, timedout
+	}
+	return replyToRank(gv), nil, timedout
+}
+
+// FutureSortResult
+//
+type FutureSortResult interface {
+	Get() (SortResult, Error)
+	TryGet(timeoutnano time.Duration) (result SortResult, error Error, timedout bool)
+}
+type _futuresortelements struct {
+	future FutureBytesArray
+}
+type _futuresortcount struct {
+	future FutureInt64 // SORT_STORE
+}
+
+func newFutureSortResult(cmd *Command, future interface{}) FutureSortResult {
+	if cmd == &SORT_STORE {
+		return _futuresortcount{future.(FutureInt64)}
+	}
+	return _futuresortelements{future.(FutureBytesArray)}
+}
+func (fvc _futuresortelements) Get() (v SortResult, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return v, err
+	}
+	return SortResult{gv, int64(len(gv))}, nil
+}
+func (fvc _futuresortelements) TryGet(ns time.Duration) (SortResult, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return SortResult{}, err, timedout
+	}
+	return SortResult{gv, int64(len(gv))}, nil, timedout
+}
+func (fvc _futuresortcount) Get() (v SortResult, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return v, err
+	}
+	return SortResult{Count: gv}, nil
+}
+func (fvc _futuresortcount) TryGet(ns time.Duration) (SortResult, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return SortResult{}, err, timedout
+	}
+	return SortResult{Count: gv}, nil, timedout
+}
+
+// FutureStreamEntries
+//
+type FutureStreamEntries interface {
+	Get() ([]StreamEntry, Error)
+	TryGet(timeoutnano time.Duration) (entries []StreamEntry, error Error, timedout bool)
+}
+type _futurestreamentries struct {
+	future FutureReply
+}
+
+func newFutureStreamEntries(future FutureReply) FutureStreamEntries {
+	return _futurestreamentries{future}
+}
+func (fvc _futurestreamentries) Get() (v []StreamEntry, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return nil, err
+	}
+	return replyToStreamEntries(gv)
+}
+func (fvc _futurestreamentries) TryGet(ns time.Duration) ([]StreamEntry, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return nil, err, timedout
+	}
+	v, err := replyToStreamEntries(gv)
+	return v, err, false
+}
+
+// FutureStreams
+//
+type FutureStreams interface {
+	Get() (map[string][]StreamEntry, Error)
+	TryGet(timeoutnano time.Duration) (streams map[string][]StreamEntry, error Error, timedout bool)
+}
+type _futurestreams struct {
+	future FutureReply
+}
+
+func newFutureStreams(future FutureReply) FutureStreams {
+	return _futurestreams{future}
+}
+func (fvc _futurestreams) Get() (v map[string][]StreamEntry, error Error) {
+	gv, err := fvc.future.Get()
+	if err != nil {
+		return nil, err
+	}
+	return replyToStreams(gv)
+}
+func (fvc _futurestreams) TryGet(ns time.Duration) (map[string][]StreamEntry, Error, bool) {
+	gv, err, timedout := fvc.future.TryGet(ns)
+	if timedout || err != nil {
+		return nil, err, timedout
+	}
+	v, err := replyToStreams(gv)
+	return v, err, false
+}
+
+// FutureFloat64 of ZSCORE - see zscore
+//
+type _futurezscore struct {
+	FutureFloat64
+}
+
+func (fvc _futurezscore) Get() (v float64, error Error) {
+	gv, err := fvc.FutureFloat64.Get()
+	if err != nil {
+		return 0, err
+	}
+	return zscore(gv)
+}
+func (fvc _futurezscore) TryGet(ns time.Duration) (float64, Error, bool) {
+	gv, err, timedout := fvc.FutureFloat64.TryGet(ns)
+	if timedout || err != nil {
+		return 0, err, timedout
+	}
+	v, err := zscore(gv)
+	return v, err, false
+}
+
+// Returns the score of the ZSCORE response, or an error if nil (i.e. not a
+// member), which DOUBLE responses return as NaN.
+func zscore(score float64) (float64, Error) {
+	if math.IsNaN(score) {
+		return 0, newSystemError("ZSCORE - nil reply (not a member)")
+	}
+	return score, nil
+}
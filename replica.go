@@ -0,0 +1,187 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// read replica routing - see NewReplicatedClientWithSpecs
+// -----------------------------------------------------------------------------
+
+// delay before a replica that failed a request is tried again
+const replicaRetryPeriod = time.Second
+
+// Returns true if the request can be serviced by a replica, i.e. if the
+// command is read-only.  The SCAN commands are read-only, but their cursors
+// are per server, so all the pages of a scan are read from the master.
+func isReadOnly(cmd *Command) bool {
+	switch cmd {
+	case &SCAN, &SSCAN, &HSCAN, &ZSCAN:
+		return false
+	}
+	return cmd.ReadOnly
+}
+
+// ReadPolicy determines the replica that services a read-only request of a
+// replicated client.  See NewReplicatedClientWithSpecs.
+type ReadPolicy int
+
+const (
+	ROUND_ROBIN   ReadPolicy = iota // replicas in turn
+	LEAST_PENDING                   // the replica with the fewest requests in progress
+)
+
+func (p ReadPolicy) String() string {
+	switch p {
+	case ROUND_ROBIN:
+		return "ReadPolicy:ROUND_ROBIN"
+	case LEAST_PENDING:
+		return "ReadPolicy:LEAST_PENDING"
+	}
+	return "BUG - unknown read policy value"
+}
+
+// Create a new Client of a master and its replicas.  Read-only requests
+// (see Command.ReadOnly) are serviced by a replica selected per the policy,
+// and all other requests (including Do requests, transactions and scans)
+// by the master.  Note that replication is asynchronous, so reads may not
+// reflect recent writes.
+//
+// A replica that fails a request with a (non Redis) error is presumed down
+// for replicaRetryPeriod, and the request is retried on the next replica.
+// If all replicas are down, read-only requests are serviced by the master.
+//
+// Requests are serviced on a connection pool per server (see
+// NewPooledSynchClientWithSpec), and the client can be shared by multiple
+// goroutines.  A connection to the master is opened here to verify its spec;
+// replica connections are opened on demand.
+//
+// Quit() closes all connections.
+func NewReplicatedClientWithSpecs(master *ConnectionSpec, replicas []*ConnectionSpec, policy ReadPolicy) (c Client, err Error) {
+	conn, err := newReplicatedConn(master, replicas, policy)
+	if err != nil {
+		return nil, withError(err)
+	}
+	return &syncClient{conn}, nil
+}
+
+// -----------------------------------------------------------------------------
+// replicatedConn - supports SyncConnection interface
+// -----------------------------------------------------------------------------
+
+// replicatedConn services read-only requests on its replicas, and all other
+// requests on its master.
+type replicatedConn struct {
+	master   *connPool
+	replicas []*replica
+	policy   ReadPolicy
+	next     uint32 // round robin counter
+}
+
+// a replica pool, and its state.
+type replica struct {
+	pool      *connPool
+	pending   int32 // requests in progress
+	downUntil int64 // unix nanos - 0 if up
+}
+
+func newReplicatedConn(master *ConnectionSpec, replicas []*ConnectionSpec, policy ReadPolicy) (*replicatedConn, Error) {
+	if policy != ROUND_ROBIN && policy != LEAST_PENDING {
+		return nil, newSystemErrorf("unknown read policy %d", policy)
+	}
+	c := &replicatedConn{master: newConnPool(master), policy: policy}
+	hdl, err := c.master.get()
+	if err != nil {
+		return nil, err
+	}
+	c.master.put(hdl, false)
+
+	for _, spec := range replicas {
+		c.replicas = append(c.replicas, &replica{pool: newConnPool(spec)})
+	}
+	return c, nil
+}
+
+// Implementation of SyncConnection.ServiceRequest
+func (c *replicatedConn) ServiceRequest(cmd *Command, args [][]byte) (Response, Error) {
+	switch {
+	case cmd == &QUIT:
+		c.quit()
+		return &_response{boolval: true}, nil
+	case !isReadOnly(cmd):
+		return c.master.ServiceRequest(cmd, args)
+	}
+
+	for _, r := range c.selectReplicas() {
+		atomic.AddInt32(&r.pending, 1)
+		resp, err := r.pool.ServiceRequest(cmd, args)
+		atomic.AddInt32(&r.pending, -1)
+		if err == nil || err.IsRedisError() {
+			return resp, err
+		}
+		atomic.StoreInt64(&r.downUntil, time.Now().Add(replicaRetryPeriod).UnixNano())
+	}
+	return c.master.ServiceRequest(cmd, args)
+}
+
+// Implementation of SyncConnection.Do
+func (c *replicatedConn) Do(name string, args ...interface{}) (*Reply, Error) {
+	return serviceDo(c, name, args)
+}
+
+// Returns the replicas that are not down, in order of preference per the
+// policy.  Each call starts at the next replica, so that replicas in turn
+// are preferred (round robin), or break ties (least pending).
+func (c *replicatedConn) selectReplicas() []*replica {
+	n := len(c.replicas)
+	if n == 0 {
+		return nil
+	}
+	start := int(atomic.AddUint32(&c.next, 1) % uint32(n))
+	now := time.Now().UnixNano()
+	up := make([]*replica, 0, n)
+	for i := 0; i < n; i++ {
+		r := c.replicas[(start+i)%n]
+		if atomic.LoadInt64(&r.downUntil) <= now {
+			up = append(up, r)
+		}
+	}
+	if c.policy == LEAST_PENDING {
+		sort.SliceStable(up, func(i, j int) bool {
+			return atomic.LoadInt32(&up[i].pending) < atomic.LoadInt32(&up[j].pending)
+		})
+	}
+	return up
+}
+
+// Closes the pools of the master and all replicas.
+func (c *replicatedConn) quit() {
+	c.master.close()
+	for _, r := range c.replicas {
+		r.pool.close()
+	}
+}
+
+// transactions are serviced by the master.
+func (c *replicatedConn) checkout() (*connHdl, Error) {
+	return c.master.checkout()
+}
+func (c *replicatedConn) release(hdl *connHdl, broken bool) {
+	c.master.release(hdl, broken)
+}
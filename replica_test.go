@@ -0,0 +1,107 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyCommands(t *testing.T) {
+	for _, cmd := range []*Command{&GET, &MGET, &LRANGE, &SMEMBERS, &ZRANGE, &HGETALL} {
+		if !isReadOnly(cmd) {
+			t.Errorf("expected %s to be read-only", cmd.Code)
+		}
+	}
+	for _, cmd := range []*Command{&SET, &DEL, &LPOP, &BLPOP, &SORT, &EVAL, &PUBLISH, &SCAN, &HSCAN} {
+		if isReadOnly(cmd) {
+			t.Errorf("expected %s not to be read-only", cmd.Code)
+		}
+	}
+}
+
+func TestReplicatedClient(t *testing.T) {
+	master := newFakeNode(t, "master")
+	defer master.close()
+	replicas := []*fakeNode{newFakeNode(t, "slave"), newFakeNode(t, "slave")}
+	master.set("foo", "master")
+	for i, replica := range replicas {
+		defer replica.close()
+		replica.set("foo", "replica"+strconv.Itoa(i))
+	}
+
+	client, e := NewReplicatedClientWithSpecs(master.spec(), []*ConnectionSpec{replicas[0].spec(), replicas[1].spec()}, ROUND_ROBIN)
+	if e != nil {
+		t.Fatalf("NewReplicatedClientWithSpecs - %s", e)
+	}
+	defer client.Quit()
+
+	if e := client.Set("bar", []byte("baz")); e != nil || master.value("bar") != "baz" {
+		t.Errorf("expected SET at the master - got %v", e)
+	}
+
+	// replicas in turn
+	get := func() string {
+		v, e := client.Get("foo")
+		if e != nil {
+			t.Fatalf("Get - %s", e)
+		}
+		return string(v)
+	}
+	first := get()
+	second := get()
+	if !strings.HasPrefix(first, "replica") || !strings.HasPrefix(second, "replica") || first == second {
+		t.Errorf("expected GET from both replicas in turn - got %s %s", first, second)
+	}
+	if v := get(); v != first {
+		t.Errorf("expected GET from %s - got %s", first, v)
+	}
+
+	// down replicas are skipped
+	replicas[0].close()
+	for i := 0; i < 4; i++ {
+		if v := get(); v != "replica1" {
+			t.Errorf("expected GET from replica1 - got %s", v)
+		}
+	}
+
+	// and the master services reads if all replicas are down
+	replicas[1].close()
+	for i := 0; i < 2; i++ {
+		if v := get(); v != "master" {
+			t.Errorf("expected GET from the master - got %s", v)
+		}
+	}
+
+	_, e = NewReplicatedClientWithSpecs(master.spec(), nil, ReadPolicy(99))
+	if e == nil || !strings.Contains(e.Error(), "unknown read policy") {
+		t.Errorf("expected read policy error - got %v", e)
+	}
+}
+
+func TestLeastPending(t *testing.T) {
+	c := &replicatedConn{policy: LEAST_PENDING}
+	for i := 0; i < 3; i++ {
+		c.replicas = append(c.replicas, &replica{pool: newConnPool(DefaultSpec())})
+	}
+	c.replicas[0].pending = 2
+	c.replicas[1].pending = 1
+	c.replicas[2].pending = 3
+	for i := 0; i < 3; i++ {
+		if r := c.selectReplicas()[0]; r != c.replicas[1] {
+			t.Errorf("expected the replica with the fewest pending requests - got %d", r.pending)
+		}
+	}
+
+	c.replicas[1].downUntil = 1 << 62
+	if up := c.selectReplicas(); len(up) != 2 || up[0] != c.replicas[0] || up[1] != c.replicas[2] {
+		t.Errorf("expected replicas 0 and 2 in order of pending requests - got %v", up)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_replica(t *testing.T) {
+	log.Println("-- replica test completed")
+}
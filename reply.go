@@ -0,0 +1,171 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Reply
+// ----------------------------------------------------------------------------
+
+// Wire type of a Reply
+type ReplyType int
+
+const (
+	REPLY_STATUS ReplyType = iota
+	REPLY_ERROR
+	REPLY_INTEGER
+	REPLY_BULK
+	REPLY_NIL
+	REPLY_ARRAY
+	// RESP3 - see ConnectionSpec.RESP3
+	REPLY_MAP
+	REPLY_SET
+	REPLY_PUSH
+	REPLY_DOUBLE
+	REPLY_BOOLEAN
+	REPLY_BIGNUM
+	REPLY_VERBATIM
+)
+
+func (t ReplyType) String() string {
+	switch t {
+	case REPLY_STATUS:
+		return "STATUS"
+	case REPLY_ERROR:
+		return "ERROR"
+	case REPLY_INTEGER:
+		return "INTEGER"
+	case REPLY_BULK:
+		return "BULK"
+	case REPLY_NIL:
+		return "NIL"
+	case REPLY_ARRAY:
+		return "ARRAY"
+	case REPLY_MAP:
+		return "MAP"
+	case REPLY_SET:
+		return "SET"
+	case REPLY_PUSH:
+		return "PUSH"
+	case REPLY_DOUBLE:
+		return "DOUBLE"
+	case REPLY_BOOLEAN:
+		return "BOOLEAN"
+	case REPLY_BIGNUM:
+		return "BIGNUM"
+	case REPLY_VERBATIM:
+		return "VERBATIM"
+	}
+	panic(newSystemErrorf("BUG - unknown ReplyType %d", int(t)))
+}
+
+// A self-describing reply, as decoded from the wire, for commands (such as
+// EVAL) whose response type is not known in advance.
+//
+// Only the field corresponding to Type is set:  Status holds the message of
+// both REPLY_STATUS and REPLY_ERROR replies, and Array holds the elements of
+// REPLY_ARRAY replies, which may themselves be arrays.  Both nil bulk and nil
+// multibulk replies are REPLY_NIL.
+//
+// RESP3 replies are likewise typed.  Array holds the elements of REPLY_SET
+// and REPLY_PUSH replies, and the keys and values, in turn, of REPLY_MAP
+// replies (see Map).  REPLY_VERBATIM replies hold their text in Bulk and its
+// format (e.g. "txt") in Format.  RESP3 nulls are REPLY_NIL, and blob errors
+// are REPLY_ERROR.  Attributes of the reply, if any, are in Attrs (a
+// REPLY_MAP).
+//
+// Note that an error reply to the command itself is returned as a RedisError
+// and not as a Reply.  Only the (nested) elements of array replies can be of
+// type REPLY_ERROR.
+type Reply struct {
+	Type    ReplyType
+	Status  string
+	Integer int64
+	Bulk    []byte
+	Array   []*Reply
+
+	Double    float64
+	Boolean   bool
+	BigNumber *big.Int
+	Format    string
+	Attrs     *Reply
+}
+
+// Returns true if reply is nil or of type REPLY_NIL.
+func (r *Reply) IsNil() bool {
+	return r == nil || r.Type == REPLY_NIL
+}
+
+// Returns the values of a REPLY_MAP reply by (the string form of) their keys,
+// and nil otherwise.
+func (r *Reply) Map() map[string]*Reply {
+	if r == nil || r.Type != REPLY_MAP {
+		return nil
+	}
+	m := make(map[string]*Reply, len(r.Array)/2)
+	for i := 0; i+1 < len(r.Array); i += 2 {
+		m[r.Array[i].String()] = r.Array[i+1]
+	}
+	return m
+}
+
+// Returns the RedisError of a REPLY_ERROR reply, and nil otherwise.
+func (r *Reply) Err() Error {
+	if r != nil && r.Type == REPLY_ERROR {
+		return newRedisReplyError(r.Status, r.Status)
+	}
+	return nil
+}
+
+func (r *Reply) String() string {
+	if r == nil {
+		return "(nil)"
+	}
+	switch r.Type {
+	case REPLY_STATUS:
+		return r.Status
+	case REPLY_ERROR:
+		return "(error) " + r.Status
+	case REPLY_INTEGER:
+		return fmt.Sprintf("%d", r.Integer)
+	case REPLY_BULK, REPLY_VERBATIM:
+		return string(r.Bulk)
+	case REPLY_DOUBLE:
+		return strconv.FormatFloat(r.Double, 'g', -1, 64)
+	case REPLY_BOOLEAN:
+		return strconv.FormatBool(r.Boolean)
+	case REPLY_BIGNUM:
+		return r.BigNumber.String()
+	case REPLY_ARRAY, REPLY_SET, REPLY_PUSH:
+		elems := make([]string, len(r.Array))
+		for i, elem := range r.Array {
+			elems[i] = elem.String()
+		}
+		return "[" + strings.Join(elems, " ") + "]"
+	case REPLY_MAP:
+		elems := make([]string, 0, len(r.Array)/2)
+		for i := 0; i+1 < len(r.Array); i += 2 {
+			elems = append(elems, r.Array[i].String()+":"+r.Array[i+1].String())
+		}
+		return "{" + strings.Join(elems, " ") + "}"
+	}
+	return "(nil)"
+}
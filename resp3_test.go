@@ -0,0 +1,286 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"bufio"
+	"log"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readerOf(raw string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(raw))
+}
+
+func TestRESP3Reply(t *testing.T) {
+	raw := "*9\r\n" +
+		"_\r\n" +
+		"#t\r\n" +
+		",3.14\r\n" +
+		"(3492890328409238509324850943850943825024385\r\n" +
+		"=15\r\ntxt:Some string\r\n" +
+		"!21\r\nSYNTAX invalid syntax\r\n" +
+		"%2\r\n+first\r\n:1\r\n$6\r\nsecond\r\n~2\r\n$1\r\na\r\n$1\r\nb\r\n" +
+		"|1\r\n+ttl\r\n:3600\r\n$3\r\nbar\r\n" +
+		">2\r\n$10\r\ninvalidate\r\n*1\r\n$3\r\nfoo\r\n"
+	r := readerOf(raw)
+	reply := readReply(r, readToCRLF(r))
+	if reply.Type != REPLY_ARRAY || len(reply.Array) != 9 {
+		t.Fatalf("expected ARRAY of 9 - got %s %s", reply.Type, reply)
+	}
+	elems := reply.Array
+
+	if !elems[0].IsNil() {
+		t.Errorf("expected nil - got %s", elems[0].Type)
+	}
+	if elems[1].Type != REPLY_BOOLEAN || !elems[1].Boolean {
+		t.Errorf("expected true - got %s %s", elems[1].Type, elems[1])
+	}
+	if elems[2].Type != REPLY_DOUBLE || elems[2].Double != 3.14 {
+		t.Errorf("expected 3.14 - got %s %s", elems[2].Type, elems[2])
+	}
+	if elems[3].Type != REPLY_BIGNUM || elems[3].BigNumber.String() != "3492890328409238509324850943850943825024385" {
+		t.Errorf("expected big number - got %s %s", elems[3].Type, elems[3])
+	}
+	if elems[4].Type != REPLY_VERBATIM || elems[4].Format != "txt" || string(elems[4].Bulk) != "Some string" {
+		t.Errorf("expected verbatim txt - got %s %s:%s", elems[4].Type, elems[4].Format, elems[4].Bulk)
+	}
+	if e := elems[5].Err(); e == nil || !strings.Contains(e.Error(), "SYNTAX invalid syntax") {
+		t.Errorf("expected blob error - got %s", elems[5])
+	}
+
+	m := elems[6].Map()
+	if elems[6].Type != REPLY_MAP || len(m) != 2 || m["first"].Integer != 1 || m["second"].Type != REPLY_SET {
+		t.Errorf("expected MAP - got %s %s", elems[6].Type, elems[6])
+	}
+	if s := m["second"].String(); s != "[a b]" {
+		t.Errorf("expected set [a b] - got %s", s)
+	}
+
+	if string(elems[7].Bulk) != "bar" || elems[7].Attrs.Map()["ttl"].Integer != 3600 {
+		t.Errorf("expected bar with ttl attribute - got %s %s", elems[7], elems[7].Attrs)
+	}
+	if elems[8].Type != REPLY_PUSH || elems[8].String() != "[invalidate [foo]]" {
+		t.Errorf("expected PUSH - got %s %s", elems[8].Type, elems[8])
+	}
+}
+
+func TestRESP3Response(t *testing.T) {
+	bulk := Command{"BULK", KEY, BULK, true}
+	multibulk := Command{"MULTI_BULK", KEY, MULTI_BULK, true}
+
+	// push frames and attributes preceding the reply are consumed
+	raw := ">2\r\n$10\r\ninvalidate\r\n*1\r\n$3\r\nfoo\r\n" +
+		"|1\r\n+ttl\r\n:3600\r\n" +
+		"=7\r\ntxt:bar\r\n"
+	var pushed *Reply
+	resp, e := getResponse(readerOf(raw), &bulk, func(push *Reply) { pushed = push })
+	if e != nil || string(resp.GetBulkData()) != "bar" {
+		t.Errorf("expected bar - got %v %s", resp, e)
+	}
+	if pushed.String() != "[invalidate [foo]]" {
+		t.Errorf("expected invalidate push - got %s", pushed)
+	}
+
+	resp, e = GetResponse(readerOf("_\r\n"), &bulk)
+	if e != nil || resp.GetBulkData() != nil {
+		t.Errorf("expected nil bulk - got %v %s", resp, e)
+	}
+
+	resp, e = GetResponse(readerOf("!10\r\nERR failed\r\n"), &bulk)
+	if e != nil || !resp.IsError() || resp.GetMessage() != "ERR failed" {
+		t.Errorf("expected blob error - got %v %s", resp, e)
+	}
+
+	resp, e = GetResponse(readerOf("#t\r\n"), &SETNX)
+	if e != nil || !resp.GetBooleanValue() {
+		t.Errorf("expected true - got %v %s", resp, e)
+	}
+
+	resp, e = GetResponse(readerOf("(42\r\n"), &INCR)
+	if e != nil || resp.GetNumberValue() != 42 {
+		t.Errorf("expected 42 - got %v %s", resp, e)
+	}
+
+	resp, e = GetResponse(readerOf("~2\r\n$1\r\na\r\n$1\r\nb\r\n"), &multibulk)
+	if e != nil || len(resp.GetMultiBulkData()) != 2 || string(resp.GetMultiBulkData()[1]) != "b" {
+		t.Errorf("expected [a b] - got %v %s", resp, e)
+	}
+
+	for _, raw := range []string{"%1\r\n$1\r\nf\r\n$1\r\nv\r\n", "*2\r\n$1\r\nf\r\n$1\r\nv\r\n"} {
+		resp, e = GetResponse(readerOf(raw), &HGETALL)
+		if e != nil || len(resp.GetMapData()) != 1 || string(resp.GetMapData()["f"]) != "v" {
+			t.Errorf("%q - expected map f:v - got %v %s", raw, resp, e)
+		}
+	}
+
+	doubles := map[string]float64{
+		",1.5\r\n":      1.5,
+		"$3\r\n1.5\r\n": 1.5,
+		",inf\r\n":      math.Inf(1),
+		",-inf\r\n":     math.Inf(-1),
+	}
+	for raw, expected := range doubles {
+		resp, e = GetResponse(readerOf(raw), &ZSCORE)
+		if e != nil || resp.GetDoubleValue() != expected {
+			t.Errorf("%q - expected %g - got %v %s", raw, expected, resp, e)
+		}
+	}
+	for _, raw := range []string{"_\r\n", "$-1\r\n"} {
+		resp, e = GetResponse(readerOf(raw), &ZSCORE)
+		if e != nil || !math.IsNaN(resp.GetDoubleValue()) {
+			t.Errorf("%q - expected NaN - got %v %s", raw, resp, e)
+		}
+	}
+}
+
+// returns a handler that replies in RESP3 (or RESP2 if not negotiated per
+// HELLO 3) to HELLO, HGETALL, ZSCORE (nil of the member "missing"), and
+// GET, which is preceded by an invalidation push frame.
+func resp3Handler() func() func(args []string) string {
+	return func() func(args []string) string {
+		resp3 := false
+		return func(args []string) string {
+			switch args[0] {
+			case "HELLO":
+				if len(args) > 2 && (args[2] != "AUTH" || args[3] != "default" || args[4] != "secret") {
+					return "-WRONGPASS invalid username-password pair or user is disabled.\r\n"
+				}
+				resp3 = args[1] == "3"
+				return "%2\r\n$6\r\nserver\r\n$5\r\nredis\r\n$5\r\nproto\r\n:3\r\n"
+			case "HGETALL":
+				if resp3 {
+					return "%2\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\nb\r\n$1\r\n2\r\n"
+				}
+				return "*4\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\nb\r\n$1\r\n2\r\n"
+			case "ZSCORE":
+				switch {
+				case args[2] == "missing" && resp3:
+					return "_\r\n"
+				case args[2] == "missing":
+					return "$-1\r\n"
+				case resp3:
+					return ",2.5\r\n"
+				}
+				return bulkReply("2.5")
+			case "GET":
+				if resp3 {
+					return ">2\r\n$10\r\ninvalidate\r\n*1\r\n$3\r\nfoo\r\n" + bulkReply("bar")
+				}
+				return bulkReply("bar")
+			case "SUBSCRIBE":
+				return ">3\r\n" + bulkReply("subscribe") + bulkReply(args[1]) + ":1\r\n"
+			case "PING":
+				return "+PONG\r\n"
+			}
+			return "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+	}
+}
+
+func assertHash(t *testing.T, info string, hash map[string][]byte) {
+	if len(hash) != 2 || string(hash["a"]) != "1" || string(hash["b"]) != "2" {
+		t.Errorf("%s - expected map a:1 b:2 - got %q", info, hash)
+	}
+}
+
+func TestRESP3SyncClient(t *testing.T) {
+	server := newFakeSessionServer(t, resp3Handler())
+	defer server.close()
+
+	for _, resp3 := range []bool{false, true} {
+		client, e := NewSynchClientWithSpec(server.spec().RESP3(resp3))
+		if e != nil {
+			t.Fatalf("NewSynchClientWithSpec - %s", e)
+		}
+		hash, e := client.Hgetall("hash")
+		if e != nil {
+			t.Fatalf("Hgetall - %s", e)
+		}
+		assertHash(t, "Hgetall", hash)
+		if score, e := client.Zscore("zset", []byte("m")); e != nil || score != 2.5 {
+			t.Errorf("expected ZSCORE 2.5 - got %g %s", score, e)
+		}
+		if score, e := client.Zscore("zset", []byte("missing")); e == nil {
+			t.Errorf("expected ZSCORE error of missing member (resp3:%t) - got %g", resp3, score)
+		}
+		if v, e := client.Get("foo"); e != nil || string(v) != "bar" {
+			t.Errorf("expected GET bar - got %s %s", v, e)
+		}
+	}
+
+	if _, e := NewSynchClientWithSpec(server.spec().RESP3(true).Password("secret")); e != nil {
+		t.Errorf("expected HELLO AUTH as default user - %s", e)
+	}
+	_, e := NewSynchClientWithSpec(server.spec().RESP3(true).Username("alice").Password("secret"))
+	if _, ok := e.(WrongPassError); !ok {
+		t.Errorf("expected WrongPassError - got %v", e)
+	}
+}
+
+func TestRESP3AsyncClient(t *testing.T) {
+	server := newFakeSessionServer(t, resp3Handler())
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour).RESP3(true))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	fhash, e := client.Hgetall("hash")
+	if e != nil {
+		t.Fatalf("Hgetall - %s", e)
+	}
+	fscore, e := client.Zscore("zset", []byte("m"))
+	if e != nil {
+		t.Fatalf("Zscore - %s", e)
+	}
+	fget, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+
+	hash, e, timedout := fhash.TryGet(5 * time.Second)
+	if timedout || e != nil {
+		t.Fatalf("Hgetall - error:%s timedout:%t", e, timedout)
+	}
+	assertHash(t, "Hgetall", hash)
+	if score, e, timedout := fscore.TryGet(5 * time.Second); timedout || e != nil || score != 2.5 {
+		t.Errorf("expected ZSCORE 2.5 - got %g error:%s timedout:%t", score, e, timedout)
+	}
+	if v, e, timedout := fget.TryGet(5 * time.Second); timedout || e != nil || string(v) != "bar" {
+		t.Errorf("expected GET bar - got %s error:%s timedout:%t", v, e, timedout)
+	}
+	fmissing, _ := client.Zscore("zset", []byte("missing"))
+	if score, e := fmissing.Get(); e == nil {
+		t.Errorf("expected ZSCORE error of missing member - got %g", score)
+	}
+}
+
+func TestRESP3PubSubClient(t *testing.T) {
+	server := newFakeSessionServer(t, resp3Handler())
+	defer server.close()
+
+	client, e := NewPubSubClientWithSpec(server.spec().RESP3(true))
+	if e != nil {
+		t.Fatalf("NewPubSubClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	if e := client.Subscribe("news"); e != nil {
+		t.Fatalf("Subscribe - %s", e)
+	}
+	server.push(">3\r\n" + bulkReply("message") + bulkReply("news") + bulkReply("hello"))
+	if msg := receiveMessage(t, client.Messages("news")); string(msg) != "hello" {
+		t.Errorf("expected hello - got %s", msg)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_resp3(t *testing.T) {
+	log.Println("-- resp3 test completed")
+}
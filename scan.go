@@ -0,0 +1,181 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import "strconv"
+
+// -----------------------------------------------------------------------------
+// SCAN, SSCAN, HSCAN and ZSCAN support
+// -----------------------------------------------------------------------------
+
+// Defines the options of SCAN family requests.  The zero value (or nil)
+// requests all elements, with the server's default COUNT.
+type ScanSpec struct {
+	match    string
+	count    int64
+	typename string
+}
+
+// Creates a new ScanSpec, without options.
+func NewScanSpec() *ScanSpec {
+	return &ScanSpec{}
+}
+
+// Sets the MATCH (glob style) pattern of the elements, and returns the
+// reference.  Note that the pattern is applied after the elements of a page
+// are fetched, so pages may be empty.
+func (s *ScanSpec) Match(pattern string) *ScanSpec {
+	s.match = pattern
+	return s
+}
+
+// Sets the COUNT hint (the amount of work per page), and returns the
+// reference.
+func (s *ScanSpec) Count(count int64) *ScanSpec {
+	s.count = count
+	return s
+}
+
+// Sets the TYPE (e.g. "string", "hash") of the keys, and returns the
+// reference.  SCAN only (Redis 6.0 or later).
+func (s *ScanSpec) Type(typename string) *ScanSpec {
+	s.typename = typename
+	return s
+}
+
+// returns the args of the request for the page at the cursor, for the key
+// (nil for SCAN).
+func (s *ScanSpec) args(key []byte, cursor uint64) [][]byte {
+	var args [][]byte
+	if key != nil {
+		args = append(args, key)
+	}
+	args = append(args, []byte(strconv.FormatUint(cursor, 10)))
+	if s == nil {
+		return args
+	}
+	if s.match != "" {
+		args = append(args, []byte("MATCH"), []byte(s.match))
+	}
+	if s.count > 0 {
+		args = append(args, []byte("COUNT"), []byte(strconv.FormatInt(s.count, 10)))
+	}
+	if s.typename != "" {
+		args = append(args, []byte("TYPE"), []byte(s.typename))
+	}
+	return args
+}
+
+// A page of a SCAN family request: the cursor of the next page (0 once
+// complete) and the elements of the page.  The elements of HSCAN (ZSCAN)
+// pages are fields and values (members and scores) in turn.
+type ScanPage struct {
+	Cursor   uint64
+	Elements [][]byte
+}
+
+// Returns the page of the (*2 cursor elements) reply.
+func replyToScanPage(r *Reply) (page ScanPage, err Error) {
+	if r.IsNil() || len(r.Array) != 2 {
+		return page, newSystemErrorf("expected cursor and elements - got %s", r)
+	}
+	cursor, e := strconv.ParseUint(string(r.Array[0].Bulk), 10, 64)
+	if e != nil {
+		return page, newSystemErrorWithCause("invalid cursor", e)
+	}
+	page.Cursor = cursor
+	for _, elem := range r.Array[1].Array {
+		page.Elements = append(page.Elements, elem.Bulk)
+	}
+	return page, nil
+}
+
+// Iterates over the elements of a SCAN family request, fetching pages on
+// demand.  Per Redis semantics, an element may be returned more than once,
+// and elements added or removed during the iteration may or may not be
+// returned.
+//
+// Usage:
+//
+//	it := client.Scan(NewScanSpec().Match("user:*"))
+//	for it.Next() {
+//	    key := it.Val()
+//	    ...
+//	}
+//	if err := it.Err(); err != nil {
+//	    ...
+//	}
+type ScanIterator interface {
+	// Advances to the next element, fetching the next page if required.
+	// Returns false once all pages are fetched, or on error.
+	Next() bool
+
+	// Returns the current element.
+	Val() []byte
+
+	// Returns the error, if any, of the iteration.
+	Err() Error
+}
+
+type scanIterator struct {
+	conn   SyncConnection
+	cmd    *Command
+	key    []byte // nil for SCAN
+	spec   *ScanSpec
+	cursor uint64
+	done   bool // the last page was fetched
+	page   [][]byte
+	val    []byte
+	err    Error
+}
+
+func newScanIterator(conn SyncConnection, cmd *Command, key []byte, spec *ScanSpec) ScanIterator {
+	return &scanIterator{conn: conn, cmd: cmd, key: key, spec: spec}
+}
+
+func (it *scanIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.val = nil
+			return false
+		}
+		it.fetch()
+	}
+	it.val, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *scanIterator) Val() []byte {
+	return it.val
+}
+
+func (it *scanIterator) Err() Error {
+	return it.err
+}
+
+// fetches the page at the cursor.
+func (it *scanIterator) fetch() {
+	resp, err := it.conn.ServiceRequest(it.cmd, it.spec.args(it.key, it.cursor))
+	if err != nil {
+		it.err = err
+		return
+	}
+	page, err := replyToScanPage(resp.GetReply())
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.cursor, it.page, it.done = page.Cursor, page.Elements, page.Cursor == 0
+}
@@ -0,0 +1,162 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scanReply pages through the elements per the cursor (the index of the
+// next element), COUNT (default 2) and MATCH of SCAN requests.  SSCAN,
+// HSCAN and ZSCAN page through the same elements, for any key but
+// "string".
+func scanReply(elements ...string) func(args []string) string {
+	return func(args []string) string {
+		switch args[0] {
+		case "SSCAN", "HSCAN", "ZSCAN":
+			if args[1] == "string" {
+				return "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+			}
+			args = args[1:]
+		case "SCAN":
+		case "QUIT":
+			return "+OK\r\n"
+		default:
+			return "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+
+		cursor, _ := strconv.Atoi(args[1])
+		count, match := 2, "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			switch args[i] {
+			case "COUNT":
+				count, _ = strconv.Atoi(args[i+1])
+			case "MATCH":
+				match = args[i+1]
+			}
+		}
+		var page []string
+		for ; cursor < len(elements) && count > 0; cursor, count = cursor+1, count-1 {
+			if ok, _ := path.Match(match, elements[cursor]); ok {
+				page = append(page, elements[cursor])
+			}
+		}
+		if cursor == len(elements) {
+			cursor = 0
+		}
+		reply := "*2\r\n" + bulkReply(strconv.Itoa(cursor)) + "*" + strconv.Itoa(len(page)) + "\r\n"
+		for _, elem := range page {
+			reply += bulkReply(elem)
+		}
+		return reply
+	}
+}
+
+// returns the elements of the iteration.
+func scanAll(t *testing.T, it ScanIterator) []string {
+	var elements []string
+	for it.Next() {
+		elements = append(elements, string(it.Val()))
+	}
+	if it.Val() != nil {
+		t.Errorf("expected nil Val after the iteration - got %s", it.Val())
+	}
+	return elements
+}
+
+func TestScanIterator(t *testing.T) {
+	server := newRecordingServer(t, scanReply("user:1", "user:2", "item:1", "user:3", "item:2"))
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	it := client.Scan(nil)
+	if keys := scanAll(t, it); strings.Join(keys, " ") != "user:1 user:2 item:1 user:3 item:2" || it.Err() != nil {
+		t.Errorf("expected all keys - got %v %v", keys, it.Err())
+	}
+	expected := []string{"SCAN 0", "SCAN 2", "SCAN 4"}
+	if requests := server.received(); strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected requests %v - got %v", expected, requests)
+	}
+
+	// pages without matches are skipped
+	it = client.Scan(NewScanSpec().Match("user:*").Count(3).Type("string"))
+	if keys := scanAll(t, it); strings.Join(keys, " ") != "user:1 user:2 user:3" {
+		t.Errorf("expected user keys - got %v", keys)
+	}
+	expected = []string{"SCAN 0 MATCH user:* COUNT 3 TYPE string", "SCAN 3 MATCH user:* COUNT 3 TYPE string"}
+	if requests := server.received(); strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected requests %v - got %v", expected, requests)
+	}
+
+	it = client.Hscan("hash", NewScanSpec().Match("item:*"))
+	if fields := scanAll(t, it); strings.Join(fields, " ") != "item:1 item:2" {
+		t.Errorf("expected item fields - got %v", fields)
+	}
+	if requests := server.received(); len(requests) != 3 || requests[0] != "HSCAN hash 0 MATCH item:*" {
+		t.Errorf("expected HSCAN requests - got %v", requests)
+	}
+
+	it = client.Sscan("string", nil)
+	if it.Next() {
+		t.Error("expected no elements of the wrong type")
+	}
+	if e := it.Err(); e == nil || !e.IsRedisError() || it.Next() {
+		t.Errorf("expected WRONGTYPE error - got %v", e)
+	}
+}
+
+func TestAsyncScan(t *testing.T) {
+	server := newRecordingServer(t, scanReply("a", "b", "c"))
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	var members []string
+	cursor := uint64(0)
+	for pages := 0; ; pages++ {
+		f, e := client.Zscan("zset", cursor, nil)
+		if e != nil {
+			t.Fatalf("Zscan - %s", e)
+		}
+		page, e, timedout := f.TryGet(time.Second)
+		if e != nil || timedout {
+			t.Fatalf("Zscan - %v timedout:%t", e, timedout)
+		}
+		for _, elem := range page.Elements {
+			members = append(members, string(elem))
+		}
+		if cursor = page.Cursor; cursor == 0 {
+			if pages != 1 {
+				t.Errorf("expected 2 pages - got %d", pages+1)
+			}
+			break
+		}
+	}
+	if strings.Join(members, " ") != "a b c" {
+		t.Errorf("expected all members - got %v", members)
+	}
+
+	f, _ := client.Sscan("string", 0, NewScanSpec().Count(10))
+	if _, e := f.Get(); e == nil || !e.IsRedisError() {
+		t.Errorf("expected WRONGTYPE error - got %v", e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_scan(t *testing.T) {
+	log.Println("-- scan test completed")
+}
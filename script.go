@@ -0,0 +1,133 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Script
+// ----------------------------------------------------------------------------
+
+// A Lua script.  The sha1 of the script is computed locally, and Run
+// (and RunAsync) first try EVALSHA, falling back to EVAL (which also
+// caches the script in Redis) if the server replies with NOSCRIPT.
+//
+// Script references are immutable and can be shared by goroutines.
+//
+// usage:
+//
+//      incrBy := redis.NewScript("return redis.call('INCRBY', KEYS[1], ARGV[1])")
+//      reply, e := incrBy.Run(client, []string{"counter"}, [][]byte{[]byte("10")})
+//
+type Script struct {
+	src  string
+	sha1 string
+}
+
+// Creates a new Script for the given Lua source.
+func NewScript(src string) *Script {
+	hash := sha1.Sum([]byte(src))
+	return &Script{src, hex.EncodeToString(hash[:])}
+}
+
+// Returns the Lua source of the script.
+func (s *Script) Source() string { return s.src }
+
+// Returns the (hex) sha1 of the script, as used by EVALSHA.
+func (s *Script) Sha1() string { return s.sha1 }
+
+// Loads the script into the script cache of the server, via SCRIPT LOAD.
+func (s *Script) Load(c Client) (err Error) {
+	_, err = c.ScriptLoad(s.src)
+	return
+}
+
+// Runs the script via EVALSHA, or EVAL if the script is not cached.
+func (s *Script) Run(c Client, keys []string, args [][]byte) (result *Reply, err Error) {
+	result, err = c.Evalsha(s.sha1, keys, args)
+	if err != nil && isNoScriptError(err) {
+		result, err = c.Eval(s.src, keys, args)
+	}
+	return
+}
+
+// Runs the script via EVALSHA, or EVAL if the script is not cached.
+//
+// EVAL is only queued once the EVALSHA response is received, so the script
+// may run after requests that were queued after RunAsync returned.  If this
+// matters (or if c is an AsyncTransaction, which can not queue EVAL after Exec)
+// use Load (or SCRIPT LOAD) beforehand.
+func (s *Script) RunAsync(c AsyncClient, keys []string, args [][]byte) (result FutureReply, err Error) {
+	fsha, err := c.Evalsha(s.sha1, keys, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result = newFutureReply()
+	go func() {
+		reply, e := fsha.Get()
+		if e != nil && isNoScriptError(e) {
+			var feval FutureReply
+			if feval, e = c.Eval(s.src, keys, args); e == nil {
+				reply, e = feval.Get()
+			}
+		}
+		if e != nil {
+			result.(FutureResult).onError(e)
+			return
+		}
+		result.set(reply)
+	}()
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+// script support
+// ----------------------------------------------------------------------------
+
+// Creates the args of EVAL and EVALSHA: script numkeys key [key ...] arg [arg ...]
+func scriptArgs(script string, keys []string, args [][]byte) [][]byte {
+	sarr := make([][]byte, 0, 2+len(keys)+len(args))
+	sarr = append(sarr, []byte(script), []byte(strconv.Itoa(len(keys))))
+	for _, key := range keys {
+		sarr = append(sarr, []byte(key))
+	}
+	return append(sarr, args...)
+}
+
+// Returns true if e is the NOSCRIPT error of EVALSHA.
+func isNoScriptError(e Error) bool {
+	if re, ok := e.(*redisError); ok {
+		return strings.HasPrefix(re.reply, "NOSCRIPT")
+	}
+	return false
+}
+
+// Converts the (integer) array reply of e.g. SCRIPT EXISTS.
+func replyToBools(r *Reply) []bool {
+	if r.IsNil() {
+		return nil
+	}
+	result := make([]bool, len(r.Array))
+	for i, elem := range r.Array {
+		result[i] = elem.Integer == 1
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestReadReply(t *testing.T) {
+	wire := "*5\r\n+OK\r\n-ERR nested\r\n:42\r\n$-1\r\n*2\r\n$3\r\nfoo\r\n*-1\r\n"
+	resp, e := GetResponse(bufio.NewReader(bytes.NewBufferString(wire)), &EVAL)
+	if e != nil {
+		t.Fatalf("GetResponse - %s", e)
+	}
+	r := resp.GetReply()
+	if r.Type != REPLY_ARRAY || len(r.Array) != 5 {
+		t.Fatalf("expected array of 5 - got %s", r)
+	}
+	expected := []ReplyType{REPLY_STATUS, REPLY_ERROR, REPLY_INTEGER, REPLY_NIL, REPLY_ARRAY}
+	for i, typ := range expected {
+		if r.Array[i].Type != typ {
+			t.Errorf("element %d - expected %s got %s", i, typ, r.Array[i].Type)
+		}
+	}
+	if r.Array[1].Err() == nil || r.Array[0].Err() != nil {
+		t.Error("Err() expected only for REPLY_ERROR")
+	}
+	if r.Array[2].Integer != 42 {
+		t.Errorf("expected 42 - got %d", r.Array[2].Integer)
+	}
+	nested := r.Array[4]
+	if string(nested.Array[0].Bulk) != "foo" || !nested.Array[1].IsNil() {
+		t.Errorf("unexpected nested array %s", nested)
+	}
+	if s := r.String(); s != "[OK (error) ERR nested 42 (nil) [foo (nil)]]" {
+		t.Errorf("String() - %s", s)
+	}
+}
+
+func TestSubcommandRequestBytes(t *testing.T) {
+	buff := CreateRequestBytes(&SCRIPT_LOAD, [][]byte{[]byte("return 1")})
+	expected := "*3\r\n$6\r\nSCRIPT\r\n$4\r\nLOAD\r\n$8\r\nreturn 1\r\n"
+	if string(buff) != expected {
+		t.Errorf("expected %q - got %q", expected, buff)
+	}
+}
+
+func TestScriptSha1(t *testing.T) {
+	// per redis EVAL documentation
+	script := NewScript("return 1")
+	if sha := script.Sha1(); sha != "e0e1f9fabfc9d4800c877a703b823ac0578ff8db" {
+		t.Errorf("unexpected sha1 %s", sha)
+	}
+}
+
+func TestScriptRun(t *testing.T) {
+	fake := newFakeRedis()
+	server := newFakeSessionServer(t, fake.session)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	script := NewScript("return {#KEYS, ...}")
+	if exists, e := client.ScriptExists(script.Sha1()); e != nil || exists[0] {
+		t.Fatalf("ScriptExists - %v %s", exists, e)
+	}
+
+	// NOSCRIPT -> EVAL
+	reply, e := script.Run(client, []string{"k1", "k2"}, [][]byte{[]byte("a")})
+	if e != nil {
+		t.Fatalf("Run - %s", e)
+	}
+	if reply.Type != REPLY_ARRAY || reply.Array[0].Integer != 2 || string(reply.Array[1].Bulk) != script.Source() {
+		t.Errorf("unexpected reply %s", reply)
+	}
+	// cached by EVAL -> EVALSHA
+	if _, e := script.Run(client, nil, nil); e != nil {
+		t.Fatalf("Run - %s", e)
+	}
+	if fake.evals != 1 {
+		t.Errorf("expected a single EVAL - got %d", fake.evals)
+	}
+
+	if e := client.ScriptFlush(); e != nil {
+		t.Fatalf("ScriptFlush - %s", e)
+	}
+	if _, e := client.Evalsha(script.Sha1(), nil, nil); !isNoScriptError(e) {
+		t.Errorf("expected NOSCRIPT error - got %s", e)
+	}
+	if e := script.Load(client); e != nil {
+		t.Fatalf("Load - %s", e)
+	}
+	if exists, _ := client.ScriptExists(script.Sha1(), "nosuchsha"); !exists[0] || exists[1] {
+		t.Errorf("ScriptExists - %v", exists)
+	}
+}
+
+func TestScriptRunAsync(t *testing.T) {
+	fake := newFakeRedis()
+	server := newFakeSessionServer(t, fake.session)
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	script := NewScript("return 1")
+	for i := 0; i < 2; i++ {
+		freply, e := script.RunAsync(client, []string{"k1"}, nil)
+		if e != nil {
+			t.Fatalf("RunAsync - %s", e)
+		}
+		reply, e, timedout := freply.TryGet(time.Second)
+		if timedout || e != nil {
+			t.Fatalf("RunAsync future - error:%s timedout:%t", e, timedout)
+		}
+		if reply.Array[0].Integer != 1 {
+			t.Errorf("unexpected reply %s", reply)
+		}
+	}
+	if fake.evals != 1 {
+		t.Errorf("expected a single EVAL - got %d", fake.evals)
+	}
+
+	fexists, _ := client.ScriptExists(script.Sha1())
+	if exists, e := fexists.Get(); e != nil || !exists[0] {
+		t.Errorf("ScriptExists - %v %s", exists, e)
+	}
+	fsha, _ := client.ScriptLoad("return 2")
+	if sha, e := fsha.Get(); e != nil || string(sha) != NewScript("return 2").Sha1() {
+		t.Errorf("ScriptLoad - %s %s", sha, e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_st(t *testing.T) {
+	log.Println("-- script test completed")
+}
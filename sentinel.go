@@ -0,0 +1,262 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// sentinel master discovery - see SentinelSpec
+// -----------------------------------------------------------------------------
+
+// the channel of the failover notifications of sentinels
+const switchMasterChannel = "+switch-master"
+
+// delay before resubscribing to failover notifications, if all sentinels
+// failed or the subscription was lost
+const sentinelRetryPeriod = time.Second
+
+// reconnect attempts of master connections, unless specified - see MasterSpec
+const sentinelReconnectAttempts = 3
+
+// Defines a master monitored by Redis Sentinel, by its name and the
+// host:port addresses of its sentinels.  See MasterSpec.
+type SentinelSpec struct {
+	masterName string
+	sentinels  []string
+	spec       *ConnectionSpec // of the sentinel connections
+	resolver   *sentinelResolver
+}
+
+// Creates a SentinelSpec of the named master, monitored by the sentinels
+// (host:port addresses), which are queried in the given order.
+func NewSentinelSpec(masterName string, sentinels ...string) *SentinelSpec {
+	s := &SentinelSpec{
+		masterName: masterName,
+		sentinels:  sentinels,
+		spec:       DefaultSpec(),
+	}
+	s.resolver = &sentinelResolver{sentinel: s, conns: make(map[*connHdl]bool)}
+	return s
+}
+
+// Sets the ConnectionSpec of the sentinel connections (e.g. for their
+// password or TLS settings), the host and port of which are per the
+// sentinel addresses, and returns the reference.
+// Note that you should not set this after you have already connected.
+func (s *SentinelSpec) SentinelConnectionSpec(spec *ConnectionSpec) *SentinelSpec {
+	s.spec = spec
+	return s
+}
+
+// Returns a copy of the spec for connections to the master, for use with
+// any of the client constructors.  The host, port and socket of the spec are
+// ignored: the master is resolved per SENTINEL get-master-addr-by-name of
+// the first responsive sentinel on each connect (and reconnect), and the
+// connection is verified to be to a master per ROLE.
+//
+// Once connected, the failover (+switch-master) notifications of a sentinel
+// are subscribed to, and on failover the connections to the old master are
+// closed.  Async and PubSub connections then reconnect (to the new master)
+// per ConnectionSpec.Reconnect, which, unless specified, is enabled (with 3
+// attempts) by the returned spec.  Sync clients redial on their next request,
+// and pooled clients open new connections.
+func (s *SentinelSpec) MasterSpec(spec *ConnectionSpec) *ConnectionSpec {
+	mspec := *spec
+	mspec.Socket("")
+	if mspec.reconnect == 0 {
+		mspec.Reconnect(sentinelReconnectAttempts)
+	}
+	mspec.sentinel = s.resolver
+	return &mspec
+}
+
+// -----------------------------------------------------------------------------
+// sentinelResolver
+// -----------------------------------------------------------------------------
+
+// sentinelResolver resolves the master of its SentinelSpec, and closes the
+// connections to the master on failover.
+type sentinelResolver struct {
+	sentinel *SentinelSpec
+	mutex    sync.Mutex
+	conns    map[*connHdl]bool // open master connections
+	done     chan bool         // closed to stop watching - see watch
+}
+
+// Returns the address of the master per the first responsive sentinel.
+// panics on error (with Error)
+func (r *sentinelResolver) resolve() (host string, port int) {
+	err := newSystemError("no sentinels")
+	for _, addr := range r.sentinel.sentinels {
+		if host, port, err = r.masterAddr(addr); err == nil {
+			return host, port
+		}
+	}
+	panic(newSystemErrorWithCause(fmt.Sprintf("failed to resolve master %s", r.sentinel.masterName), err))
+}
+
+// Returns the address of the master per the sentinel of the address.
+func (r *sentinelResolver) masterAddr(addr string) (host string, port int, err Error) {
+	spec, err := r.sentinelSpec(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	conn, err := NewSyncConnection(spec)
+	if err != nil {
+		return "", 0, err
+	}
+	defer conn.ServiceRequest(&QUIT, [][]byte{})
+
+	resp, err := conn.ServiceRequest(&SENTINEL_MASTER_ADDR, [][]byte{[]byte(r.sentinel.masterName)})
+	if err != nil {
+		return "", 0, err
+	}
+	data := resp.GetMultiBulkData()
+	if len(data) != 2 {
+		return "", 0, newSystemErrorf("sentinel %s - unknown master %s", addr, r.sentinel.masterName)
+	}
+	return splitAddr(net.JoinHostPort(string(data[0]), string(data[1])))
+}
+
+func (r *sentinelResolver) sentinelSpec(addr string) (*ConnectionSpec, Error) {
+	host, port, err := splitAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	spec := *r.sentinel.spec
+	spec.Socket("").Host(host).Port(port)
+	return &spec, nil
+}
+
+// Registers the new master connection, and starts watching for failovers,
+// unless watching.
+func (r *sentinelResolver) register(hdl *connHdl) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.conns[hdl] = true
+	if r.done == nil {
+		r.done = make(chan bool)
+		go r.watch(r.done)
+	}
+}
+
+// Unregisters the closed master connection, and stops watching for
+// failovers once no master connections remain.
+func (r *sentinelResolver) unregister(hdl *connHdl) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.conns, hdl)
+	if len(r.conns) == 0 && r.done != nil {
+		close(r.done)
+		r.done = nil
+	}
+}
+
+// Follows the failover notifications of the first responsive sentinel
+// until done, and resubscribes (after sentinelRetryPeriod) if the
+// subscription is lost.
+func (r *sentinelResolver) watch(done chan bool) {
+	for {
+		r.follow(done)
+		select {
+		case <-done:
+			return
+		case <-time.After(sentinelRetryPeriod):
+		}
+	}
+}
+
+func (r *sentinelResolver) follow(done chan bool) {
+	for _, addr := range r.sentinel.sentinels {
+		conn, s, err := r.subscribe(addr)
+		if err != nil {
+			log.Printf("<INFO> - sentinel %s - subscription to %s failed - %s", addr, switchMasterChannel, err)
+			continue
+		}
+		r.receive(s.Envelopes, done)
+		conn.ServiceRequest(&QUIT, [][]byte{})
+		return
+	}
+}
+
+// Switches the master on its failover notifications, until done or the
+// subscription is lost.
+func (r *sentinelResolver) receive(notifications <-chan *Message, done chan bool) {
+	for {
+		select {
+		case <-done:
+			return
+		case msg, ok := <-notifications:
+			if !ok {
+				return
+			}
+			// <master name> <old ip> <old port> <new ip> <new port>
+			if fields := strings.Fields(string(msg.Body)); len(fields) == 5 && fields[0] == r.sentinel.masterName {
+				r.switchMaster()
+			}
+		}
+	}
+}
+
+// Subscribes to the failover notifications of the sentinel of the address.
+func (r *sentinelResolver) subscribe(addr string) (conn *asyncConnHdl, s *Subscription, err Error) {
+	spec, err := r.sentinelSpec(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	spec.Reconnect(0)
+	if conn, err = newPubSubConnection(spec, nil); err != nil {
+		return nil, nil, err
+	}
+	pending, err := conn.ServiceSubscription(&SUBSCRIBE, ENVELOPE, [][]byte{[]byte(switchMasterChannel)})
+	if err == nil {
+		_, err = pending[switchMasterChannel].Get()
+	}
+	s = conn.Subscriptions()[SubscriptionKey{switchMasterChannel, false}]
+	if err == nil && s == nil {
+		err = newSystemError("subscription to " + switchMasterChannel + " failed")
+	}
+	if err != nil {
+		conn.ServiceRequest(&QUIT, [][]byte{})
+		return nil, nil, err
+	}
+	return conn, s, nil
+}
+
+// Closes the connections to the (old) master, and marks them stale.
+func (r *sentinelResolver) switchMaster() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for hdl := range r.conns {
+		atomic.StoreInt32(&hdl.stale, 1)
+		hdl.conn.Close()
+	}
+}
+
+// Returns true if the connection's master was switched.
+func (c *connHdl) isStale() bool {
+	return atomic.LoadInt32(&c.stale) == 1
+}
@@ -0,0 +1,247 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// fake sentinel and replication nodes
+// ----------------------------------------------------------------------------
+
+// fakeSentinel is a sentinel of the master "mymaster", serving SENTINEL
+// get-master-addr-by-name, and SUBSCRIBE.  See failover.
+type fakeSentinel struct {
+	*fakeServer
+	mutex       sync.Mutex
+	master      string // host:port
+	subscribers int
+}
+
+func newFakeSentinel(t *testing.T, master string) *fakeSentinel {
+	s := &fakeSentinel{master: master}
+	s.fakeServer = newFakeServer(t, s.handle)
+	return s
+}
+
+func (s *fakeSentinel) handle(args []string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch args[0] {
+	case "SENTINEL":
+		if args[1] == "get-master-addr-by-name" && args[2] == "mymaster" {
+			host, port, _ := net.SplitHostPort(s.master)
+			return "*2\r\n" + bulkReply(host) + bulkReply(port)
+		}
+		return "*-1\r\n"
+	case "SUBSCRIBE":
+		s.subscribers++
+		return "*3\r\n" + bulkReply("subscribe") + bulkReply(args[1]) + ":1\r\n"
+	case "PING":
+		return "+PONG\r\n"
+	case "QUIT":
+		return "+OK\r\n"
+	}
+	return "-ERR unknown command '" + args[0] + "'\r\n"
+}
+
+func (s *fakeSentinel) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSentinel) subscriberCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.subscribers
+}
+
+// switches the master and notifies the subscribers.
+func (s *fakeSentinel) failover(master string) {
+	s.mutex.Lock()
+	old := s.master
+	s.master = master
+	s.mutex.Unlock()
+
+	oldhost, oldport, _ := net.SplitHostPort(old)
+	host, port, _ := net.SplitHostPort(master)
+	s.push(pubsubMessage("message", switchMasterChannel, strings.Join([]string{"mymaster", oldhost, oldport, host, port}, " ")))
+}
+
+// fakeNode is a fakeRedis that replies to ROLE per its role.
+type fakeNode struct {
+	*fakeServer
+	redis *fakeRedis
+	mutex sync.Mutex
+	role  string // master or slave
+}
+
+func newFakeNode(t *testing.T, role string) *fakeNode {
+	n := &fakeNode{redis: newFakeRedis(), role: role}
+	n.fakeServer = newFakeSessionServer(t, func() func(args []string) string {
+		session := n.redis.session()
+		return func(args []string) string {
+			if args[0] == "ROLE" {
+				n.mutex.Lock()
+				defer n.mutex.Unlock()
+				return "*3\r\n" + bulkReply(n.role) + ":0\r\n*0\r\n"
+			}
+			return session(args)
+		}
+	})
+	return n
+}
+
+func (n *fakeNode) addr() string {
+	return n.listener.Addr().String()
+}
+
+func (n *fakeNode) setRole(role string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.role = role
+}
+
+// returns the value of the key, if any.
+func (n *fakeNode) value(key string) string {
+	n.redis.mutex.Lock()
+	defer n.redis.mutex.Unlock()
+	return n.redis.data[key]
+}
+
+func (n *fakeNode) set(key, value string) {
+	n.redis.mutex.Lock()
+	defer n.redis.mutex.Unlock()
+	n.redis.data[key] = value
+}
+
+// ----------------------------------------------------------------------------
+// tests
+// ----------------------------------------------------------------------------
+
+func TestSentinelFailover(t *testing.T) {
+	master, replica := newFakeNode(t, "master"), newFakeNode(t, "slave")
+	defer master.close()
+	defer replica.close()
+	sentinel := newFakeSentinel(t, master.addr())
+	defer sentinel.close()
+
+	// the first sentinel is down
+	down, _ := net.Listen("tcp", "127.0.0.1:0")
+	down.Close()
+	sspec := NewSentinelSpec("mymaster", down.Addr().String(), sentinel.addr())
+
+	// reconnect is enabled by default
+	async, e := NewAsynchClientWithSpec(sspec.MasterSpec(DefaultSpec().Heartbeat(time.Hour).Backoff(10*time.Millisecond, 100*time.Millisecond)))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer async.Quit()
+	client, e := NewSynchClientWithSpec(sspec.MasterSpec(DefaultSpec()))
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+	pooled, e := NewPooledSynchClientWithSpec(sspec.MasterSpec(DefaultSpec()))
+	if e != nil {
+		t.Fatalf("NewPooledSynchClientWithSpec - %s", e)
+	}
+	defer pooled.Quit()
+
+	if e := pooled.Set("foo", []byte("bar")); e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	if v := master.value("foo"); v != "bar" {
+		t.Errorf("expected foo bar at the master - got %q", v)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for sentinel.subscriberCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if sentinel.subscriberCount() != 1 {
+		t.Fatalf("expected a subscription to %s - got %d", switchMasterChannel, sentinel.subscriberCount())
+	}
+
+	replica.set("foo", "baz")
+	master.setRole("slave")
+	replica.setRole("master")
+	sentinel.failover(replica.addr())
+
+	// the async client reconnects to the new master
+	for v := ""; v != "baz"; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected async GET baz from the new master - got %q", v)
+		}
+		if f, e := async.Get("foo"); e == nil {
+			b, _, _ := f.TryGet(time.Second)
+			v = string(b)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// pooled connections to the old master are not reused
+	if v, e := pooled.Get("foo"); e != nil || string(v) != "baz" {
+		t.Errorf("expected pooled GET baz from the new master - got %s %v", v, e)
+	}
+	// the sync client redials to the new master
+	if v, e := client.Get("foo"); e != nil || string(v) != "baz" {
+		t.Errorf("expected sync GET baz from the new master - got %s %v", v, e)
+	}
+	if e := client.Set("foo", []byte("qux")); e != nil || replica.value("foo") != "qux" {
+		t.Errorf("expected sync SET at the new master - got %v", e)
+	}
+}
+
+func TestSentinelMasterSpecReconnect(t *testing.T) {
+	sspec := NewSentinelSpec("mymaster", "127.0.0.1:26379")
+	if n := sspec.MasterSpec(DefaultSpec()).reconnect; n != sentinelReconnectAttempts {
+		t.Errorf("expected %d reconnect attempts by default - got %d", sentinelReconnectAttempts, n)
+	}
+	if n := sspec.MasterSpec(DefaultSpec().Reconnect(10)).reconnect; n != 10 {
+		t.Errorf("expected the specified reconnect attempts - got %d", n)
+	}
+}
+
+func TestSentinelResolution(t *testing.T) {
+	master, replica := newFakeNode(t, "master"), newFakeNode(t, "slave")
+	defer master.close()
+	defer replica.close()
+	sentinel := newFakeSentinel(t, replica.addr())
+	defer sentinel.close()
+
+	_, e := NewSynchClientWithSpec(NewSentinelSpec("mymaster", sentinel.addr()).MasterSpec(DefaultSpec()))
+	if e == nil || !strings.Contains(e.Error(), "not a master") {
+		t.Errorf("expected ROLE verification error - got %v", e)
+	}
+
+	_, e = NewSynchClientWithSpec(NewSentinelSpec("unknown", sentinel.addr()).MasterSpec(DefaultSpec()))
+	if e == nil || !strings.Contains(e.Error(), "unknown master unknown") {
+		t.Errorf("expected unknown master error - got %v", e)
+	}
+
+	_, e = NewSynchClientWithSpec(NewSentinelSpec("mymaster").MasterSpec(DefaultSpec()))
+	if e == nil || !strings.Contains(e.Error(), "no sentinels") {
+		t.Errorf("expected resolution error - got %v", e)
+	}
+
+	sentinel.failover(master.addr())
+	client, e := NewSynchClientWithSpec(NewSentinelSpec("mymaster", sentinel.addr()).MasterSpec(DefaultSpec()))
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	if e := client.Set("foo", []byte("bar")); e != nil || master.value("foo") != "bar" {
+		t.Errorf("expected SET at the master - got %v", e)
+	}
+	client.Quit()
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_sentinel(t *testing.T) {
+	log.Println("-- sentinel test completed")
+}
@@ -0,0 +1,332 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"crypto/md5"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// client side sharding - see NewShardedClientWithSpecs
+// -----------------------------------------------------------------------------
+
+// ring points per unit of shard weight (40 md5 digests of 4 points each, as
+// per ketama)
+const ketamaPoints = 160
+
+// A Client of independent Redis servers (shards), each serving the keys
+// hashed to it.  See NewShardedClientWithSpecs.
+type ShardedClient interface {
+	Client
+
+	// Returns the index of the shard of the key.
+	ShardOf(key string) int
+}
+
+// A server of a ShardedClient, and its weight, i.e. its relative share of
+// the keys.
+type Shard struct {
+	Spec   *ConnectionSpec
+	Weight int
+}
+
+// Create a new ShardedClient of the shards.  Keys are mapped to the shards
+// per a (ketama) consistent hash ring, on which each shard has points in
+// proportion to its weight, so that adding or removing a shard remaps only
+// the keys of its points.  Shards are placed on the ring per their address
+// (host:port or socket), so the order of the shards does not matter.  Keys
+// with a non empty {hashtag} are mapped per the hashtag (see hashTag).
+//
+// Requests are serviced by the shard of their key, on a connection pool per
+// shard (see NewPooledSynchClientWithSpec), and the client can be shared by
+// multiple goroutines.  Mget is split by shard, and its values reassembled
+// in the order of the keys.  Other multi-key requests (e.g. Sinter, Rename,
+// or Do of DEL, MSET or EXISTS) fail if their keys span shards.  Do requests
+// of other commands are serviced by the shard of their first arg, which
+// must be their (only) key.  XreadBlock is serviced by the shard of its
+// streams, which must not span shards.  Flushdb and Flushall are serviced
+// by all shards.  Other keyless requests (e.g. Dbsize, Keys) and
+// transactions are not supported.
+//
+// Quit() closes all connections.
+func NewShardedClientWithSpecs(shards []Shard) (c ShardedClient, err Error) {
+	conn, err := newShardedConn(shards)
+	if err != nil {
+		return nil, withError(err)
+	}
+	return &shardedClient{syncClient{conn}}, nil
+}
+
+type shardedClient struct {
+	syncClient
+}
+
+func (c *shardedClient) ShardOf(key string) int {
+	return c.conn.(*shardedConn).shardOf([]byte(key))
+}
+
+// -----------------------------------------------------------------------------
+// shardedConn - supports SyncConnection interface
+// -----------------------------------------------------------------------------
+
+// shardedConn services requests on the connection of the shard of their
+// keys.
+type shardedConn struct {
+	conns []SyncConnection // by shard
+	ring  ketamaRing
+}
+
+func newShardedConn(shards []Shard) (*shardedConn, Error) {
+	if len(shards) == 0 {
+		return nil, newSystemError("no shards")
+	}
+	c := &shardedConn{}
+	names := make(map[string]bool)
+	for i, shard := range shards {
+		name := shardName(shard.Spec)
+		switch {
+		case shard.Weight <= 0:
+			c.quit()
+			return nil, newSystemErrorf("shard %s - weight must be positive - got %d", name, shard.Weight)
+		case names[name]:
+			c.quit()
+			return nil, newSystemErrorf("shard %s - duplicate shard", name)
+		}
+		names[name] = true
+
+		conn, err := NewPooledSyncConnection(shard.Spec)
+		if err != nil {
+			c.quit()
+			return nil, err
+		}
+		c.conns = append(c.conns, conn)
+		c.ring.add(i, name, shard.Weight)
+	}
+	sort.Sort(&c.ring)
+	return c, nil
+}
+
+// returns the address of the shard, which places it on the ring.
+func shardName(spec *ConnectionSpec) string {
+	if spec.socket != "" {
+		return spec.socket
+	}
+	return spec.host + ":" + strconv.Itoa(spec.port)
+}
+
+func (c *shardedConn) shardOf(key []byte) int {
+	return c.ring.shardOf(key)
+}
+
+// Implementation of SyncConnection.ServiceRequest
+func (c *shardedConn) ServiceRequest(cmd *Command, args [][]byte) (Response, Error) {
+	switch cmd {
+	case &QUIT:
+		c.quit()
+		return &_response{boolval: true}, nil
+	case &FLUSHDB, &FLUSHALL:
+		for _, conn := range c.conns {
+			if _, err := conn.ServiceRequest(cmd, args); err != nil {
+				return nil, err
+			}
+		}
+		return &_response{boolval: true}, nil
+	case &MGET:
+		return c.mget(args)
+	}
+
+	shard, err := c.shardOfKeys(cmd, commandKeys(cmd, args))
+	if err != nil {
+		return nil, err
+	}
+	return c.conns[shard].ServiceRequest(cmd, args)
+}
+
+// Implementation of SyncConnection.Do
+// The request is serviced by the shard of its keys - see doKeys.
+func (c *shardedConn) Do(name string, args ...interface{}) (*Reply, Error) {
+	cmd, bargs, err := newDoRequest(name, args)
+	if err != nil {
+		return nil, err
+	}
+	shard, err := c.shardOfKeys(cmd, doKeys(cmd.Code, bargs))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.conns[shard].ServiceRequest(cmd, bargs)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetReply(), nil
+}
+
+// Implementation of blockingRouter: blocking requests are serviced by a
+// connection of the pool of the shard of their keys.
+func (c *shardedConn) blockingSource(cmd *Command, args [][]byte) (txConnection, Error) {
+	shard, err := c.shardOfKeys(cmd, commandKeys(cmd, args))
+	if err != nil {
+		return nil, err
+	}
+	return c.conns[shard].(txConnection), nil
+}
+
+// Returns the shard of the keys of the request, or an error if there are
+// none, or if they span shards.
+func (c *shardedConn) shardOfKeys(cmd *Command, keys [][]byte) (int, Error) {
+	if len(keys) == 0 {
+		return 0, newSystemErrorf("%s - keyless requests are not supported by sharded clients", cmd.Code)
+	}
+	shard := c.shardOf(keys[0])
+	for _, key := range keys[1:] {
+		if c.shardOf(key) != shard {
+			return 0, newSystemErrorf("%s - keys %s and %s are on distinct shards (see {hashtags})", cmd.Code, keys[0], key)
+		}
+	}
+	return shard, nil
+}
+
+// Returns the keys of the Do request, per the (multi-key) commands known to
+// take more than one key, and otherwise its first arg.
+func doKeys(code string, args [][]byte) [][]byte {
+	if len(args) == 0 {
+		return nil
+	}
+	switch strings.ToUpper(code) {
+	case "DEL", "UNLINK", "EXISTS", "TOUCH", "MGET",
+		"SINTER", "SINTERSTORE", "SUNION", "SUNIONSTORE", "SDIFF", "SDIFFSTORE",
+		"PFCOUNT", "PFMERGE":
+		return args
+	case "MSET", "MSETNX":
+		// key value [key value ...]
+		var keys [][]byte
+		for i := 0; i < len(args); i += 2 {
+			keys = append(keys, args[i])
+		}
+		return keys
+	case "RENAME", "RENAMENX", "SMOVE", "RPOPLPUSH", "BRPOPLPUSH", "LMOVE", "BLMOVE", "COPY":
+		if len(args) > 2 {
+			return args[:2]
+		}
+		return args
+	case "BLPOP", "BRPOP", "BZPOPMIN", "BZPOPMAX":
+		// key [key ...] timeout
+		return args[:len(args)-1]
+	case "EVAL", "EVALSHA":
+		return requestKeys(&EVAL, args)
+	case "ZUNIONSTORE", "ZINTERSTORE", "ZDIFFSTORE":
+		return commandKeys(&ZUNIONSTORE, args)
+	}
+	return args[:1]
+}
+
+// Services MGET on the shards of the keys (concurrently), and returns the
+// values in the order of the keys.
+func (c *shardedConn) mget(keys [][]byte) (Response, Error) {
+	byshard := make(map[int][]int) // shard -> key indices
+	for i, key := range keys {
+		shard := c.shardOf(key)
+		byshard[shard] = append(byshard[shard], i)
+	}
+
+	values := make([][]byte, len(keys))
+	errs := make(chan Error, len(byshard))
+	var wg sync.WaitGroup
+	for shard, indices := range byshard {
+		wg.Add(1)
+		go func(shard int, indices []int) {
+			defer wg.Done()
+			args := make([][]byte, len(indices))
+			for j, i := range indices {
+				args[j] = keys[i]
+			}
+			resp, err := c.conns[shard].ServiceRequest(&MGET, args)
+			if err != nil {
+				errs <- err
+				return
+			}
+			data := resp.GetMultiBulkData()
+			if len(data) != len(indices) {
+				errs <- newSystemErrorf("MGET - expected %d values from shard %d - got %d", len(indices), shard, len(data))
+				return
+			}
+			for j, i := range indices {
+				values[i] = data[j]
+			}
+		}(shard, indices)
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	return &_response{multibulkdata: values}, nil
+}
+
+// Quits the connections of all shards.
+func (c *shardedConn) quit() {
+	for _, conn := range c.conns {
+		conn.ServiceRequest(&QUIT, [][]byte{})
+	}
+}
+
+// -----------------------------------------------------------------------------
+// ketamaRing
+// -----------------------------------------------------------------------------
+
+// ketamaRing is a consistent hash ring of the points of the shards.
+type ketamaRing struct {
+	points []uint32 // sorted, once built
+	shards []int    // shard of points[i]
+}
+
+// adds the points of the shard, per its name and weight.
+func (r *ketamaRing) add(shard int, name string, weight int) {
+	for i := 0; i < ketamaPoints*weight/4; i++ {
+		digest := md5.Sum([]byte(name + "-" + strconv.Itoa(i)))
+		for j := 0; j < 4; j++ {
+			r.points = append(r.points, ketamaHash(digest[j*4:]))
+			r.shards = append(r.shards, shard)
+		}
+	}
+}
+
+// returns the (little endian) uint32 of the first 4 bytes of the digest.
+func ketamaHash(digest []byte) uint32 {
+	return uint32(digest[3])<<24 | uint32(digest[2])<<16 | uint32(digest[1])<<8 | uint32(digest[0])
+}
+
+// Returns the shard of the first point at or after the hash of the key
+// (its hashTag).
+func (r *ketamaRing) shardOf(key []byte) int {
+	digest := md5.Sum(hashTag(key))
+	hash := ketamaHash(digest[:])
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.shards[i]
+}
+
+// sort.Interface support - sorts the points, and their shards.
+func (r *ketamaRing) Len() int           { return len(r.points) }
+func (r *ketamaRing) Less(i, j int) bool { return r.points[i] < r.points[j] }
+func (r *ketamaRing) Swap(i, j int) {
+	r.points[i], r.points[j] = r.points[j], r.points[i]
+	r.shards[i], r.shards[j] = r.shards[j], r.shards[i]
+}
@@ -0,0 +1,157 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// returns a ring of the named shards of the weights.
+func newTestRing(names []string, weights []int) *ketamaRing {
+	ring := &ketamaRing{}
+	for i, name := range names {
+		ring.add(i, name, weights[i])
+	}
+	sort.Sort(ring)
+	return ring
+}
+
+func TestKetamaRing(t *testing.T) {
+	names := []string{"10.0.0.1:6379", "10.0.0.2:6379", "10.0.0.3:6379"}
+	ring := newTestRing(names, []int{1, 1, 2})
+
+	keys := 20000
+	counts := make([]int, 3)
+	for i := 0; i < keys; i++ {
+		counts[ring.shardOf([]byte("key"+strconv.Itoa(i)))]++
+	}
+	for shard, share := range []float64{0.25, 0.25, 0.5} {
+		if actual := float64(counts[shard]) / float64(keys); actual < share-0.05 || actual > share+0.05 {
+			t.Errorf("shard %d - expected share %.2f - got %.3f", shard, share, actual)
+		}
+	}
+
+	// shards are placed per their names, and removing a shard remaps only
+	// its keys
+	reordered := newTestRing([]string{names[1], names[0]}, []int{1, 1})
+	for i := 0; i < keys; i++ {
+		key := []byte("key" + strconv.Itoa(i))
+		shard := ring.shardOf(key)
+		if shard == 2 {
+			continue
+		}
+		if remapped := 1 - reordered.shardOf(key); remapped != shard {
+			t.Fatalf("%s - expected shard %d - got %d", key, shard, remapped)
+		}
+	}
+
+	if ring.shardOf([]byte("{user1000}.following")) != ring.shardOf([]byte("{user1000}.followers")) {
+		t.Error("expected keys of the same hashtag on the same shard")
+	}
+}
+
+func TestShardedClient(t *testing.T) {
+	nodes := []*fakeNode{newFakeNode(t, "master"), newFakeNode(t, "master"), newFakeNode(t, "master")}
+	var shards []Shard
+	for _, node := range nodes {
+		defer node.close()
+		shards = append(shards, Shard{node.spec(), 1})
+	}
+
+	client, e := NewShardedClientWithSpecs(shards)
+	if e != nil {
+		t.Fatalf("NewShardedClientWithSpecs - %s", e)
+	}
+	defer client.Quit()
+
+	var keys []string
+	used := make(map[int]bool)
+	for i := 0; i < 30; i++ {
+		key := "key" + strconv.Itoa(i)
+		keys = append(keys, key)
+		if e := client.Set(key, []byte(strconv.Itoa(i))); e != nil {
+			t.Fatalf("Set - %s", e)
+		}
+		shard := client.ShardOf(key)
+		used[shard] = true
+		if v := nodes[shard].value(key); v != strconv.Itoa(i) {
+			t.Errorf("%s - expected %d on shard %d - got %q", key, i, shard, v)
+		}
+	}
+	if len(used) != 3 {
+		t.Errorf("expected keys on all shards - got %v", used)
+	}
+
+	values, e := client.Mget(keys[0], append(keys[1:], "missing"))
+	if e != nil || len(values) != len(keys)+1 {
+		t.Fatalf("expected %d MGET values - got %d %s", len(keys)+1, len(values), e)
+	}
+	for i, v := range values[:len(keys)] {
+		if string(v) != strconv.Itoa(i) {
+			t.Errorf("expected MGET value %d of %s - got %s", i, keys[i], v)
+		}
+	}
+	if values[len(keys)] != nil {
+		t.Errorf("expected nil MGET value of missing key - got %s", values[len(keys)])
+	}
+
+	// multi-key requests across shards
+	other := keys[1]
+	for client.ShardOf(other) == client.ShardOf(keys[0]) {
+		other += "x"
+	}
+	if _, e := client.Sinter(keys[0], []string{other}); e == nil || !strings.Contains(e.Error(), "distinct shards") {
+		t.Errorf("expected distinct shards error - got %v", e)
+	}
+	for _, request := range []struct {
+		name string
+		args []interface{}
+	}{
+		{"DEL", []interface{}{keys[0], other}},
+		{"EXISTS", []interface{}{keys[0], other}},
+		{"MSET", []interface{}{keys[0], "v", other, "v"}},
+	} {
+		if _, e := client.Do(request.name, request.args...); e == nil || !strings.Contains(e.Error(), "distinct shards") {
+			t.Errorf("expected distinct shards error of Do %s - got %v", request.name, e)
+		}
+	}
+	if _, e := client.Do("SET", keys[0], other); e != nil || nodes[client.ShardOf(keys[0])].value(keys[0]) != other {
+		t.Errorf("expected Do SET on the shard of %s - got %v", keys[0], e)
+	}
+	if r, e := client.Do("MGET", "{t}a", "{t}b"); e != nil || len(r.Array) != 2 {
+		t.Errorf("expected Do MGET of keys of one shard - got %v %v", r, e)
+	}
+	if streams, e := client.XreadBlock(map[string]string{keys[0]: "$"}, 0, time.Millisecond); e != nil || streams != nil {
+		t.Errorf("expected XREAD BLOCK on the shard of %s - got %v %v", keys[0], streams, e)
+	}
+	if _, e := client.XreadBlock(map[string]string{keys[0]: "$", other: "$"}, 0, 0); e == nil || !strings.Contains(e.Error(), "distinct shards") {
+		t.Errorf("expected distinct shards error of XREAD BLOCK - got %v", e)
+	}
+	if _, e := client.Dbsize(); e == nil || !strings.Contains(e.Error(), "not supported") {
+		t.Errorf("expected keyless request error - got %v", e)
+	}
+
+	if e := client.Flushdb(); e != nil {
+		t.Errorf("Flushdb - %s", e)
+	}
+	for i, node := range nodes {
+		if v := node.value(keys[0]); v != "" {
+			t.Errorf("expected flushed shard %d", i)
+		}
+	}
+
+	_, e = NewShardedClientWithSpecs([]Shard{{nodes[0].spec(), 0}})
+	if e == nil || !strings.Contains(e.Error(), "weight must be positive") {
+		t.Errorf("expected weight error - got %v", e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_shard(t *testing.T) {
+	log.Println("-- shard test completed")
+}
@@ -0,0 +1,146 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import "strconv"
+
+// -----------------------------------------------------------------------------
+// SORT support - see Client.Sort
+// -----------------------------------------------------------------------------
+
+// Defines the options of a SORT request.  The zero value (or nil) sorts the
+// elements numerically, in ascending order.
+//
+// Usage:
+//
+//	spec := NewSortSpec().By("weight_*").Get("#").Get("object_*").Limit(0, 10).Desc()
+//	result, err := client.Sort("mylist", spec)
+type SortSpec struct {
+	by     string
+	limit  bool
+	offset int64
+	count  int64
+	get    []string
+	order  string // "", ASC or DESC
+	alpha  bool
+	store  string
+}
+
+// Creates a new SortSpec, without options.
+func NewSortSpec() *SortSpec {
+	return &SortSpec{}
+}
+
+// Sorts per the values of the keys of the pattern (e.g. "weight_*", where *
+// is substituted by each element), or "nosort" to skip sorting, and returns
+// the reference.
+func (s *SortSpec) By(pattern string) *SortSpec {
+	s.by = pattern
+	return s
+}
+
+// Returns count elements (all remaining elements if count is negative),
+// after skipping offset elements, and returns the reference.
+func (s *SortSpec) Limit(offset, count int64) *SortSpec {
+	s.offset, s.count, s.limit = offset, count, true
+	return s
+}
+
+// Adds a GET pattern, i.e. returns the values of the keys of the pattern
+// (e.g. "object_*", or "#" for the element itself) instead of the elements,
+// and returns the reference.  With multiple GET patterns, the values of each
+// element are returned in turn.
+func (s *SortSpec) Get(pattern string) *SortSpec {
+	s.get = append(s.get, pattern)
+	return s
+}
+
+// Sorts in ascending order (the default), and returns the reference.
+func (s *SortSpec) Asc() *SortSpec {
+	s.order = "ASC"
+	return s
+}
+
+// Sorts in descending order, and returns the reference.
+func (s *SortSpec) Desc() *SortSpec {
+	s.order = "DESC"
+	return s
+}
+
+// Sorts lexicographically, rather than numerically, and returns the
+// reference.
+func (s *SortSpec) Alpha() *SortSpec {
+	s.alpha = true
+	return s
+}
+
+// Stores the result as a list at the destination key, rather than returning
+// it, and returns the reference.
+func (s *SortSpec) Store(dst string) *SortSpec {
+	s.store = dst
+	return s
+}
+
+// returns the command of the spec, per STORE.
+func (s *SortSpec) command() *Command {
+	if s != nil && s.store != "" {
+		return &SORT_STORE
+	}
+	return &SORT
+}
+
+// returns the args of the SORT request of the key.
+func (s *SortSpec) args(key string) [][]byte {
+	args := [][]byte{[]byte(key)}
+	if s == nil {
+		return args
+	}
+	if s.by != "" {
+		args = append(args, []byte("BY"), []byte(s.by))
+	}
+	if s.limit {
+		args = append(args, []byte("LIMIT"), []byte(strconv.FormatInt(s.offset, 10)), []byte(strconv.FormatInt(s.count, 10)))
+	}
+	for _, pattern := range s.get {
+		args = append(args, []byte("GET"), []byte(pattern))
+	}
+	if s.order != "" {
+		args = append(args, []byte(s.order))
+	}
+	if s.alpha {
+		args = append(args, []byte("ALPHA"))
+	}
+	if s.store != "" {
+		args = append(args, []byte("STORE"), []byte(s.store))
+	}
+	return args
+}
+
+// The result of a SORT request: the sorted elements (or values, per
+// SortSpec.Get), or if stored (per SortSpec.Store) nil.  Count is the number
+// of elements returned or stored.
+type SortResult struct {
+	Elements [][]byte
+	Count    int64
+}
+
+// Returns the SortResult of the SORT (or SORT_STORE) response.
+func sortResult(cmd *Command, resp Response) SortResult {
+	if cmd == &SORT_STORE {
+		return SortResult{Count: resp.GetNumberValue()}
+	}
+	elements := resp.GetMultiBulkData()
+	return SortResult{elements, int64(len(elements))}
+}
@@ -0,0 +1,101 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sortReply replies to SORT requests with the elements "3 2 1", or with
+// their count if stored.
+func sortReply(args []string) string {
+	switch {
+	case args[0] == "QUIT":
+		return "+OK\r\n"
+	case args[0] != "SORT":
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	case args[1] == "string":
+		return "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+	case len(args) > 2 && args[len(args)-2] == "STORE":
+		return ":3\r\n"
+	}
+	return "*3\r\n" + bulkReply("3") + bulkReply("2") + bulkReply("1")
+}
+
+func TestSortSpec(t *testing.T) {
+	for _, test := range []struct {
+		spec     *SortSpec
+		expected string
+	}{
+		{nil, "k"},
+		{NewSortSpec(), "k"},
+		{NewSortSpec().Get("#").By("weight_*").Desc().Get("o_*").Limit(0, 10), "k BY weight_* LIMIT 0 10 GET # GET o_* DESC"},
+		{NewSortSpec().Store("dst").Alpha().Asc(), "k ASC ALPHA STORE dst"},
+		{NewSortSpec().By("nosort").Limit(5, -1), "k BY nosort LIMIT 5 -1"},
+	} {
+		var args []string
+		for _, arg := range test.spec.args("k") {
+			args = append(args, string(arg))
+		}
+		if actual := strings.Join(args, " "); actual != test.expected {
+			t.Errorf("expected SORT %s - got %s", test.expected, actual)
+		}
+	}
+	if NewSortSpec().command() != &SORT || (*SortSpec)(nil).command() != &SORT || NewSortSpec().Store("dst").command() != &SORT_STORE {
+		t.Error("expected SORT_STORE only if stored")
+	}
+	if keys := commandKeys(&SORT_STORE, NewSortSpec().Desc().Store("dst").args("k")); len(keys) != 2 ||
+		string(keys[0]) != "k" || string(keys[1]) != "dst" {
+		t.Errorf("expected SORT keys k dst - got %q", keys)
+	}
+}
+
+func TestSort(t *testing.T) {
+	server := newRecordingServer(t, sortReply)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	result, e := client.Sort("list", NewSortSpec().Desc())
+	if e != nil || result.Count != 3 || len(result.Elements) != 3 || string(result.Elements[0]) != "3" {
+		t.Errorf("expected sorted elements 3 2 1 - got %v %v", result, e)
+	}
+	result, e = client.Sort("list", NewSortSpec().Store("dst"))
+	if e != nil || result.Count != 3 || result.Elements != nil {
+		t.Errorf("expected stored count 3 - got %v %v", result, e)
+	}
+	if _, e := client.Sort("string", nil); e == nil || !e.IsRedisError() {
+		t.Errorf("expected WRONGTYPE error - got %v", e)
+	}
+	expected := []string{"SORT list DESC", "SORT list STORE dst", "SORT string"}
+	if requests := server.received(); strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected requests %v - got %v", expected, requests)
+	}
+
+	async, e := NewAsynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer async.Quit()
+
+	felements, _ := async.Sort("list", NewSortSpec().Alpha())
+	fcount, _ := async.Sort("list", NewSortSpec().Store("dst"))
+	if result, e, timedout := felements.TryGet(time.Second); e != nil || timedout || result.Count != 3 || string(result.Elements[2]) != "1" {
+		t.Errorf("expected async sorted elements 3 2 1 - got %v %v timedout:%t", result, e, timedout)
+	}
+	if result, e := fcount.Get(); e != nil || result.Count != 3 || result.Elements != nil {
+		t.Errorf("expected async stored count 3 - got %v %v", result, e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_sort(t *testing.T) {
+	log.Println("-- sort test completed")
+}
@@ -0,0 +1,195 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"math"
+	"strconv"
+)
+
+// -----------------------------------------------------------------------------
+// sorted set support
+// -----------------------------------------------------------------------------
+
+// A member of a sorted set, and its score.
+type ZMember struct {
+	Member []byte
+	Score  float64
+}
+
+// A (min or max) score bound of a sorted set range.  See ZInclusive,
+// ZExclusive, ZNegInf and ZPosInf.
+type ZBound string
+
+const (
+	ZNegInf ZBound = "-inf"
+	ZPosInf ZBound = "+inf"
+)
+
+// Returns the bound that includes the score.
+func ZInclusive(score float64) ZBound {
+	return ZBound(formatScore(score))
+}
+
+// Returns the bound that excludes the score.
+func ZExclusive(score float64) ZBound {
+	return ZBound("(" + formatScore(score))
+}
+
+// formats the score, without loss of precision.
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+// Defines a score range of a sorted set (ZRANGEBYSCORE, ZREVRANGEBYSCORE),
+// and optionally, its LIMIT.
+type ZRangeSpec struct {
+	min, max ZBound
+	offset   int64
+	count    int64
+	limit    bool
+}
+
+// Creates a ZRangeSpec of the scores from min to max.
+func NewZRangeSpec(min, max ZBound) *ZRangeSpec {
+	return &ZRangeSpec{min: min, max: max}
+}
+
+// Sets the LIMIT of the range, i.e. skips offset members and returns at most
+// count members (all remaining members if count is negative), and returns
+// the reference.
+func (s *ZRangeSpec) Limit(offset, count int64) *ZRangeSpec {
+	s.offset, s.count, s.limit = offset, count, true
+	return s
+}
+
+// returns the args of the range of the key, in order of the bounds (max
+// first if reverse), and WITHSCORES if scores.
+func (s *ZRangeSpec) args(key string, reverse, scores bool) [][]byte {
+	from, to := s.min, s.max
+	if reverse {
+		from, to = s.max, s.min
+	}
+	args := [][]byte{[]byte(key), []byte(from), []byte(to)}
+	if scores {
+		args = append(args, []byte("WITHSCORES"))
+	}
+	if s.limit {
+		args = append(args, []byte("LIMIT"), []byte(strconv.FormatInt(s.offset, 10)), []byte(strconv.FormatInt(s.count, 10)))
+	}
+	return args
+}
+
+// Conditions and options of ZADD.  See Client.ZaddMembers.
+type ZAddFlag string
+
+const (
+	ZADD_NX ZAddFlag = "NX" // only add new members
+	ZADD_XX ZAddFlag = "XX" // only update existing members
+	ZADD_GT ZAddFlag = "GT" // only update scores to greater scores
+	ZADD_LT ZAddFlag = "LT" // only update scores to lesser scores
+	ZADD_CH ZAddFlag = "CH" // count changed (and not only added) members
+)
+
+// returns the args of ZADD of the members, per the flags.
+func zaddArgs(key string, flags []ZAddFlag, incr bool, members ...ZMember) [][]byte {
+	args := [][]byte{[]byte(key)}
+	for _, flag := range flags {
+		args = append(args, []byte(flag))
+	}
+	if incr {
+		args = append(args, []byte("INCR"))
+	}
+	for _, m := range members {
+		args = append(args, []byte(formatScore(m.Score)), m.Member)
+	}
+	return args
+}
+
+// How the scores of a member in multiple sets are combined by ZUNIONSTORE
+// and ZINTERSTORE.
+type ZAggregate string
+
+const (
+	AGGREGATE_SUM ZAggregate = "SUM"
+	AGGREGATE_MIN ZAggregate = "MIN"
+	AGGREGATE_MAX ZAggregate = "MAX"
+)
+
+// returns the args of ZUNIONSTORE or ZINTERSTORE.  weights (of the keys in
+// order) and aggregate are optional (nil and "").
+func zstoreArgs(dst string, keys []string, weights []float64, aggregate ZAggregate) [][]byte {
+	args := [][]byte{[]byte(dst), []byte(strconv.Itoa(len(keys)))}
+	for _, key := range keys {
+		args = append(args, []byte(key))
+	}
+	if len(weights) > 0 {
+		args = append(args, []byte("WEIGHTS"))
+		for _, w := range weights {
+			args = append(args, []byte(formatScore(w)))
+		}
+	}
+	if aggregate != "" {
+		args = append(args, []byte("AGGREGATE"), []byte(aggregate))
+	}
+	return args
+}
+
+// Returns the members of the WITHSCORES reply, i.e. a multibulk of the
+// members and scores in turn (RESP2), or of [member, score] pairs (RESP3).
+func replyToZMembers(r *Reply) ([]ZMember, Error) {
+	if r.IsNil() {
+		return nil, nil
+	}
+	var members []ZMember
+	for i := 0; i < len(r.Array); i++ {
+		member, score := r.Array[i], (*Reply)(nil)
+		if member.Type == REPLY_ARRAY && len(member.Array) == 2 {
+			member, score = member.Array[0], member.Array[1]
+		} else if i+1 < len(r.Array) {
+			i++
+			score = r.Array[i]
+		} else {
+			return nil, newSystemError("WITHSCORES reply - member without score")
+		}
+		f, err := replyToScore(score)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, ZMember{member.Bulk, f})
+	}
+	return members, nil
+}
+
+// Returns the score of the (bulk or RESP3 double) reply.
+func replyToScore(r *Reply) (float64, Error) {
+	if r.Type == REPLY_DOUBLE {
+		return r.Double, nil
+	}
+	f, e := strconv.ParseFloat(string(r.Bulk), 64)
+	if e != nil {
+		return math.NaN(), newSystemErrorWithCause("invalid score", e)
+	}
+	return f, nil
+}
+
+// Returns the rank of the ZRANK (ZREVRANK) reply, or -1 if nil (i.e. not a
+// member).
+func replyToRank(r *Reply) int64 {
+	if r.IsNil() {
+		return -1
+	}
+	return r.Integer
+}
@@ -0,0 +1,188 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"math"
+	"strings"
+	"testing"
+)
+
+// zsetReply sends canned replies to sorted set requests.
+func zsetReply(args []string) string {
+	request := strings.Join(args, " ")
+	switch {
+	case args[0] == "QUIT":
+		return "+OK\r\n"
+	case args[0] == "ZADD" && strings.Contains(request, " INCR "):
+		if strings.Contains(request, " NX ") {
+			return "$-1\r\n"
+		}
+		return bulkReply("3.5")
+	case strings.HasSuffix(args[0], "RANK") && args[2] == "missing":
+		return "$-1\r\n"
+	case strings.HasSuffix(args[0], "RANK") && args[0] != "ZREMRANGEBYRANK":
+		return ":1\r\n"
+	case args[0] == "ZINCRBY":
+		return bulkReply("2.5")
+	case strings.Contains(request, "WITHSCORES"):
+		return "*4\r\n" + bulkReply("a") + bulkReply("1.5") + bulkReply("b") + bulkReply("inf")
+	case strings.Contains(args[0], "RANGEBYSCORE") && !strings.HasPrefix(args[0], "ZREM"):
+		return "*1\r\n" + bulkReply("a")
+	}
+	return ":2\r\n"
+}
+
+func TestZBounds(t *testing.T) {
+	for bound, expected := range map[ZBound]string{
+		ZInclusive(1.5):    "1.5",
+		ZExclusive(2):      "(2",
+		ZExclusive(0.1):    "(0.1",
+		ZInclusive(1e21):   "1e+21",
+		ZNegInf:            "-inf",
+		ZPosInf:            "+inf",
+		ZExclusive(-1e-07): "(-1e-07",
+	} {
+		if string(bound) != expected {
+			t.Errorf("expected bound %s - got %s", expected, bound)
+		}
+	}
+}
+
+func TestReplyToZMembers(t *testing.T) {
+	bulk := func(s string) *Reply { return &Reply{Type: REPLY_BULK, Bulk: []byte(s)} }
+	double := func(f float64) *Reply { return &Reply{Type: REPLY_DOUBLE, Double: f} }
+
+	resp2 := &Reply{Type: REPLY_ARRAY, Array: []*Reply{bulk("a"), bulk("1"), bulk("b"), bulk("-inf")}}
+	resp3 := &Reply{Type: REPLY_ARRAY, Array: []*Reply{
+		{Type: REPLY_ARRAY, Array: []*Reply{bulk("a"), double(1)}},
+		{Type: REPLY_ARRAY, Array: []*Reply{bulk("b"), double(math.Inf(-1))}},
+	}}
+	for _, r := range []*Reply{resp2, resp3} {
+		members, e := replyToZMembers(r)
+		if e != nil || len(members) != 2 || string(members[0].Member) != "a" || members[0].Score != 1 ||
+			string(members[1].Member) != "b" || !math.IsInf(members[1].Score, -1) {
+			t.Errorf("expected a 1 b -inf - got %v %v", members, e)
+		}
+	}
+
+	odd := &Reply{Type: REPLY_ARRAY, Array: []*Reply{bulk("a")}}
+	if _, e := replyToZMembers(odd); e == nil {
+		t.Error("expected error for member without score")
+	}
+	if _, e := replyToZMembers(&Reply{Type: REPLY_ARRAY, Array: []*Reply{bulk("a"), bulk("x")}}); e == nil {
+		t.Error("expected error for invalid score")
+	}
+}
+
+func TestSortedSetCommands(t *testing.T) {
+	server := newRecordingServer(t, zsetReply)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	expect := func(requests ...string) {
+		t.Helper()
+		if received := server.received(); strings.Join(received, "\n") != strings.Join(requests, "\n") {
+			t.Errorf("expected requests %q - got %q", requests, received)
+		}
+	}
+
+	members := []ZMember{{[]byte("a"), 1}, {[]byte("b"), 2.5}}
+	if n, e := client.ZaddMembers("z", members, ZADD_XX, ZADD_GT, ZADD_CH); e != nil || n != 2 {
+		t.Errorf("expected ZADD 2 - got %d %v", n, e)
+	}
+	expect("ZADD z XX GT CH 1 a 2.5 b")
+
+	if f, e := client.ZaddIncr("z", []byte("a"), 2.5); e != nil || f != 3.5 {
+		t.Errorf("expected ZADD INCR 3.5 - got %f %v", f, e)
+	}
+	if f, e := client.ZaddIncr("z", []byte("a"), 1, ZADD_NX); e != nil || !math.IsNaN(f) {
+		t.Errorf("expected ZADD NX INCR NaN - got %f %v", f, e)
+	}
+	expect("ZADD z INCR 2.5 a", "ZADD z NX INCR 1 a")
+
+	if f, e := client.Zincrby("z", 1.5, []byte("a")); e != nil || f != 2.5 {
+		t.Errorf("expected ZINCRBY 2.5 - got %f %v", f, e)
+	}
+	if r, e := client.Zrank("z", []byte("a")); e != nil || r != 1 {
+		t.Errorf("expected ZRANK 1 - got %d %v", r, e)
+	}
+	if r, e := client.Zrevrank("z", []byte("missing")); e != nil || r != -1 {
+		t.Errorf("expected ZREVRANK -1 - got %d %v", r, e)
+	}
+	expect("ZINCRBY z 1.5 a", "ZRANK z a", "ZREVRANK z missing")
+
+	if n, e := client.Zcount("z", ZExclusive(1), ZPosInf); e != nil || n != 2 {
+		t.Errorf("expected ZCOUNT 2 - got %d %v", n, e)
+	}
+	client.Zremrangebyscore("z", ZNegInf, ZInclusive(0))
+	client.Zremrangebyrank("z", 0, -3)
+	expect("ZCOUNT z (1 +inf", "ZREMRANGEBYSCORE z -inf 0", "ZREMRANGEBYRANK z 0 -3")
+
+	zmembers, e := client.ZrangeWithScores("z", 0, -1)
+	if e != nil || len(zmembers) != 2 || string(zmembers[1].Member) != "b" || !math.IsInf(zmembers[1].Score, 1) {
+		t.Errorf("expected ZRANGE WITHSCORES a 1.5 b inf - got %v %v", zmembers, e)
+	}
+	client.ZrevrangeWithScores("z", 0, 1)
+	expect("ZRANGE z 0 -1 WITHSCORES", "ZREVRANGE z 0 1 WITHSCORES")
+
+	spec := NewZRangeSpec(ZExclusive(1), ZPosInf).Limit(10, 5)
+	if values, e := client.ZrangebyscoreWithSpec("z", spec); e != nil || len(values) != 1 || string(values[0]) != "a" {
+		t.Errorf("expected ZRANGEBYSCORE a - got %q %v", values, e)
+	}
+	client.Zrevrangebyscore("z", spec)
+	client.ZrangebyscoreWithScores("z", NewZRangeSpec(ZNegInf, ZInclusive(3)))
+	if zmembers, e := client.ZrevrangebyscoreWithScores("z", spec); e != nil || len(zmembers) != 2 {
+		t.Errorf("expected ZREVRANGEBYSCORE WITHSCORES of 2 members - got %v %v", zmembers, e)
+	}
+	expect("ZRANGEBYSCORE z (1 +inf LIMIT 10 5",
+		"ZREVRANGEBYSCORE z +inf (1 LIMIT 10 5",
+		"ZRANGEBYSCORE z -inf 3 WITHSCORES",
+		"ZREVRANGEBYSCORE z +inf (1 WITHSCORES LIMIT 10 5")
+
+	if n, e := client.Zunionstore("dst", []string{"z1", "z2"}, []float64{1, 0.5}, AGGREGATE_MAX); e != nil || n != 2 {
+		t.Errorf("expected ZUNIONSTORE 2 - got %d %v", n, e)
+	}
+	client.Zinterstore("dst", []string{"z1", "z2"}, nil, "")
+	expect("ZUNIONSTORE dst 2 z1 z2 WEIGHTS 1 0.5 AGGREGATE MAX", "ZINTERSTORE dst 2 z1 z2")
+
+	if keys := commandKeys(&ZUNIONSTORE, zstoreArgs("dst", []string{"z1", "z2"}, []float64{1, 2}, AGGREGATE_SUM)); len(keys) != 3 ||
+		string(keys[0]) != "dst" || string(keys[2]) != "z2" {
+		t.Errorf("expected keys dst z1 z2 - got %q", keys)
+	}
+}
+
+func TestAsyncSortedSetCommands(t *testing.T) {
+	server := newRecordingServer(t, zsetReply)
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	frank, _ := client.Zrank("z", []byte("missing"))
+	fmembers, _ := client.ZrevrangebyscoreWithScores("z", NewZRangeSpec(ZNegInf, ZPosInf))
+	fincr, _ := client.ZaddIncr("z", []byte("a"), 1)
+	if r, e := frank.Get(); e != nil || r != -1 {
+		t.Errorf("expected ZRANK -1 - got %d %v", r, e)
+	}
+	if members, e := fmembers.Get(); e != nil || len(members) != 2 || members[0].Score != 1.5 {
+		t.Errorf("expected ZREVRANGEBYSCORE WITHSCORES a 1.5 b inf - got %v %v", members, e)
+	}
+	if f, e := fincr.Get(); e != nil || f != 3.5 {
+		t.Errorf("expected ZADD INCR 3.5 - got %f %v", f, e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_sortedset(t *testing.T) {
+	log.Println("-- sortedset test completed")
+}
@@ -90,6 +90,7 @@ const (
 	KEY_KEY_VALUE
 	KEY_CNT_VALUE
 	MULTI_KEY
+	SCRIPT_SPEC
 )
 
 // Response type defines the various flavors of responses from Redis
@@ -105,95 +106,167 @@ const (
 	STATUS
 	BULK
 	MULTI_BULK
+	REPLY  // self-describing - see Reply
+	DOUBLE // bulk (RESP2) or double (RESP3)
+	MAP    // multibulk of keys and values (RESP2) or map (RESP3)
 )
 
 // Describes a given Redis command
 //
+// Code is sent as is, unless it names a subcommand (e.g. "SCRIPT LOAD"), in
+// which case each word is sent as a distinct argument.
 type Command struct {
 	Code     string
 	ReqType  RequestType
 	RespType ResponseType
+	ReadOnly bool // does not write - see NewReplicatedClientWithSpecs
 }
 
 // The supported Command set, with one to one mapping to eponymous Redis command.
 //
 var (
-	AUTH          Command = Command{"AUTH", KEY, STATUS}
-	PING          Command = Command{"PING", NO_ARG, STATUS}
-	QUIT          Command = Command{"QUIT", NO_ARG, VIRTUAL}
-	SET           Command = Command{"SET", KEY_VALUE, STATUS}
-	GET           Command = Command{"GET", KEY, BULK}
-	GETSET        Command = Command{"GETSET", KEY_VALUE, BULK}
-	MGET          Command = Command{"MGET", MULTI_KEY, MULTI_BULK}
-	SETNX         Command = Command{"SETNX", KEY_VALUE, BOOLEAN}
-	INCR          Command = Command{"INCR", KEY, NUMBER}
-	INCRBY        Command = Command{"INCRBY", KEY_NUM, NUMBER}
-	DECR          Command = Command{"DECR", KEY, NUMBER}
-	DECRBY        Command = Command{"DECRBY", KEY_NUM, NUMBER}
-	EXISTS        Command = Command{"EXISTS", KEY, BOOLEAN}
-	DEL           Command = Command{"DEL", KEY, BOOLEAN}
-	TYPE          Command = Command{"TYPE", KEY, STRING}
-	KEYS          Command = Command{"KEYS", KEY, MULTI_BULK}
-	RANDOMKEY     Command = Command{"RANDOMKEY", NO_ARG, BULK}
-	RENAME        Command = Command{"RENAME", KEY_KEY, STATUS}
-	RENAMENX      Command = Command{"RENAMENX", KEY_KEY, BOOLEAN}
-	DBSIZE        Command = Command{"DBSIZE", NO_ARG, NUMBER}
-	EXPIRE        Command = Command{"EXPIRE", KEY_NUM, BOOLEAN}
-	TTL           Command = Command{"TTL", KEY, NUMBER}
-	RPUSH         Command = Command{"RPUSH", KEY_VALUE, STATUS}
-	LPUSH         Command = Command{"LPUSH", KEY_VALUE, STATUS}
-	LLEN          Command = Command{"LLEN", KEY, NUMBER}
-	LRANGE        Command = Command{"LRANGE", KEY_NUM_NUM, MULTI_BULK}
-	LTRIM         Command = Command{"LTRIM", KEY_NUM_NUM, STATUS}
-	LINDEX        Command = Command{"LINDEX", KEY_NUM, BULK}
-	LSET          Command = Command{"LSET", KEY_IDX_VALUE, STATUS}
-	LREM          Command = Command{"LREM", KEY_CNT_VALUE, NUMBER}
-	LPOP          Command = Command{"LPOP", KEY, BULK}
-	BLPOP         Command = Command{"BLPOP", KEY, MULTI_BULK}
-	RPOP          Command = Command{"RPOP", KEY, BULK}
-	BRPOP         Command = Command{"BRPOP", KEY, MULTI_BULK}
-	RPOPLPUSH     Command = Command{"RPOPLPUSH", KEY_VALUE, BULK}
-	BRPOPLPUSH    Command = Command{"BRPOPLPUSH", KEY_VALUE, MULTI_BULK}
-	SADD          Command = Command{"SADD", KEY_VALUE, BOOLEAN}
-	SREM          Command = Command{"SREM", KEY_VALUE, BOOLEAN}
-	SCARD         Command = Command{"SCARD", KEY, NUMBER}
-	SISMEMBER     Command = Command{"SISMEMBER", KEY_VALUE, BOOLEAN}
-	SINTER        Command = Command{"SINTER", MULTI_KEY, MULTI_BULK}
-	SINTERSTORE   Command = Command{"SINTERSTORE", MULTI_KEY, STATUS}
-	SUNION        Command = Command{"SUNION", MULTI_KEY, MULTI_BULK}
-	SUNIONSTORE   Command = Command{"SUNIONSTORE", MULTI_KEY, STATUS}
-	SDIFF         Command = Command{"SDIFF", MULTI_KEY, MULTI_BULK}
-	SDIFFSTORE    Command = Command{"SDIFFSTORE", MULTI_KEY, STATUS}
-	SMEMBERS      Command = Command{"SMEMBERS", KEY, MULTI_BULK}
-	SMOVE         Command = Command{"SMOVE", KEY_KEY_VALUE, BOOLEAN}
-	SRANDMEMBER   Command = Command{"SRANDMEMBER", KEY, BULK}
-	HGET          Command = Command{"HGET", KEY_KEY, BULK}
-	HSET          Command = Command{"HSET", KEY_KEY_VALUE, STATUS}
-	HGETALL       Command = Command{"HGETALL", KEY, MULTI_BULK}
-	ZADD          Command = Command{"ZADD", KEY_IDX_VALUE, BOOLEAN}
-	ZREM          Command = Command{"ZREM", KEY_VALUE, BOOLEAN}
-	ZCARD         Command = Command{"ZCARD", KEY, NUMBER}
-	ZSCORE        Command = Command{"ZSCORE", KEY_VALUE, BULK}
-	ZRANGE        Command = Command{"ZRANGE", KEY_NUM_NUM, MULTI_BULK}
-	ZREVRANGE     Command = Command{"ZREVRANGE", KEY_NUM_NUM, MULTI_BULK}
-	ZRANGEBYSCORE Command = Command{"ZRANGEBYSCORE", KEY_NUM_NUM, MULTI_BULK}
-	SELECT        Command = Command{"SELECT", KEY, STATUS}
-	FLUSHDB       Command = Command{"FLUSHDB", NO_ARG, STATUS}
-	FLUSHALL      Command = Command{"FLUSHALL", NO_ARG, STATUS}
-	MOVE          Command = Command{"MOVE", KEY_NUM, BOOLEAN}
-	SORT          Command = Command{"SORT", KEY_SPEC, MULTI_BULK}
-	SAVE          Command = Command{"SAVE", NO_ARG, STATUS}
-	BGSAVE        Command = Command{"BGSAVE", NO_ARG, STATUS}
-	LASTSAVE      Command = Command{"LASTSAVE", NO_ARG, NUMBER}
-	SHUTDOWN      Command = Command{"SHUTDOWN", NO_ARG, VIRTUAL}
-	INFO          Command = Command{"INFO", NO_ARG, BULK}
-	MONITOR       Command = Command{"MONITOR", NO_ARG, VIRTUAL}
+	AUTH          Command = Command{"AUTH", KEY, STATUS, false}
+	AUTH_USER     Command = Command{"AUTH", KEY_KEY, STATUS, false} // AUTH username password
+	HELLO         Command = Command{"HELLO", MULTI_KEY, REPLY, false}
+	PING          Command = Command{"PING", NO_ARG, STATUS, false}
+	QUIT          Command = Command{"QUIT", NO_ARG, VIRTUAL, false}
+	SET           Command = Command{"SET", KEY_VALUE, STATUS, false}
+	GET           Command = Command{"GET", KEY, BULK, true}
+	GETSET        Command = Command{"GETSET", KEY_VALUE, BULK, false}
+	MGET          Command = Command{"MGET", MULTI_KEY, MULTI_BULK, true}
+	SETNX         Command = Command{"SETNX", KEY_VALUE, BOOLEAN, false}
+	INCR          Command = Command{"INCR", KEY, NUMBER, false}
+	INCRBY        Command = Command{"INCRBY", KEY_NUM, NUMBER, false}
+	DECR          Command = Command{"DECR", KEY, NUMBER, false}
+	DECRBY        Command = Command{"DECRBY", KEY_NUM, NUMBER, false}
+	EXISTS        Command = Command{"EXISTS", KEY, BOOLEAN, true}
+	DEL           Command = Command{"DEL", KEY, BOOLEAN, false}
+	TYPE          Command = Command{"TYPE", KEY, STRING, true}
+	KEYS          Command = Command{"KEYS", KEY, MULTI_BULK, true}
+	RANDOMKEY     Command = Command{"RANDOMKEY", NO_ARG, BULK, true}
+	RENAME        Command = Command{"RENAME", KEY_KEY, STATUS, false}
+	RENAMENX      Command = Command{"RENAMENX", KEY_KEY, BOOLEAN, false}
+	DBSIZE        Command = Command{"DBSIZE", NO_ARG, NUMBER, true}
+	EXPIRE        Command = Command{"EXPIRE", KEY_NUM, BOOLEAN, false}
+	TTL           Command = Command{"TTL", KEY, NUMBER, true}
+	RPUSH         Command = Command{"RPUSH", KEY_VALUE, STATUS, false}
+	LPUSH         Command = Command{"LPUSH", KEY_VALUE, STATUS, false}
+	LLEN          Command = Command{"LLEN", KEY, NUMBER, true}
+	LRANGE        Command = Command{"LRANGE", KEY_NUM_NUM, MULTI_BULK, true}
+	LTRIM         Command = Command{"LTRIM", KEY_NUM_NUM, STATUS, false}
+	LINDEX        Command = Command{"LINDEX", KEY_NUM, BULK, true}
+	LSET          Command = Command{"LSET", KEY_IDX_VALUE, STATUS, false}
+	LREM          Command = Command{"LREM", KEY_CNT_VALUE, NUMBER, false}
+	LPOP          Command = Command{"LPOP", KEY, BULK, false}
+	BLPOP         Command = Command{"BLPOP", KEY, MULTI_BULK, false}
+	RPOP          Command = Command{"RPOP", KEY, BULK, false}
+	BRPOP         Command = Command{"BRPOP", KEY, MULTI_BULK, false}
+	RPOPLPUSH     Command = Command{"RPOPLPUSH", KEY_VALUE, BULK, false}
+	BRPOPLPUSH    Command = Command{"BRPOPLPUSH", KEY_VALUE, MULTI_BULK, false}
+	SADD          Command = Command{"SADD", KEY_VALUE, BOOLEAN, false}
+	SREM          Command = Command{"SREM", KEY_VALUE, BOOLEAN, false}
+	SCARD         Command = Command{"SCARD", KEY, NUMBER, true}
+	SISMEMBER     Command = Command{"SISMEMBER", KEY_VALUE, BOOLEAN, true}
+	SINTER        Command = Command{"SINTER", MULTI_KEY, MULTI_BULK, true}
+	SINTERSTORE   Command = Command{"SINTERSTORE", MULTI_KEY, STATUS, false}
+	SUNION        Command = Command{"SUNION", MULTI_KEY, MULTI_BULK, true}
+	SUNIONSTORE   Command = Command{"SUNIONSTORE", MULTI_KEY, STATUS, false}
+	SDIFF         Command = Command{"SDIFF", MULTI_KEY, MULTI_BULK, true}
+	SDIFFSTORE    Command = Command{"SDIFFSTORE", MULTI_KEY, STATUS, false}
+	SMEMBERS      Command = Command{"SMEMBERS", KEY, MULTI_BULK, true}
+	SMOVE         Command = Command{"SMOVE", KEY_KEY_VALUE, BOOLEAN, false}
+	SRANDMEMBER   Command = Command{"SRANDMEMBER", KEY, BULK, true}
+	HGET          Command = Command{"HGET", KEY_KEY, BULK, true}
+	HSET          Command = Command{"HSET", KEY_KEY_VALUE, STATUS, false}
+	HGETALL       Command = Command{"HGETALL", KEY, MAP, true}
+	HMSET         Command = Command{"HMSET", MULTI_KEY, STATUS, false}
+	HMGET         Command = Command{"HMGET", MULTI_KEY, MULTI_BULK, true}
+	HDEL          Command = Command{"HDEL", MULTI_KEY, NUMBER, false}
+	HEXISTS       Command = Command{"HEXISTS", KEY_KEY, BOOLEAN, true}
+	HLEN          Command = Command{"HLEN", KEY, NUMBER, true}
+	HKEYS         Command = Command{"HKEYS", KEY, MULTI_BULK, true}
+	HVALS         Command = Command{"HVALS", KEY, MULTI_BULK, true}
+	HINCRBY       Command = Command{"HINCRBY", KEY_KEY_VALUE, NUMBER, false}
+	HINCRBYFLOAT  Command = Command{"HINCRBYFLOAT", KEY_KEY_VALUE, DOUBLE, false}
+	HSETNX        Command = Command{"HSETNX", KEY_KEY_VALUE, BOOLEAN, false}
+	HSTRLEN       Command = Command{"HSTRLEN", KEY_KEY, NUMBER, true}
+	ZADD          Command = Command{"ZADD", KEY_IDX_VALUE, BOOLEAN, false}
+	ZREM          Command = Command{"ZREM", KEY_VALUE, BOOLEAN, false}
+	ZCARD         Command = Command{"ZCARD", KEY, NUMBER, true}
+	ZSCORE        Command = Command{"ZSCORE", KEY_VALUE, DOUBLE, true}
+	ZRANGE        Command = Command{"ZRANGE", KEY_NUM_NUM, MULTI_BULK, true}
+	ZREVRANGE     Command = Command{"ZREVRANGE", KEY_NUM_NUM, MULTI_BULK, true}
+	ZRANGEBYSCORE Command = Command{"ZRANGEBYSCORE", KEY_NUM_NUM, MULTI_BULK, true}
+	SELECT        Command = Command{"SELECT", KEY, STATUS, false}
+	FLUSHDB       Command = Command{"FLUSHDB", NO_ARG, STATUS, false}
+	FLUSHALL      Command = Command{"FLUSHALL", NO_ARG, STATUS, false}
+	MOVE          Command = Command{"MOVE", KEY_NUM, BOOLEAN, false}
+	SORT          Command = Command{"SORT", KEY_SPEC, MULTI_BULK, false}
+	SORT_STORE    Command = Command{"SORT", KEY_SPEC, NUMBER, false}
+	SAVE          Command = Command{"SAVE", NO_ARG, STATUS, false}
+	BGSAVE        Command = Command{"BGSAVE", NO_ARG, STATUS, false}
+	LASTSAVE      Command = Command{"LASTSAVE", NO_ARG, NUMBER, false}
+	SHUTDOWN      Command = Command{"SHUTDOWN", NO_ARG, VIRTUAL, false}
+	INFO          Command = Command{"INFO", NO_ARG, BULK, false}
+	MONITOR       Command = Command{"MONITOR", NO_ARG, VIRTUAL, false}
 	// TODO	SORT		(RequestType.MULTI_KEY,		ResponseType.MULTI_BULK),
-	PUBLISH      Command = Command{"PUBLISH", KEY_VALUE, NUMBER}
-	SUBSCRIBE    Command = Command{"SUBSCRIBE", MULTI_KEY, MULTI_BULK}
-	UNSUBSCRIBE  Command = Command{"UNSUBSCRIBE", MULTI_KEY, MULTI_BULK}
-	PSUBSCRIBE   Command = Command{"PSUBSCRIBE", MULTI_KEY, MULTI_BULK}
-	PUNSUBSCRIBE Command = Command{"PUNSUBSCRIBE", MULTI_KEY, MULTI_BULK}
+	PUBLISH      Command = Command{"PUBLISH", KEY_VALUE, NUMBER, false}
+	SUBSCRIBE    Command = Command{"SUBSCRIBE", MULTI_KEY, MULTI_BULK, false}
+	UNSUBSCRIBE  Command = Command{"UNSUBSCRIBE", MULTI_KEY, MULTI_BULK, false}
+	PSUBSCRIBE   Command = Command{"PSUBSCRIBE", MULTI_KEY, MULTI_BULK, false}
+	PUNSUBSCRIBE Command = Command{"PUNSUBSCRIBE", MULTI_KEY, MULTI_BULK, false}
+	MULTI        Command = Command{"MULTI", NO_ARG, STATUS, false}
+	EXEC         Command = Command{"EXEC", NO_ARG, MULTI_BULK, false}
+	DISCARD      Command = Command{"DISCARD", NO_ARG, STATUS, false}
+	WATCH        Command = Command{"WATCH", MULTI_KEY, STATUS, false}
+	UNWATCH      Command = Command{"UNWATCH", NO_ARG, STATUS, false}
+
+	EVAL          Command = Command{"EVAL", SCRIPT_SPEC, REPLY, false}
+	EVALSHA       Command = Command{"EVALSHA", SCRIPT_SPEC, REPLY, false}
+	SCRIPT_LOAD   Command = Command{"SCRIPT LOAD", KEY, BULK, false}
+	SCRIPT_EXISTS Command = Command{"SCRIPT EXISTS", MULTI_KEY, REPLY, false}
+	SCRIPT_FLUSH  Command = Command{"SCRIPT FLUSH", NO_ARG, STATUS, false}
+
+	CLIENT_ID       Command = Command{"CLIENT ID", NO_ARG, NUMBER, false}
+	CLIENT_TRACKING Command = Command{"CLIENT TRACKING", MULTI_KEY, STATUS, false}
+
+	CLUSTER_SLOTS Command = Command{"CLUSTER SLOTS", NO_ARG, REPLY, false}
+	ASKING        Command = Command{"ASKING", NO_ARG, STATUS, false}
+
+	ROLE                 Command = Command{"ROLE", NO_ARG, REPLY, false}
+	SENTINEL_MASTER_ADDR Command = Command{"SENTINEL get-master-addr-by-name", KEY, MULTI_BULK, false}
+
+	SCAN  Command = Command{"SCAN", MULTI_KEY, REPLY, true}
+	SSCAN Command = Command{"SSCAN", MULTI_KEY, REPLY, true}
+	HSCAN Command = Command{"HSCAN", MULTI_KEY, REPLY, true}
+	ZSCAN Command = Command{"ZSCAN", MULTI_KEY, REPLY, true}
+
+	// sorted set commands of distinct reply types (per their args) are
+	// distinct Commands, e.g. ZRANGE_WITHSCORES
+	ZADD_MEMBERS                Command = Command{"ZADD", MULTI_KEY, NUMBER, false}
+	ZADD_INCR                   Command = Command{"ZADD", MULTI_KEY, DOUBLE, false}
+	ZINCRBY                     Command = Command{"ZINCRBY", KEY_IDX_VALUE, DOUBLE, false}
+	ZRANK                       Command = Command{"ZRANK", KEY_VALUE, REPLY, true}
+	ZREVRANK                    Command = Command{"ZREVRANK", KEY_VALUE, REPLY, true}
+	ZCOUNT                      Command = Command{"ZCOUNT", KEY_NUM_NUM, NUMBER, true}
+	ZREMRANGEBYSCORE            Command = Command{"ZREMRANGEBYSCORE", KEY_NUM_NUM, NUMBER, false}
+	ZREMRANGEBYRANK             Command = Command{"ZREMRANGEBYRANK", KEY_NUM_NUM, NUMBER, false}
+	ZREVRANGEBYSCORE            Command = Command{"ZREVRANGEBYSCORE", MULTI_KEY, MULTI_BULK, true}
+	ZRANGE_WITHSCORES           Command = Command{"ZRANGE", MULTI_KEY, REPLY, true}
+	ZREVRANGE_WITHSCORES        Command = Command{"ZREVRANGE", MULTI_KEY, REPLY, true}
+	ZRANGEBYSCORE_WITHSCORES    Command = Command{"ZRANGEBYSCORE", MULTI_KEY, REPLY, true}
+	ZREVRANGEBYSCORE_WITHSCORES Command = Command{"ZREVRANGEBYSCORE", MULTI_KEY, REPLY, true}
+	ZUNIONSTORE                 Command = Command{"ZUNIONSTORE", MULTI_KEY, NUMBER, false}
+	ZINTERSTORE                 Command = Command{"ZINTERSTORE", MULTI_KEY, NUMBER, false}
+
+	// streams - see stream.go
+	XADD      Command = Command{"XADD", MULTI_KEY, BULK, false}
+	XRANGE    Command = Command{"XRANGE", MULTI_KEY, REPLY, true}
+	XREVRANGE Command = Command{"XREVRANGE", MULTI_KEY, REPLY, true}
+	XLEN      Command = Command{"XLEN", KEY, NUMBER, true}
+	XDEL      Command = Command{"XDEL", MULTI_KEY, NUMBER, false}
+	XTRIM     Command = Command{"XTRIM", MULTI_KEY, NUMBER, false}
+	XREAD     Command = Command{"XREAD", MULTI_KEY, REPLY, true}
 )
 
 // ----------------------------------------------------------------------
@@ -249,6 +322,9 @@ func anyClientRedisErrSpec(spec *MethodSpec, method string) *MethodSpec {
 	// Redis-Spec: Background save may be running already and can raise -ERR
 	case "Bgsave":
 		spec.NoRedisErr = false
+	// Redis-Spec: random scripts may not compile, and random sha1s are NOSCRIPT
+	case "Eval", "Evalsha":
+		spec.NoRedisErr = false
 	}
 
 	return spec
@@ -0,0 +1,237 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// stream support
+// -----------------------------------------------------------------------------
+
+// An entry of a stream: its ID (e.g. "1526919030474-0") and fields.
+type StreamEntry struct {
+	ID     string
+	Fields map[string][]byte
+}
+
+// Defines the trimming of a stream by XADD or XTRIM, per MAXLEN (see
+// NewMaxLenTrim) or MINID (see NewMinIDTrim).
+type StreamTrimSpec struct {
+	strategy  string // MAXLEN or MINID
+	threshold string
+	approx    bool
+	limit     int64
+}
+
+// Creates a StreamTrimSpec that trims the stream to its latest maxlen
+// entries.
+func NewMaxLenTrim(maxlen int64) *StreamTrimSpec {
+	return &StreamTrimSpec{strategy: "MAXLEN", threshold: strconv.FormatInt(maxlen, 10)}
+}
+
+// Creates a StreamTrimSpec that evicts the entries with IDs lower than id
+// (Redis 6.2 or later).
+func NewMinIDTrim(id string) *StreamTrimSpec {
+	return &StreamTrimSpec{strategy: "MINID", threshold: id}
+}
+
+// Trims approximately (~), i.e. only whole macro nodes, which is much more
+// efficient, and returns the reference.
+func (t *StreamTrimSpec) Approx() *StreamTrimSpec {
+	t.approx = true
+	return t
+}
+
+// Sets the LIMIT of the entries evicted by an approximate trim (Redis 6.2
+// or later), and returns the reference.  See Approx.
+func (t *StreamTrimSpec) Limit(limit int64) *StreamTrimSpec {
+	t.limit = limit
+	return t
+}
+
+// returns the args of the trim - none if nil.
+func (t *StreamTrimSpec) args() [][]byte {
+	if t == nil {
+		return nil
+	}
+	args := [][]byte{[]byte(t.strategy)}
+	if t.approx {
+		args = append(args, []byte("~"))
+	}
+	args = append(args, []byte(t.threshold))
+	if t.approx && t.limit > 0 {
+		args = append(args, []byte("LIMIT"), []byte(strconv.FormatInt(t.limit, 10)))
+	}
+	return args
+}
+
+// returns the args of XADD of the entry, in sorted field order.  The ID is
+// generated by the server ("*") if id is "".
+func xaddArgs(key string, id string, fields map[string][]byte, trim *StreamTrimSpec) [][]byte {
+	if id == "" {
+		id = "*"
+	}
+	args := append([][]byte{[]byte(key)}, trim.args()...)
+	return append(args, hashArgs(id, fields)...)
+}
+
+// returns the args of XRANGE (XREVRANGE) from start to end (end to start),
+// and COUNT if count > 0.
+func xrangeArgs(key string, from, to string, count int64) [][]byte {
+	args := [][]byte{[]byte(key), []byte(from), []byte(to)}
+	if count > 0 {
+		args = append(args, []byte("COUNT"), []byte(strconv.FormatInt(count, 10)))
+	}
+	return args
+}
+
+// returns the args of XREAD of the streams (by key, in sorted order) after
+// their IDs, with COUNT if count > 0, and BLOCK if block.
+func xreadArgs(streams map[string]string, count int64, block bool, timeout time.Duration) [][]byte {
+	var args [][]byte
+	if count > 0 {
+		args = append(args, []byte("COUNT"), []byte(strconv.FormatInt(count, 10)))
+	}
+	if block {
+		ms := int64(timeout / time.Millisecond)
+		if ms == 0 && timeout > 0 {
+			ms = 1 // 0 blocks indefinitely
+		}
+		args = append(args, []byte("BLOCK"), []byte(strconv.FormatInt(ms, 10)))
+	}
+	args = append(args, []byte("STREAMS"))
+
+	keys := make([]string, 0, len(streams))
+	for key := range streams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, []byte(key))
+	}
+	for _, key := range keys {
+		args = append(args, []byte(streams[key]))
+	}
+	return args
+}
+
+// Returns the keys of the XREAD request, i.e. the first half of the args
+// following STREAMS.
+func xreadKeys(args [][]byte) [][]byte {
+	for i, arg := range args {
+		if string(arg) == "STREAMS" {
+			streams := args[i+1:]
+			return streams[:len(streams)/2]
+		}
+	}
+	return nil
+}
+
+// Implemented by connections that route blocking requests by their keys,
+// e.g. to a shard.
+type blockingRouter interface {
+	blockingSource(cmd *Command, args [][]byte) (txConnection, Error)
+}
+
+// Returns the source of dedicated connections for the blocking request on
+// the connection, or an error if it does not support blocking requests.
+func blockingSourceOf(conn interface{}, cmd *Command, args [][]byte) (txConnection, Error) {
+	switch conn := conn.(type) {
+	case blockingRouter:
+		return conn.blockingSource(cmd, args)
+	case txConnection:
+		return conn, nil
+	}
+	return nil, newSystemError("connection does not support blocking reads")
+}
+
+// Services the blocking request on a dedicated connection of the source,
+// i.e. the connection of a sync client (or one checked out of its pool),
+// or one checked out of the dedicated pool of async clients, so that it
+// does not stall the pipeline.
+func serviceBlocking(source txConnection, cmd *Command, args [][]byte, timeout time.Duration) (Response, Error) {
+	hdl, err := source.checkout()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hdl.serviceBlockingRequest(cmd, args, timeout)
+	source.release(hdl, err != nil && !err.IsRedisError())
+	return resp, err
+}
+
+// Returns the entry of the [id, [field, value ...]] reply.
+func replyToStreamEntry(r *Reply) (StreamEntry, Error) {
+	if r.IsNil() || len(r.Array) != 2 {
+		return StreamEntry{}, newSystemError("stream entry reply - expected [id, fields]")
+	}
+	id, values := r.Array[0], r.Array[1].Array
+	if len(values)%2 != 0 {
+		return StreamEntry{}, newSystemErrorf("stream entry %s - field without value", id.Bulk)
+	}
+	entry := StreamEntry{string(id.Bulk), make(map[string][]byte, len(values)/2)}
+	for i := 0; i < len(values); i += 2 {
+		entry.Fields[string(values[i].Bulk)] = values[i+1].Bulk
+	}
+	return entry, nil
+}
+
+// Returns the entries of the XRANGE (XREVRANGE) reply.
+func replyToStreamEntries(r *Reply) ([]StreamEntry, Error) {
+	if r.IsNil() {
+		return nil, nil
+	}
+	entries := make([]StreamEntry, len(r.Array))
+	for i, er := range r.Array {
+		entry, err := replyToStreamEntry(er)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+// Returns the entries by stream of the XREAD reply, i.e. a multibulk of
+// [stream, entries] pairs (RESP2), or a map of the entries by stream
+// (RESP3).  nil if the (blocking) read timed out.
+func replyToStreams(r *Reply) (map[string][]StreamEntry, Error) {
+	if r.IsNil() {
+		return nil, nil
+	}
+	var pairs []*Reply
+	if r.Type == REPLY_MAP {
+		pairs = r.Array
+	} else {
+		for _, pair := range r.Array {
+			if len(pair.Array) != 2 {
+				return nil, newSystemError("XREAD reply - expected [stream, entries]")
+			}
+			pairs = append(pairs, pair.Array...)
+		}
+	}
+	streams := make(map[string][]StreamEntry, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		entries, err := replyToStreamEntries(pairs[i+1])
+		if err != nil {
+			return nil, err
+		}
+		streams[string(pairs[i].Bulk)] = entries
+	}
+	return streams, nil
+}
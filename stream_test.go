@@ -0,0 +1,239 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeStreamEntries = "*2\r\n" +
+	"*2\r\n$3\r\n1-0\r\n*4\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\nb\r\n$1\r\n2\r\n" +
+	"*2\r\n$3\r\n2-0\r\n*2\r\n$1\r\na\r\n$1\r\n3\r\n"
+
+// streamReply sends canned replies to stream requests.  XREAD BLOCK of the
+// stream "idle" waits for the BLOCK timeout and replies nil, and of other
+// streams replies after 100ms.
+func streamReply(args []string) string {
+	switch args[0] {
+	case "QUIT":
+		return "+OK\r\n"
+	case "XADD":
+		return bulkReply("3-0")
+	case "XRANGE", "XREVRANGE":
+		return fakeStreamEntries
+	case "XREAD":
+		for i, arg := range args {
+			if arg != "BLOCK" {
+				continue
+			}
+			if args[len(args)-2] == "idle" {
+				ms, _ := strconv.Atoi(args[i+1])
+				time.Sleep(time.Duration(ms) * time.Millisecond)
+				return "*-1\r\n"
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		return "*1\r\n*2\r\n" + bulkReply("s") + fakeStreamEntries
+	}
+	return ":2\r\n"
+}
+
+func TestStreamArgs(t *testing.T) {
+	join := func(args [][]byte) string {
+		var strs []string
+		for _, arg := range args {
+			strs = append(strs, string(arg))
+		}
+		return strings.Join(strs, " ")
+	}
+
+	fields := map[string][]byte{"b": []byte("2"), "a": []byte("1")}
+	for _, test := range []struct {
+		id       string
+		trim     *StreamTrimSpec
+		expected string
+	}{
+		{"", nil, "s * a 1 b 2"},
+		{"5-1", NewMaxLenTrim(1000), "s MAXLEN 1000 5-1 a 1 b 2"},
+		{"*", NewMaxLenTrim(10).Approx().Limit(100), "s MAXLEN ~ 10 LIMIT 100 * a 1 b 2"},
+		{"", NewMinIDTrim("7-0").Limit(100), "s MINID 7-0 * a 1 b 2"},
+	} {
+		if actual := join(xaddArgs("s", test.id, fields, test.trim)); actual != test.expected {
+			t.Errorf("expected XADD %s - got %s", test.expected, actual)
+		}
+	}
+
+	streams := map[string]string{"s2": "$", "s1": "0"}
+	for _, test := range []struct {
+		count    int64
+		block    bool
+		timeout  time.Duration
+		expected string
+	}{
+		{0, false, 0, "STREAMS s1 s2 0 $"},
+		{10, true, 0, "COUNT 10 BLOCK 0 STREAMS s1 s2 0 $"},
+		{0, true, 1500 * time.Microsecond, "BLOCK 1 STREAMS s1 s2 0 $"},
+		{0, true, time.Microsecond, "BLOCK 1 STREAMS s1 s2 0 $"},
+	} {
+		if actual := join(xreadArgs(streams, test.count, test.block, test.timeout)); actual != test.expected {
+			t.Errorf("expected XREAD %s - got %s", test.expected, actual)
+		}
+	}
+	if keys := join(commandKeys(&XREAD, xreadArgs(streams, 10, true, time.Second))); keys != "s1 s2" {
+		t.Errorf("expected XREAD keys s1 s2 - got %s", keys)
+	}
+}
+
+func TestReplyToStreams(t *testing.T) {
+	bulk := func(s string) *Reply { return &Reply{Type: REPLY_BULK, Bulk: []byte(s)} }
+	array := func(elems ...*Reply) *Reply { return &Reply{Type: REPLY_ARRAY, Array: elems} }
+
+	entries := array(array(bulk("1-0"), array(bulk("a"), bulk("1"))), array(bulk("2-0"), array()))
+	resp2 := array(array(bulk("s1"), entries), array(bulk("s2"), array()))
+	resp3 := &Reply{Type: REPLY_MAP, Array: []*Reply{bulk("s1"), entries, bulk("s2"), array()}}
+	for _, r := range []*Reply{resp2, resp3} {
+		streams, e := replyToStreams(r)
+		if e != nil || len(streams) != 2 || len(streams["s1"]) != 2 || len(streams["s2"]) != 0 {
+			t.Fatalf("expected s1 of 2 entries and empty s2 - got %v %v", streams, e)
+		}
+		entry := streams["s1"][0]
+		if entry.ID != "1-0" || len(entry.Fields) != 1 || string(entry.Fields["a"]) != "1" {
+			t.Errorf("expected entry 1-0 a 1 - got %v", entry)
+		}
+		if entry := streams["s1"][1]; entry.ID != "2-0" || len(entry.Fields) != 0 {
+			t.Errorf("expected entry 2-0 without fields - got %v", entry)
+		}
+	}
+
+	if streams, e := replyToStreams(&Reply{Type: REPLY_NIL}); e != nil || streams != nil {
+		t.Errorf("expected nil on timeout - got %v %v", streams, e)
+	}
+	if _, e := replyToStreamEntries(array(array(bulk("1-0"), array(bulk("a"))))); e == nil {
+		t.Error("expected error for field without value")
+	}
+	if _, e := replyToStreams(array(array(bulk("s1")))); e == nil {
+		t.Error("expected error for stream without entries")
+	}
+}
+
+func TestStreamCommands(t *testing.T) {
+	server := newRecordingServer(t, streamReply)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec().ReadTimeout(50 * time.Millisecond))
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	expect := func(requests ...string) {
+		t.Helper()
+		if received := server.received(); strings.Join(received, "\n") != strings.Join(requests, "\n") {
+			t.Errorf("expected requests %q - got %q", requests, received)
+		}
+	}
+
+	if id, e := client.Xadd("s", "", map[string][]byte{"a": []byte("1")}, NewMaxLenTrim(10).Approx()); e != nil || id != "3-0" {
+		t.Errorf("expected XADD 3-0 - got %s %v", id, e)
+	}
+	expect("XADD s MAXLEN ~ 10 * a 1")
+
+	entries, e := client.Xrange("s", "-", "+", 2)
+	if e != nil || len(entries) != 2 || entries[0].ID != "1-0" || string(entries[0].Fields["b"]) != "2" ||
+		string(entries[1].Fields["a"]) != "3" {
+		t.Errorf("expected XRANGE 1-0 a 1 b 2, 2-0 a 3 - got %v %v", entries, e)
+	}
+	client.Xrevrange("s", "+", "-", 0)
+	expect("XRANGE s - + COUNT 2", "XREVRANGE s + -")
+
+	if n, e := client.Xlen("s"); e != nil || n != 2 {
+		t.Errorf("expected XLEN 2 - got %d %v", n, e)
+	}
+	client.Xdel("s", "1-0", "2-0")
+	client.Xtrim("s", NewMinIDTrim("2-0"))
+	expect("XLEN s", "XDEL s 1-0 2-0", "XTRIM s MINID 2-0")
+
+	streams, e := client.Xread(map[string]string{"s": "0"}, 5)
+	if e != nil || len(streams) != 1 || len(streams["s"]) != 2 {
+		t.Errorf("expected XREAD of 2 entries of s - got %v %v", streams, e)
+	}
+	expect("XREAD COUNT 5 STREAMS s 0")
+
+	// the read timeout (50ms) is extended by the block timeout
+	if streams, e := client.XreadBlock(map[string]string{"s": "$"}, 0, time.Second); e != nil || len(streams["s"]) != 2 {
+		t.Errorf("expected XREAD BLOCK of 2 entries of s - got %v %v", streams, e)
+	}
+	if streams, e := client.XreadBlock(map[string]string{"idle": "$"}, 0, 100*time.Millisecond); e != nil || streams != nil {
+		t.Errorf("expected XREAD BLOCK to time out - got %v %v", streams, e)
+	}
+	expect("XREAD BLOCK 1000 STREAMS s $", "XREAD BLOCK 100 STREAMS idle $")
+
+	// or lifted if blocking indefinitely, and restored after
+	if _, e := client.XreadBlock(map[string]string{"s": "$"}, 0, 0); e != nil {
+		t.Errorf("expected XREAD BLOCK 0 - got %v", e)
+	}
+	hdl := client.(*syncClient).conn.(*connHdl)
+	if conn := hdl.conn.(*timeoutConn); conn.rTimeout != 50*time.Millisecond {
+		t.Errorf("expected read timeout 50ms to be restored - got %s", conn.rTimeout)
+	}
+
+	// and extended on the new connection of a faulted one
+	hdl.fault()
+	if streams, e := client.XreadBlock(map[string]string{"s": "$"}, 0, time.Second); e != nil || len(streams["s"]) != 2 {
+		t.Errorf("expected XREAD BLOCK on a new connection - got %v %v", streams, e)
+	}
+}
+
+func TestAsyncStreamCommands(t *testing.T) {
+	server := newRecordingServer(t, streamReply)
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	fid, _ := client.Xadd("s", "5-0", map[string][]byte{"a": []byte("1")}, nil)
+	fentries, _ := client.Xrevrange("s", "+", "-", 1)
+	if id, e := fid.Get(); e != nil || string(id) != "3-0" {
+		t.Errorf("expected XADD 3-0 - got %s %v", id, e)
+	}
+	if entries, e := fentries.Get(); e != nil || len(entries) != 2 {
+		t.Errorf("expected XREVRANGE of 2 entries - got %v %v", entries, e)
+	}
+
+	// the blocking read does not stall the pipeline
+	fblock, e := client.XreadBlock(map[string]string{"s": "$"}, 0, time.Second)
+	if e != nil {
+		t.Fatalf("XreadBlock - %s", e)
+	}
+	flen, _ := client.Xlen("s")
+	if n, e, timedout := flen.TryGet(50 * time.Millisecond); e != nil || timedout || n != 2 {
+		t.Errorf("expected XLEN 2 while blocked - got %d %v timedout:%t", n, e, timedout)
+	}
+	if streams, e := fblock.Get(); e != nil || len(streams["s"]) != 2 {
+		t.Errorf("expected XREAD BLOCK of 2 entries of s - got %v %v", streams, e)
+	}
+	if n := server.connectionCount(); n != 2 {
+		t.Errorf("expected a dedicated connection for XREAD BLOCK - got %d connections", n)
+	}
+
+	// which is reused by subsequent blocking reads
+	fidle, _ := client.XreadBlock(map[string]string{"idle": "$"}, 0, 10*time.Millisecond)
+	if streams, e := fidle.Get(); e != nil || streams != nil {
+		t.Errorf("expected XREAD BLOCK to time out - got %v %v", streams, e)
+	}
+	if n := server.connectionCount(); n != 2 {
+		t.Errorf("expected the dedicated connection to be reused - got %d connections", n)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_stream(t *testing.T) {
+	log.Println("-- stream test completed")
+}
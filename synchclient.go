@@ -20,6 +20,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -59,6 +60,29 @@ func NewSynchClientWithSpec(spec *ConnectionSpec) (c Client, err Error) {
 	return _c, nil
 }
 
+// Create a new pooled syncClient using the default ConnectionSpec.
+// See NewPooledSynchClientWithSpec.
+//
+func NewPooledSynchClient() (c Client, err Error) {
+	spec := DefaultSpec()
+	return NewPooledSynchClientWithSpec(spec)
+}
+
+// Create a new syncClient backed by a connection pool per the specified
+// ConnectionSpec (see MaxIdle, MaxActive, IdleTimeout and WaitOnExhaustion).
+// Each request checks out a connection from the pool, so unlike the client
+// returned by NewSynchClientWithSpec, the pooled client can be shared by
+// multiple goroutines.  Quit() closes the pool.
+//
+func NewPooledSynchClientWithSpec(spec *ConnectionSpec) (c Client, err Error) {
+	_c := new(syncClient)
+	_c.conn, err = NewPooledSyncConnection(spec)
+	if err != nil {
+		return nil, withError(err)
+	}
+	return _c, nil
+}
+
 // -----------------------------------------------------------------------------
 // interface redis.Client support
 // -----------------------------------------------------------------------------
@@ -137,18 +161,19 @@ func convAndSplit(buff []byte) []string {
 	return strings.SplitN(bytes.NewBuffer(buff).String(), " ", 0)
 }
 
-/***
 // Redis SORT command.
-func (c *syncClient) Sort (arg0 string) (result redis.Sort, err Error){
-	arg0bytes := []byte (arg0);
+func (c *syncClient) Sort(arg0 string, spec *SortSpec) (result SortResult, err Error) {
+	cmd := spec.command()
 
-	var resp Response;
-	resp, err = c.conn.ServiceRequest(&SORT, [][]byte{arg0bytes});
-	if err == nil {result = resp.GetMultiBulkData();}
-	return result, err;
+	var resp Response
+	resp, err = c.conn.ServiceRequest(cmd, spec.args(arg0))
+	if err == nil {
+		result = sortResult(cmd, resp)
+	}
+	return result, err
 
 }
-***/
+
 // Redis EXISTS command.
 func (c *syncClient) Exists(arg0 string) (result bool, err Error) {
 	arg0bytes := []byte(arg0)
@@ -786,13 +811,7 @@ func (c *syncClient) Zscore(arg0 string, arg1 []byte) (result float64, err Error
 	var resp Response
 	resp, err = c.conn.ServiceRequest(&ZSCORE, [][]byte{arg0bytes, arg1bytes})
 	if err == nil {
-		buff := resp.GetBulkData()
-		//		fnum, oserr := strconv.Atof64(bytes.NewBuffer(buff).String());
-		//		if oserr != nil {
-		//			err = newSystemErrorWithCause("Expected a parsable byte representation of a float64 in Zscore!", oserr);
-		//		}
-		//		result = fnum;
-		result, err = Btof64(buff)
+		result, err = zscore(resp.GetDoubleValue())
 	}
 	return result, err
 
@@ -851,6 +870,183 @@ func (c *syncClient) Zrangebyscore(arg0 string, arg1 float64, arg2 float64) (res
 
 }
 
+// Redis ZADD command, of multiple members.
+func (c *syncClient) ZaddMembers(arg0 string, arg1 []ZMember, flags ...ZAddFlag) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZADD_MEMBERS, zaddArgs(arg0, flags, false, arg1...))
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis ZADD INCR command.
+func (c *syncClient) ZaddIncr(arg0 string, arg1 []byte, arg2 float64, flags ...ZAddFlag) (result float64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZADD_INCR, zaddArgs(arg0, flags, true, ZMember{arg1, arg2}))
+	if err == nil {
+		result = resp.GetDoubleValue()
+	}
+	return result, err
+
+}
+
+// Redis ZINCRBY command.
+func (c *syncClient) Zincrby(arg0 string, arg1 float64, arg2 []byte) (result float64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZINCRBY, [][]byte{[]byte(arg0), []byte(formatScore(arg1)), arg2})
+	if err == nil {
+		result = resp.GetDoubleValue()
+	}
+	return result, err
+
+}
+
+// Redis ZRANK command.
+func (c *syncClient) Zrank(arg0 string, arg1 []byte) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZRANK, [][]byte{[]byte(arg0), arg1})
+	if err == nil {
+		result = replyToRank(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANK command.
+func (c *syncClient) Zrevrank(arg0 string, arg1 []byte) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZREVRANK, [][]byte{[]byte(arg0), arg1})
+	if err == nil {
+		result = replyToRank(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis ZCOUNT command.
+func (c *syncClient) Zcount(arg0 string, min ZBound, max ZBound) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZCOUNT, [][]byte{[]byte(arg0), []byte(min), []byte(max)})
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis ZREMRANGEBYSCORE command.
+func (c *syncClient) Zremrangebyscore(arg0 string, min ZBound, max ZBound) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZREMRANGEBYSCORE, [][]byte{[]byte(arg0), []byte(min), []byte(max)})
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis ZREMRANGEBYRANK command.
+func (c *syncClient) Zremrangebyrank(arg0 string, arg1 int64, arg2 int64) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZREMRANGEBYRANK, [][]byte{[]byte(arg0), []byte(fmt.Sprintf("%d", arg1)), []byte(fmt.Sprintf("%d", arg2))})
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis ZRANGE WITHSCORES command.
+func (c *syncClient) ZrangeWithScores(arg0 string, arg1 int64, arg2 int64) (result []ZMember, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZRANGE_WITHSCORES, [][]byte{[]byte(arg0), []byte(fmt.Sprintf("%d", arg1)), []byte(fmt.Sprintf("%d", arg2)), []byte("WITHSCORES")})
+	if err == nil {
+		result, err = replyToZMembers(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANGE WITHSCORES command.
+func (c *syncClient) ZrevrangeWithScores(arg0 string, arg1 int64, arg2 int64) (result []ZMember, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZREVRANGE_WITHSCORES, [][]byte{[]byte(arg0), []byte(fmt.Sprintf("%d", arg1)), []byte(fmt.Sprintf("%d", arg2)), []byte("WITHSCORES")})
+	if err == nil {
+		result, err = replyToZMembers(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis ZRANGEBYSCORE command, of the (exclusive or infinite) bounds and
+// LIMIT of the spec.
+func (c *syncClient) ZrangebyscoreWithSpec(arg0 string, spec *ZRangeSpec) (result [][]byte, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZRANGEBYSCORE, spec.args(arg0, false, false))
+	if err == nil {
+		result = resp.GetMultiBulkData()
+	}
+	return result, err
+
+}
+
+// Redis ZRANGEBYSCORE WITHSCORES command.  See ZrangebyscoreWithSpec.
+func (c *syncClient) ZrangebyscoreWithScores(arg0 string, spec *ZRangeSpec) (result []ZMember, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZRANGEBYSCORE_WITHSCORES, spec.args(arg0, false, true))
+	if err == nil {
+		result, err = replyToZMembers(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANGEBYSCORE command.
+func (c *syncClient) Zrevrangebyscore(arg0 string, spec *ZRangeSpec) (result [][]byte, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZREVRANGEBYSCORE, spec.args(arg0, true, false))
+	if err == nil {
+		result = resp.GetMultiBulkData()
+	}
+	return result, err
+
+}
+
+// Redis ZREVRANGEBYSCORE WITHSCORES command.  See Zrevrangebyscore.
+func (c *syncClient) ZrevrangebyscoreWithScores(arg0 string, spec *ZRangeSpec) (result []ZMember, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZREVRANGEBYSCORE_WITHSCORES, spec.args(arg0, true, true))
+	if err == nil {
+		result, err = replyToZMembers(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis ZUNIONSTORE command.
+func (c *syncClient) Zunionstore(arg0 string, arg1 []string, weights []float64, aggregate ZAggregate) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZUNIONSTORE, zstoreArgs(arg0, arg1, weights, aggregate))
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis ZINTERSTORE command.  See Zunionstore.
+func (c *syncClient) Zinterstore(arg0 string, arg1 []string, weights []float64, aggregate ZAggregate) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&ZINTERSTORE, zstoreArgs(arg0, arg1, weights, aggregate))
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
 // Redis HGET command.
 func (c *syncClient) Hget(arg0 string, arg1 string) (result []byte, err Error) {
 	arg0bytes := []byte(arg0)
@@ -876,11 +1072,92 @@ func (c *syncClient) Hset(arg0 string, arg1 string, arg2 []byte) (err Error) {
 }
 
 // Redis HGETALL command.
-func (c *syncClient) Hgetall(arg0 string) (result [][]byte, err Error) {
+func (c *syncClient) Hgetall(arg0 string) (result map[string][]byte, err Error) {
 	arg0bytes := []byte(arg0)
 
 	var resp Response
 	resp, err = c.conn.ServiceRequest(&HGETALL, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.GetMapData()
+	}
+	return result, err
+
+}
+
+// Redis HMSET command.
+func (c *syncClient) Hmset(arg0 string, arg1 map[string][]byte) (err Error) {
+	_, err = c.conn.ServiceRequest(&HMSET, hashArgs(arg0, arg1))
+	return
+}
+
+// Redis HMGET command.
+func (c *syncClient) Hmget(arg0 string, arg1 string, arg2 ...string) (result [][]byte, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HMGET, appendAndConvert(arg0, append([]string{arg1}, arg2...)...))
+	if err == nil {
+		result = resp.GetMultiBulkData()
+	}
+	return result, err
+
+}
+
+// Redis HDEL command.
+func (c *syncClient) Hdel(arg0 string, arg1 string, arg2 ...string) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HDEL, appendAndConvert(arg0, append([]string{arg1}, arg2...)...))
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis HEXISTS command.
+func (c *syncClient) Hexists(arg0 string, arg1 string) (result bool, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HEXISTS, [][]byte{arg0bytes, arg1bytes})
+	if err == nil {
+		result = resp.GetBooleanValue()
+	}
+	return result, err
+
+}
+
+// Redis HLEN command.
+func (c *syncClient) Hlen(arg0 string) (result int64, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HLEN, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis HKEYS command.
+func (c *syncClient) Hkeys(arg0 string) (result [][]byte, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HKEYS, [][]byte{arg0bytes})
+	if err == nil {
+		result = resp.GetMultiBulkData()
+	}
+	return result, err
+
+}
+
+// Redis HVALS command.
+func (c *syncClient) Hvals(arg0 string) (result [][]byte, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HVALS, [][]byte{arg0bytes})
 	if err == nil {
 		result = resp.GetMultiBulkData()
 	}
@@ -888,6 +1165,179 @@ func (c *syncClient) Hgetall(arg0 string) (result [][]byte, err Error) {
 
 }
 
+// Redis HINCRBY command.
+func (c *syncClient) Hincrby(arg0 string, arg1 string, arg2 int64) (result int64, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+	arg2bytes := []byte(fmt.Sprintf("%d", arg2))
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HINCRBY, [][]byte{arg0bytes, arg1bytes, arg2bytes})
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis HINCRBYFLOAT command.
+func (c *syncClient) Hincrbyfloat(arg0 string, arg1 string, arg2 float64) (result float64, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+	arg2bytes := []byte(fmt.Sprintf("%g", arg2))
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HINCRBYFLOAT, [][]byte{arg0bytes, arg1bytes, arg2bytes})
+	if err == nil {
+		result = resp.GetDoubleValue()
+	}
+	return result, err
+
+}
+
+// Redis HSETNX command.
+func (c *syncClient) Hsetnx(arg0 string, arg1 string, arg2 []byte) (result bool, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+	arg2bytes := arg2
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HSETNX, [][]byte{arg0bytes, arg1bytes, arg2bytes})
+	if err == nil {
+		result = resp.GetBooleanValue()
+	}
+	return result, err
+
+}
+
+// Redis HSTRLEN command.
+func (c *syncClient) Hstrlen(arg0 string, arg1 string) (result int64, err Error) {
+	arg0bytes := []byte(arg0)
+	arg1bytes := []byte(arg1)
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&HSTRLEN, [][]byte{arg0bytes, arg1bytes})
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis SCAN command.
+func (c *syncClient) Scan(spec *ScanSpec) ScanIterator {
+	return newScanIterator(c.conn, &SCAN, nil, spec)
+}
+
+// Redis SSCAN command.
+func (c *syncClient) Sscan(arg0 string, spec *ScanSpec) ScanIterator {
+	return newScanIterator(c.conn, &SSCAN, []byte(arg0), spec)
+}
+
+// Redis HSCAN command.
+func (c *syncClient) Hscan(arg0 string, spec *ScanSpec) ScanIterator {
+	return newScanIterator(c.conn, &HSCAN, []byte(arg0), spec)
+}
+
+// Redis ZSCAN command.
+func (c *syncClient) Zscan(arg0 string, spec *ScanSpec) ScanIterator {
+	return newScanIterator(c.conn, &ZSCAN, []byte(arg0), spec)
+}
+
+// Redis XADD command.
+func (c *syncClient) Xadd(arg0 string, arg1 string, arg2 map[string][]byte, trim *StreamTrimSpec) (result string, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&XADD, xaddArgs(arg0, arg1, arg2, trim))
+	if err == nil {
+		result = string(resp.GetBulkData())
+	}
+	return result, err
+
+}
+
+// Redis XRANGE command.
+func (c *syncClient) Xrange(arg0 string, start, end string, count int64) (result []StreamEntry, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&XRANGE, xrangeArgs(arg0, start, end, count))
+	if err == nil {
+		result, err = replyToStreamEntries(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis XREVRANGE command.
+func (c *syncClient) Xrevrange(arg0 string, end, start string, count int64) (result []StreamEntry, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&XREVRANGE, xrangeArgs(arg0, end, start, count))
+	if err == nil {
+		result, err = replyToStreamEntries(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis XLEN command.
+func (c *syncClient) Xlen(arg0 string) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&XLEN, [][]byte{[]byte(arg0)})
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis XDEL command.
+func (c *syncClient) Xdel(arg0 string, arg1 string, arg2 ...string) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&XDEL, appendAndConvert(arg0, append([]string{arg1}, arg2...)...))
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis XTRIM command.
+func (c *syncClient) Xtrim(arg0 string, trim *StreamTrimSpec) (result int64, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&XTRIM, append([][]byte{[]byte(arg0)}, trim.args()...))
+	if err == nil {
+		result = resp.GetNumberValue()
+	}
+	return result, err
+
+}
+
+// Redis XREAD command.
+func (c *syncClient) Xread(arg0 map[string]string, count int64) (result map[string][]StreamEntry, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&XREAD, xreadArgs(arg0, count, false, 0))
+	if err == nil {
+		result, err = replyToStreams(resp.GetReply())
+	}
+	return result, err
+
+}
+
+// Redis XREAD BLOCK command.
+func (c *syncClient) XreadBlock(arg0 map[string]string, count int64, timeout time.Duration) (result map[string][]StreamEntry, err Error) {
+	args := xreadArgs(arg0, count, true, timeout)
+	source, err := blockingSourceOf(c.conn, &XREAD, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	resp, err = serviceBlocking(source, &XREAD, args, timeout)
+	if err == nil {
+		result, err = replyToStreams(resp.GetReply())
+	}
+	return result, err
+
+}
+
 // Redis FLUSHDB command.
 func (c *syncClient) Flushdb() (err Error) {
 	_, err = c.conn.ServiceRequest(&FLUSHDB, [][]byte{})
@@ -943,3 +1393,65 @@ func (c *syncClient) Publish(arg0 string, arg1 []byte) (rcvCnt int64, err Error)
 	}
 	return rcvCnt, err
 }
+
+// Redis MULTI command.
+func (c *syncClient) Multi() (tx Transaction, err Error) {
+	source, ok := c.conn.(txConnection)
+	if !ok {
+		return nil, newSystemError("connection does not support transactions")
+	}
+	return newSyncTransaction(source), nil
+}
+
+// Redis EVAL command.
+func (c *syncClient) Eval(arg0 string, arg1 []string, arg2 [][]byte) (result *Reply, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&EVAL, scriptArgs(arg0, arg1, arg2))
+	if err == nil {
+		result = resp.GetReply()
+	}
+	return result, err
+}
+
+// Redis EVALSHA command.
+func (c *syncClient) Evalsha(arg0 string, arg1 []string, arg2 [][]byte) (result *Reply, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&EVALSHA, scriptArgs(arg0, arg1, arg2))
+	if err == nil {
+		result = resp.GetReply()
+	}
+	return result, err
+}
+
+// Redis SCRIPT LOAD command.
+func (c *syncClient) ScriptLoad(arg0 string) (sha1 string, err Error) {
+	arg0bytes := []byte(arg0)
+
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&SCRIPT_LOAD, [][]byte{arg0bytes})
+	if err == nil {
+		sha1 = string(resp.GetBulkData())
+	}
+	return sha1, err
+}
+
+// Redis SCRIPT EXISTS command.
+func (c *syncClient) ScriptExists(arg0 string, arg1 ...string) (result []bool, err Error) {
+	var resp Response
+	resp, err = c.conn.ServiceRequest(&SCRIPT_EXISTS, appendAndConvert(arg0, arg1...))
+	if err == nil {
+		result = replyToBools(resp.GetReply())
+	}
+	return result, err
+}
+
+// Redis SCRIPT FLUSH command.
+func (c *syncClient) ScriptFlush() (err Error) {
+	_, err = c.conn.ServiceRequest(&SCRIPT_FLUSH, [][]byte{})
+	return
+}
+
+// Sends an arbitrary Redis command.
+func (c *syncClient) Do(name string, args ...interface{}) (result *Reply, err Error) {
+	return c.conn.Do(name, args...)
+}
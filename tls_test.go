@@ -0,0 +1,230 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// self-signed certificates and in-process tls server
+// ----------------------------------------------------------------------------
+
+// returns a new self-signed certificate for 127.0.0.1 and localhost, and its
+// pool.
+func newSelfSignedCert(t *testing.T, cn string) (tls.Certificate, *x509.CertPool) {
+	key, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if e != nil {
+		t.Fatalf("GenerateKey - %s", e)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+	}
+	der, e := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if e != nil {
+		t.Fatalf("CreateCertificate - %s", e)
+	}
+	leaf, e := x509.ParseCertificate(der)
+	if e != nil {
+		t.Fatalf("ParseCertificate - %s", e)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// starts a fake redis server on a tls listener per config.
+func newFakeTLSServer(t *testing.T, config *tls.Config) *fakeServer {
+	listener, e := tls.Listen("tcp", "127.0.0.1:0", config)
+	if e != nil {
+		t.Fatalf("tls.Listen - %s", e)
+	}
+	s := &fakeServer{listener: listener, session: newFakeRedis().session}
+	go s.serve()
+	return s
+}
+
+// ----------------------------------------------------------------------------
+// tests
+// ----------------------------------------------------------------------------
+
+func TestTLSSyncClient(t *testing.T) {
+	cert, pool := newSelfSignedCert(t, "redis")
+	server := newFakeTLSServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec().TLSRootCAs(pool))
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	if e := client.Set("foo", []byte("bar")); e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	if v, e := client.Get("foo"); e != nil || string(v) != "bar" {
+		t.Errorf("expected GET result - value:%s error:%s", v, e)
+	}
+
+	pooled, e := NewPooledSynchClientWithSpec(server.spec().TLSRootCAs(pool))
+	if e != nil {
+		t.Fatalf("NewPooledSynchClientWithSpec - %s", e)
+	}
+	if v, e := pooled.Get("foo"); e != nil || string(v) != "bar" {
+		t.Errorf("expected pooled GET result - value:%s error:%s", v, e)
+	}
+}
+
+func TestTLSAsyncClient(t *testing.T) {
+	cert, pool := newSelfSignedCert(t, "redis")
+	server := newFakeTLSServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour).TLSRootCAs(pool))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	if _, e := client.Set("foo", []byte("bar")); e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	future, e := client.Get("foo")
+	if e != nil {
+		t.Fatalf("Get - %s", e)
+	}
+	if v, e, timedout := future.TryGet(5 * time.Second); timedout || e != nil || string(v) != "bar" {
+		t.Errorf("expected GET result - value:%s error:%s timedout:%t", v, e, timedout)
+	}
+}
+
+func TestTLSPubSubClient(t *testing.T) {
+	cert, pool := newSelfSignedCert(t, "redis")
+	server := newFakeTLSServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	defer server.close()
+
+	client, e := NewPubSubClientWithSpec(server.spec().Protocol(REDIS_PUBSUB).TLSRootCAs(pool))
+	if e != nil {
+		t.Fatalf("NewPubSubClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	if e := client.Subscribe("news"); e != nil {
+		t.Fatalf("Subscribe - %s", e)
+	}
+	server.push(pubsubMessage("message", "news", "hello"))
+	if msg := receiveMessage(t, client.Messages("news")); string(msg) != "hello" {
+		t.Errorf("expected hello - got %s", msg)
+	}
+}
+
+func TestTLSVerification(t *testing.T) {
+	cert, pool := newSelfSignedCert(t, "redis")
+	server := newFakeTLSServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	defer server.close()
+
+	// unknown authority
+	if _, e := NewSynchClientWithSpec(server.spec().TLS(true)); e == nil {
+		t.Error("expected error on unverified server certificate")
+	}
+	// server name mismatch
+	if _, e := NewSynchClientWithSpec(server.spec().TLSRootCAs(pool).TLSServerName("redis.example.com")); e == nil {
+		t.Error("expected error on server name mismatch")
+	}
+	if _, e := NewSynchClientWithSpec(server.spec().TLSRootCAs(pool).TLSServerName("localhost")); e != nil {
+		t.Errorf("expected server name localhost to verify - %s", e)
+	}
+	if _, e := NewSynchClientWithSpec(server.spec().TLSInsecureSkipVerify(true)); e != nil {
+		t.Errorf("expected connection with skipped verification - %s", e)
+	}
+	// plain connection to tls server
+	client, e := NewSynchClientWithSpec(server.spec().ReadTimeout(time.Second))
+	if e == nil {
+		if _, e = client.Get("foo"); e == nil {
+			t.Error("expected error on plain connection to TLS server")
+		}
+	}
+}
+
+func TestTLSClientCert(t *testing.T) {
+	cert, pool := newSelfSignedCert(t, "redis")
+	clientCert, clientPool := newSelfSignedCert(t, "client")
+	server := newFakeTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientPool,
+	})
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec().TLSRootCAs(pool).TLSClientCert(clientCert))
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	if e := client.Set("foo", []byte("bar")); e != nil {
+		t.Errorf("Set - %s", e)
+	}
+
+	// server rejects the client after the (tls 1.3) client handshake
+	client, e = NewSynchClientWithSpec(server.spec().TLSRootCAs(pool).ReadTimeout(time.Second))
+	if e == nil {
+		if e = client.Set("foo", []byte("bar")); e == nil {
+			t.Error("expected error without client certificate")
+		}
+	}
+}
+
+func TestTLSURL(t *testing.T) {
+	cert, _ := newSelfSignedCert(t, "redis")
+	server := newFakeTLSServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	defer server.close()
+
+	capath := filepath.Join(t.TempDir(), "ca.pem")
+	if e := os.WriteFile(capath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600); e != nil {
+		t.Fatalf("WriteFile - %s", e)
+	}
+
+	spec, e := ParseURL("rediss://" + server.listener.Addr().String() + "?tls_ca_file=" + capath)
+	if e != nil {
+		t.Fatalf("ParseURL - %s", e)
+	}
+	client, e := NewSynchClientWithSpec(spec)
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	if e := client.Set("foo", []byte("bar")); e != nil {
+		t.Errorf("Set - %s", e)
+	}
+
+	if spec, _ := ParseURL("redis://localhost"); spec.tlsConfig != nil {
+		t.Error("expected no TLS for redis scheme")
+	}
+	_, e = ParseURL("rediss://localhost?tls_ca_file=" + filepath.Join(t.TempDir(), "none.pem"))
+	if specerr, ok := e.(SpecError); !ok || specerr.Parameter() != "tls_ca_file" {
+		t.Errorf("expected SpecError on tls_ca_file - got %v", e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_tlst(t *testing.T) {
+	log.Println("-- tls test completed")
+}
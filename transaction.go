@@ -0,0 +1,303 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"bytes"
+)
+
+// -----------------------------------------------------------------------------
+// transaction - the state common to Transaction and AsyncTransaction
+// -----------------------------------------------------------------------------
+
+// Commands of the transaction are queued (locally) and the MULTI ... EXEC
+// sequence is only sent on Exec.
+//
+// WATCH requires a dedicated connection, as the watched keys are per
+// connection.  For sync clients that is the client's connection (or a
+// connection checked out of the pool), and for async clients it is a
+// connection checked out of its dedicated pool on first Watch (since the
+// pipeline is shared).
+// Absent a dedicated connection, the transaction of an async client
+// is queued as a single request on the pipeline.
+type transaction struct {
+	source  txConnection
+	hdl     *connHdl // dedicated connection, if any
+	reqs    []asyncReqPtr
+	watched bool
+	done    bool
+}
+
+// Provides dedicated connections to transactions.
+type txConnection interface {
+	checkout() (*connHdl, Error)
+	release(hdl *connHdl, broken bool)
+}
+
+// Queues the request in the transaction.  future is nil for sync clients.
+func (tx *transaction) queue(cmd *Command, args [][]byte, future interface{}) (err Error) {
+
+	defer func() {
+		if re := recover(); re != nil {
+			err = newSystemErrorWithCause("queue", re.(error))
+		}
+	}()
+
+	if tx.done {
+		return newSystemError("transaction is already executed or discarded")
+	}
+
+	buff := CreateRequestBytes(cmd, args) // panics
+	tx.reqs = append(tx.reqs, &asyncRequestInfo{0, 0, cmd, &buff, future, nil, nil})
+	return
+}
+
+// Redis WATCH command.
+func (tx *transaction) Watch(key string, otherKeys ...string) (err Error) {
+	if tx.done {
+		return newSystemError("transaction is already executed or discarded")
+	}
+	if tx.hdl == nil {
+		if tx.hdl, err = tx.source.checkout(); err != nil {
+			return
+		}
+	}
+	_, err = tx.hdl.ServiceRequest(&WATCH, appendAndConvert(key, otherKeys...))
+	if err == nil {
+		tx.watched = true
+	}
+	return
+}
+
+// Redis UNWATCH command.
+func (tx *transaction) Unwatch() (err Error) {
+	if tx.done {
+		return newSystemError("transaction is already executed or discarded")
+	}
+	if tx.watched {
+		_, err = tx.hdl.ServiceRequest(&UNWATCH, [][]byte{})
+		if err == nil {
+			tx.watched = false
+		}
+	}
+	return
+}
+
+// Sends the MULTI, <reqs>, EXEC sequence on the dedicated connection (checked
+// out if none) and returns the responses to the queued requests.
+func (tx *transaction) exec() (resps []Response, err Error) {
+	hdl := tx.hdl
+	if hdl == nil {
+		if hdl, err = tx.source.checkout(); err != nil {
+			return nil, err
+		}
+	}
+	tx.hdl = nil
+
+	resps, err = hdl.serviceTransaction(tx.reqs)
+	tx.source.release(hdl, err != nil && !err.IsRedisError())
+	return
+}
+
+// Unwatches the keys, if any, and releases the dedicated connection.
+func (tx *transaction) discard() (err Error) {
+	// MULTI is only sent on Exec - DISCARD would only UNWATCH
+	if tx.hdl != nil {
+		broken := false
+		if tx.watched {
+			_, err = tx.hdl.ServiceRequest(&UNWATCH, [][]byte{})
+			broken = err != nil && !err.IsRedisError()
+		}
+		tx.source.release(tx.hdl, broken)
+		tx.hdl = nil
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
+// syncTransaction - supports Transaction interface
+// -----------------------------------------------------------------------------
+
+type syncTransaction struct {
+	transaction
+}
+
+func newSyncTransaction(source txConnection) *syncTransaction {
+	return &syncTransaction{transaction{source: source}}
+}
+
+// Queues the command in the transaction.
+func (tx *syncTransaction) Queue(cmd *Command, args ...[]byte) Error {
+	return tx.queue(cmd, args, nil)
+}
+
+// Redis MULTI ... EXEC commands.
+func (tx *syncTransaction) Exec() (results []Response, err Error) {
+	if tx.done {
+		return nil, newSystemError("transaction is already executed or discarded")
+	}
+	tx.done = true
+	return tx.exec()
+}
+
+// Redis DISCARD command.
+func (tx *syncTransaction) Discard() Error {
+	if tx.done {
+		return newSystemError("transaction is already executed or discarded")
+	}
+	tx.done = true
+	return tx.discard()
+}
+
+// -----------------------------------------------------------------------------
+// asyncTransaction - supports AsyncTransaction interface
+// -----------------------------------------------------------------------------
+
+// Commands are queued via the embedded asyncClient, which uses the
+// transaction itself as its AsyncConnection.
+type asyncTransaction struct {
+	asyncClient
+	transaction
+	pipeline txPipeline
+}
+
+// The pipeline of async clients, on which transactions without a dedicated
+// connection are queued.
+type txPipeline interface {
+	txConnection
+	queueTransaction(reqs []asyncReqPtr) (status FutureBool, err Error)
+}
+
+func newAsyncTransaction(source txConnection, pipeline txPipeline) *asyncTransaction {
+	tx := &asyncTransaction{transaction: transaction{source: source}, pipeline: pipeline}
+	tx.asyncClient.conn = tx
+	return tx
+}
+
+// Implementation of AsyncConnection.QueueRequest - queues the request in
+// the transaction.
+func (tx *asyncTransaction) QueueRequest(cmd *Command, args [][]byte) (*PendingResponse, Error) {
+	future := CreateFuture(cmd)
+	if err := tx.queue(cmd, args, future); err != nil {
+		return nil, err
+	}
+	return &PendingResponse{future}, nil
+}
+
+// Transactions can not be nested.
+func (tx *asyncTransaction) Multi() (AsyncTransaction, Error) {
+	return nil, newSystemError("nested transactions are not supported")
+}
+
+// Redis MULTI ... EXEC commands.
+func (tx *asyncTransaction) Exec() (status FutureBool, err Error) {
+	if tx.done {
+		return nil, newSystemError("transaction is already executed or discarded")
+	}
+	tx.done = true
+
+	if tx.hdl == nil {
+		return tx.pipeline.queueTransaction(tx.reqs)
+	}
+
+	status = newFutureBool()
+	go func() {
+		resps, e := tx.exec()
+		setTransactionResult(status, tx.reqs, resps, e)
+	}()
+	return status, nil
+}
+
+// Redis DISCARD command.
+func (tx *asyncTransaction) Discard() (err Error) {
+	if tx.done {
+		return newSystemError("transaction is already executed or discarded")
+	}
+	tx.done = true
+
+	err = tx.discard()
+	discarded := newSystemError("transaction discarded")
+	for _, req := range tx.reqs {
+		req.future.(FutureResult).onError(discarded)
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
+// txConnection support
+// -----------------------------------------------------------------------------
+
+// the (non-shared) connection of a sync client is dedicated.
+func (c *connHdl) checkout() (*connHdl, Error) {
+	return c, nil
+}
+func (c *connHdl) release(hdl *connHdl, broken bool) {}
+
+// pooled clients checkout a pool connection for the transaction.
+func (p *connPool) checkout() (*connHdl, Error) {
+	return p.get()
+}
+func (p *connPool) release(hdl *connHdl, broken bool) {
+	p.put(hdl, broken)
+}
+
+// the pipeline of async clients is shared, so a dedicated connection is
+// checked out of the (per spec MaxIdle, MaxActive) pool of the connection.
+func (c *asyncConnHdl) checkout() (*connHdl, Error) {
+	return c.dedicated.get()
+}
+func (c *asyncConnHdl) release(hdl *connHdl, broken bool) {
+	c.dedicated.put(hdl, broken)
+}
+
+// -----------------------------------------------------------------------------
+// transaction support
+// -----------------------------------------------------------------------------
+
+// Creates the byte buffer for the MULTI, <reqs>, EXEC sequence.
+func createTransactionBytes(reqs []asyncReqPtr) []byte {
+	var buffer bytes.Buffer
+	buffer.Write(CreateRequestBytes(&MULTI, [][]byte{}))
+	for _, req := range reqs {
+		buffer.Write(*req.outbuff)
+	}
+	buffer.Write(CreateRequestBytes(&EXEC, [][]byte{}))
+	return buffer.Bytes()
+}
+
+func transactionCommands(reqs []asyncReqPtr) []*Command {
+	cmds := make([]*Command, len(reqs))
+	for i, req := range reqs {
+		cmds[i] = req.cmd
+	}
+	return cmds
+}
+
+// Sets the futures of the requests per responses of EXEC, and the
+// transaction status.  If EXEC raised an error (e.g. ExecAbortedError)
+// all futures are set with that error.
+func setTransactionResult(status FutureBool, reqs []asyncReqPtr, resps []Response, e Error) {
+	if e != nil {
+		for _, req := range reqs {
+			req.future.(FutureResult).onError(e)
+		}
+		status.(FutureResult).onError(e)
+		return
+	}
+	for i, req := range reqs {
+		SetFutureResult(req.future, req.cmd, resps[i])
+	}
+	status.set(true)
+}
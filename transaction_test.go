@@ -0,0 +1,158 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"testing"
+	"time"
+)
+
+func TestSyncTransaction(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	tx, e := client.Multi()
+	if e != nil {
+		t.Fatalf("Multi - %s", e)
+	}
+	tx.Queue(&SET, []byte("foo"), []byte("bar"))
+	tx.Queue(&GET, []byte("foo"))
+	tx.Queue(&INCR, []byte("counter"))
+	tx.Queue(&LRANGE, []byte("list"), []byte("0"), []byte("-1"))
+	tx.Queue(&INCR, []byte("foo"))
+
+	results, e := tx.Exec()
+	if e != nil {
+		t.Fatalf("Exec - %s", e)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results - got %d", len(results))
+	}
+	if v := results[1].GetBulkData(); string(v) != "bar" {
+		t.Errorf("GET in transaction - value:%s", v)
+	}
+	if n := results[2].GetNumberValue(); n != 1 {
+		t.Errorf("INCR in transaction - value:%d", n)
+	}
+	if v := results[3].GetMultiBulkData(); len(v) != 2 {
+		t.Errorf("LRANGE in transaction - value:%s", v)
+	}
+	if !results[4].IsError() {
+		t.Errorf("expected error response for INCR of non-integer - got %d", results[4].GetNumberValue())
+	}
+
+	if _, e := tx.Exec(); e == nil {
+		t.Error("expected error on repeated Exec")
+	}
+}
+
+func TestSyncTransactionWatchAborted(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewPooledSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewPooledSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	tx, _ := client.Multi()
+	if e := tx.Watch("foo"); e != nil {
+		t.Fatalf("Watch - %s", e)
+	}
+	// modified on another pool connection
+	if e := client.Set("foo", []byte("changed")); e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	tx.Queue(&SET, []byte("foo"), []byte("bar"))
+
+	results, e := tx.Exec()
+	if ae, ok := e.(ExecAbortedError); !ok || !ae.ExecAborted() {
+		t.Fatalf("expected ExecAbortedError - got %s", e)
+	}
+	if results != nil {
+		t.Error("expected no results of aborted transaction")
+	}
+	if v, _ := client.Get("foo"); string(v) != "changed" {
+		t.Errorf("aborted transaction modified foo - %s", v)
+	}
+}
+
+func TestAsyncTransaction(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewAsynchClientWithSpec(server.spec().Heartbeat(time.Hour))
+	if e != nil {
+		t.Fatalf("NewAsynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	// pipelined
+	tx, _ := client.Multi()
+	tx.Set("foo", []byte("bar"))
+	fget, _ := tx.Get("foo")
+	status, e := tx.Exec()
+	if e != nil {
+		t.Fatalf("Exec - %s", e)
+	}
+	if ok, e := status.Get(); e != nil || !ok {
+		t.Fatalf("Exec future - ok:%t error:%s", ok, e)
+	}
+	if v, e := fget.Get(); e != nil || string(v) != "bar" {
+		t.Errorf("GET in transaction - value:%s error:%s", v, e)
+	}
+
+	// watched - on dedicated connection
+	tx, _ = client.Multi()
+	if e := tx.Watch("foo"); e != nil {
+		t.Fatalf("Watch - %s", e)
+	}
+	fset, _ := client.Set("foo", []byte("changed"))
+	if _, e := fset.Get(); e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	tx.Set("foo", []byte("bar"))
+	status, _ = tx.Exec()
+	if _, e := status.Get(); e == nil {
+		t.Fatal("expected ExecAbortedError")
+	} else if _, ok := e.(ExecAbortedError); !ok {
+		t.Fatalf("expected ExecAbortedError - got %s", e)
+	}
+}
+
+func TestTransactionDiscard(t *testing.T) {
+	server := newFakeSessionServer(t, newFakeRedis().session)
+	defer server.close()
+
+	client, e := NewSynchClientWithSpec(server.spec())
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	defer client.Quit()
+
+	tx, _ := client.Multi()
+	tx.Watch("foo")
+	tx.Queue(&SET, []byte("foo"), []byte("bar"))
+	if e := tx.Discard(); e != nil {
+		t.Fatalf("Discard - %s", e)
+	}
+	if _, e := tx.Exec(); e == nil {
+		t.Error("expected error on Exec of discarded transaction")
+	}
+	if v, _ := client.Get("foo"); v != nil {
+		t.Errorf("discarded transaction modified foo - %s", v)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_tt(t *testing.T) {
+	log.Println("-- transaction test completed")
+}
@@ -0,0 +1,277 @@
+//   Copyright 2009-2012 Joubin Houshyar
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package redis
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// ConnectionSpec from URL and environment
+// -----------------------------------------------------------------------------
+
+// Creates a ConnectionSpec per the URL.  Unspecified settings are per
+// DefaultSpec.  Supported forms are
+//
+//	redis://[[username]:password@]host[:port][/db][?param=value&...]
+//	rediss://[[username]:password@]host[:port][/db][?param=value&...]
+//	unix://[[username]:password@]/path/to/socket[?param=value&...]
+//
+// e.g. redis://:pw@host:6380/3?heartbeat=2s&read_timeout=500ms
+//
+// rediss connections use TLS.
+//
+// Supported params are db, username, password, heartbeat, read_timeout, write_timeout,
+// max_idle, max_active, idle_timeout, pool_wait, reconnect, backoff,
+// max_backoff, replay (fail_pending, replay_unsent, or replay_all), and
+// resp3.
+// Durations are per time.ParseDuration.
+//
+// TLS params (which enable TLS) are tls_ca_file (PEM file of the CAs used to
+// verify the server certificate), tls_server_name, and
+// tls_insecure_skip_verify.  Client certificates are set per
+// ConnectionSpec.TLSClientCert.
+//
+// Invalid URLs and settings are reported with a SpecError naming the
+// offending parameter.
+func ParseURL(rawurl string) (spec *ConnectionSpec, err Error) {
+	u, e := url.Parse(rawurl)
+	if e != nil {
+		return nil, newSpecError("url", e)
+	}
+
+	spec = DefaultSpec()
+	switch u.Scheme {
+	case "redis", "rediss":
+		spec.TLS(u.Scheme == "rediss")
+		if host := u.Hostname(); host != "" {
+			spec.Host(host)
+		}
+		if port := u.Port(); port != "" {
+			if err = setSpecPort(spec, "port", port); err != nil {
+				return nil, err
+			}
+		}
+		if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+			if err = setSpecParam(spec, "db", "db", db); err != nil {
+				return nil, err
+			}
+		}
+	case "unix":
+		if u.Path == "" {
+			return nil, newSpecError("socket", errors.New("missing socket path"))
+		}
+		spec.Socket(u.Path)
+	default:
+		return nil, newSpecError("scheme", fmt.Errorf("%q - expecting redis, rediss, or unix", u.Scheme))
+	}
+
+	if u.User != nil {
+		spec.Username(u.User.Username())
+		if password, ok := u.User.Password(); ok {
+			spec.Password(password)
+		}
+	}
+	for name, values := range u.Query() {
+		if err = setSpecParam(spec, name, name, values[len(values)-1]); err != nil {
+			return nil, err
+		}
+	}
+	return spec, nil
+}
+
+// Creates a ConnectionSpec per environment variables with the given prefix
+// (e.g. "REDIS_").  Unspecified settings are per DefaultSpec.
+//
+// <prefix>URL, if set, is parsed per ParseURL.  <prefix>HOST, <prefix>PORT,
+// and <prefix>SOCKET, and the upper case names of the ParseURL params (e.g.
+// <prefix>READ_TIMEOUT) override the URL settings.
+//
+// Invalid settings are reported with a SpecError naming the offending
+// environment variable.
+func SpecFromEnv(prefix string) (spec *ConnectionSpec, err Error) {
+	spec = DefaultSpec()
+	if rawurl, ok := os.LookupEnv(prefix + "URL"); ok {
+		if spec, err = ParseURL(rawurl); err != nil {
+			return nil, newSpecError(prefix+"URL", err)
+		}
+	}
+
+	if host, ok := os.LookupEnv(prefix + "HOST"); ok {
+		spec.Host(host)
+	}
+	if port, ok := os.LookupEnv(prefix + "PORT"); ok {
+		if err = setSpecPort(spec, prefix+"PORT", port); err != nil {
+			return nil, err
+		}
+	}
+	if socket, ok := os.LookupEnv(prefix + "SOCKET"); ok {
+		spec.Socket(socket)
+	}
+
+	for name := range specParams {
+		envvar := prefix + strings.ToUpper(name)
+		if value, ok := os.LookupEnv(envvar); ok {
+			if err = setSpecParam(spec, envvar, name, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return spec, nil
+}
+
+// sets the port of the spec.  param is the name reported on error.
+func setSpecPort(spec *ConnectionSpec, param, port string) Error {
+	n, e := strconv.Atoi(port)
+	if e == nil && (n < 1 || n > 65535) {
+		e = fmt.Errorf("%d is out of range", n)
+	}
+	if e != nil {
+		return newSpecError(param, e)
+	}
+	spec.Port(n)
+	return nil
+}
+
+// sets the named spec param.  param is the name reported on error.
+func setSpecParam(spec *ConnectionSpec, param, name, value string) Error {
+	set, ok := specParams[name]
+	if !ok {
+		return newSpecError(param, errors.New("unknown parameter"))
+	}
+	if e := set(spec, value); e != nil {
+		return newSpecError(param, e)
+	}
+	return nil
+}
+
+// ConnectionSpec params settable by URL query and environment.
+var specParams = map[string]func(spec *ConnectionSpec, value string) error{
+	"db": func(spec *ConnectionSpec, value string) (e error) {
+		spec.db, e = parseNonNegativeInt(value)
+		return
+	},
+	"password": func(spec *ConnectionSpec, value string) error {
+		spec.password = value
+		return nil
+	},
+	"username": func(spec *ConnectionSpec, value string) error {
+		spec.username = value
+		return nil
+	},
+	"heartbeat": func(spec *ConnectionSpec, value string) (e error) {
+		spec.heartbeat, e = parseNonNegativeDuration(value)
+		return
+	},
+	"read_timeout": func(spec *ConnectionSpec, value string) (e error) {
+		spec.rTimeout, e = parseNonNegativeDuration(value)
+		return
+	},
+	"write_timeout": func(spec *ConnectionSpec, value string) (e error) {
+		spec.wTimeout, e = parseNonNegativeDuration(value)
+		return
+	},
+	"max_idle": func(spec *ConnectionSpec, value string) (e error) {
+		spec.maxIdle, e = parseNonNegativeInt(value)
+		return
+	},
+	"max_active": func(spec *ConnectionSpec, value string) (e error) {
+		spec.maxActive, e = parseNonNegativeInt(value)
+		return
+	},
+	"idle_timeout": func(spec *ConnectionSpec, value string) (e error) {
+		spec.idleTTL, e = parseNonNegativeDuration(value)
+		return
+	},
+	"pool_wait": func(spec *ConnectionSpec, value string) (e error) {
+		spec.poolWait, e = strconv.ParseBool(value)
+		return
+	},
+	"reconnect": func(spec *ConnectionSpec, value string) (e error) {
+		spec.reconnect, e = parseNonNegativeInt(value)
+		return
+	},
+	"backoff": func(spec *ConnectionSpec, value string) (e error) {
+		spec.backoff, e = parseNonNegativeDuration(value)
+		return
+	},
+	"max_backoff": func(spec *ConnectionSpec, value string) (e error) {
+		spec.backoffMax, e = parseNonNegativeDuration(value)
+		return
+	},
+	"replay": func(spec *ConnectionSpec, value string) error {
+		switch strings.ToLower(value) {
+		case "fail_pending":
+			spec.replay = FAIL_PENDING
+		case "replay_unsent":
+			spec.replay = REPLAY_UNSENT
+		case "replay_all":
+			spec.replay = REPLAY_ALL
+		default:
+			return fmt.Errorf("%q - expecting fail_pending, replay_unsent, or replay_all", value)
+		}
+		return nil
+	},
+	"resp3": func(spec *ConnectionSpec, value string) (e error) {
+		spec.resp3, e = strconv.ParseBool(value)
+		return
+	},
+	"tls_ca_file": func(spec *ConnectionSpec, value string) error {
+		pem, e := os.ReadFile(value)
+		if e != nil {
+			return e
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", value)
+		}
+		spec.TLSRootCAs(pool)
+		return nil
+	},
+	"tls_server_name": func(spec *ConnectionSpec, value string) error {
+		spec.TLSServerName(value)
+		return nil
+	},
+	"tls_insecure_skip_verify": func(spec *ConnectionSpec, value string) error {
+		skip, e := strconv.ParseBool(value)
+		if e == nil {
+			spec.TLSInsecureSkipVerify(skip)
+		}
+		return e
+	},
+}
+
+func parseNonNegativeInt(value string) (int, error) {
+	n, e := strconv.Atoi(value)
+	if e == nil && n < 0 {
+		e = fmt.Errorf("%d is negative", n)
+	}
+	return n, e
+}
+
+func parseNonNegativeDuration(value string) (time.Duration, error) {
+	d, e := time.ParseDuration(value)
+	if e == nil && d < 0 {
+		e = fmt.Errorf("%s is negative", d)
+	}
+	return d, e
+}
@@ -0,0 +1,141 @@
+// REVU - whitebox testing of internal comps -- OK.
+
+package redis
+
+import (
+	"log"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseURL(t *testing.T) {
+	spec, e := ParseURL("redis://:pw@host:6380/3?heartbeat=2s&read_timeout=500ms")
+	if e != nil {
+		t.Fatalf("ParseURL - %s", e)
+	}
+	if spec.host != "host" || spec.port != 6380 || spec.password != "pw" || spec.db != 3 {
+		t.Errorf("unexpected address %s:%d password:%s db:%d", spec.host, spec.port, spec.password, spec.db)
+	}
+	if spec.heartbeat != 2*time.Second || spec.rTimeout != 500*time.Millisecond {
+		t.Errorf("unexpected heartbeat:%s read_timeout:%s", spec.heartbeat, spec.rTimeout)
+	}
+	if spec.wTimeout != DefaultTCPWriteTimeoutNSecs || spec.socket != "" {
+		t.Errorf("expected defaults for unspecified settings")
+	}
+
+	spec, e = ParseURL("redis://alice:pw@host")
+	if e != nil {
+		t.Fatalf("ParseURL - %s", e)
+	}
+	if spec.username != "alice" || spec.password != "pw" {
+		t.Errorf("unexpected username:%s password:%s", spec.username, spec.password)
+	}
+
+	spec, e = ParseURL("unix:///tmp/redis.sock?db=2")
+	if e != nil {
+		t.Fatalf("ParseURL - %s", e)
+	}
+	if spec.socket != "/tmp/redis.sock" || spec.db != 2 {
+		t.Errorf("unexpected socket:%s db:%d", spec.socket, spec.db)
+	}
+
+	spec, e = ParseURL("redis://localhost?max_active=8&pool_wait=true&replay=replay_all&backoff=1s&max_backoff=4s")
+	if e != nil {
+		t.Fatalf("ParseURL - %s", e)
+	}
+	if spec.port != DefaultRedisPort || spec.maxActive != 8 || !spec.poolWait || spec.replay != REPLAY_ALL ||
+		spec.backoff != time.Second || spec.backoffMax != 4*time.Second {
+		t.Errorf("unexpected spec %v", spec)
+	}
+}
+
+func TestParseURLErrors(t *testing.T) {
+	invalid := map[string]string{
+		"http://host":                       "scheme",
+		"redis://host:0":                    "port",
+		"redis://host:6379/x":               "db",
+		"redis://host?db=-1":                "db",
+		"redis://host?heartbeat=2":          "heartbeat",
+		"redis://host?read_timeout=-1s":     "read_timeout",
+		"redis://host?pool_wait=maybe":      "pool_wait",
+		"redis://host?replay=sometimes":     "replay",
+		"redis://host?no_such_param=1":      "no_such_param",
+		"unix://?db=2":                      "socket",
+		"redis://host:6379/?write_timeout=": "write_timeout",
+	}
+	for rawurl, param := range invalid {
+		_, e := ParseURL(rawurl)
+		specerr, ok := e.(SpecError)
+		if !ok {
+			t.Errorf("%s - expected SpecError - got %v", rawurl, e)
+			continue
+		}
+		if specerr.Parameter() != param {
+			t.Errorf("%s - expected error on %s - got %s", rawurl, param, specerr)
+		}
+	}
+}
+
+func TestSpecFromEnv(t *testing.T) {
+	t.Setenv("TEST_REDIS_URL", "redis://:pw@host:6380/3")
+	t.Setenv("TEST_REDIS_PORT", "6381")
+	t.Setenv("TEST_REDIS_WRITE_TIMEOUT", "250ms")
+
+	spec, e := SpecFromEnv("TEST_REDIS_")
+	if e != nil {
+		t.Fatalf("SpecFromEnv - %s", e)
+	}
+	if spec.host != "host" || spec.port != 6381 || spec.password != "pw" || spec.db != 3 {
+		t.Errorf("unexpected address %s:%d password:%s db:%d", spec.host, spec.port, spec.password, spec.db)
+	}
+	if spec.wTimeout != 250*time.Millisecond {
+		t.Errorf("unexpected write_timeout:%s", spec.wTimeout)
+	}
+
+	t.Setenv("TEST_REDIS_RECONNECT", "some")
+	_, e = SpecFromEnv("TEST_REDIS_")
+	if specerr, ok := e.(SpecError); !ok || specerr.Parameter() != "TEST_REDIS_RECONNECT" {
+		t.Errorf("expected SpecError on TEST_REDIS_RECONNECT - got %v", e)
+	}
+
+	t.Setenv("TEST_REDIS_RECONNECT", "3")
+	t.Setenv("TEST_REDIS_URL", "redis://host/x")
+	_, e = SpecFromEnv("TEST_REDIS_")
+	if specerr, ok := e.(SpecError); !ok || specerr.Parameter() != "TEST_REDIS_URL" {
+		t.Errorf("expected SpecError on TEST_REDIS_URL - got %v", e)
+	}
+}
+
+func TestUnixSocketSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redis.sock")
+	listener, e := net.Listen(UNIX, path)
+	if e != nil {
+		t.Fatalf("Listen - %s", e)
+	}
+	redis := newFakeRedis()
+	server := &fakeServer{listener: listener, session: redis.session}
+	go server.serve()
+	defer server.close()
+
+	spec, e := ParseURL("unix://" + path)
+	if e != nil {
+		t.Fatalf("ParseURL - %s", e)
+	}
+	client, e := NewSynchClientWithSpec(spec)
+	if e != nil {
+		t.Fatalf("NewSynchClientWithSpec - %s", e)
+	}
+	if e := client.Set("foo", []byte("bar")); e != nil {
+		t.Fatalf("Set - %s", e)
+	}
+	if v, e := client.Get("foo"); e != nil || string(v) != "bar" {
+		t.Errorf("expected GET result - value:%s error:%s", v, e)
+	}
+}
+
+/* --------------- KEEP THIS AS LAST FUNCTION -------------- */
+func TestEnd_ut(t *testing.T) {
+	log.Println("-- url test completed")
+}